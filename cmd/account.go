@@ -0,0 +1,287 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+)
+
+// runAccountCommand manages accounts, e.g.
+// my-grpc-server account create -name "Jane Doe" -currency USD -deposit 100
+// my-grpc-server account create -name "Jane Doe" -currency USD -savings
+// my-grpc-server account show -number 7835697001 -output json
+// my-grpc-server account show -uuid 6f1c0e0a-52f5-4b8e-9a51-0b7e2f3c1d2a
+// my-grpc-server account freeze -number 7835697001
+// my-grpc-server account rename -number 7835697001 -name "Jane Smith"
+// my-grpc-server account close -number 7835697001
+// my-grpc-server account overdraft -number 7835697001 -limit 500
+func runAccountCommand(args []string) {
+	if len(args) == 0 {
+		printAccountUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "create":
+		runAccountCreateCommand(args[1:])
+	case "show":
+		runAccountShowCommand(args[1:])
+	case "freeze":
+		runAccountStatusCommand("freeze", args[1:])
+	case "unfreeze":
+		runAccountStatusCommand("unfreeze", args[1:])
+	case "close":
+		runAccountStatusCommand("close", args[1:])
+	case "rename":
+		runAccountRenameCommand(args[1:])
+	case "overdraft":
+		runAccountOverdraftCommand(args[1:])
+	default:
+		printAccountUsage()
+		os.Exit(2)
+	}
+}
+
+func printAccountUsage() {
+	fmt.Fprintln(os.Stderr, "usage : my-grpc-server account create|show|rename|overdraft|freeze|unfreeze|close [flags]")
+}
+
+func runAccountCreateCommand(args []string) {
+	fs := flag.NewFlagSet("account create", flag.ExitOnError)
+	name := fs.String("name", "", "account name")
+	currency := fs.String("currency", "USD", "account currency")
+	deposit := fs.Float64("deposit", 0, "initial deposit")
+	savings := fs.Bool("savings", false, "open a savings account, only savings accounts are paid interest")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *name == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	svc := newServices(openDatabaseAdapter())
+	loadScreeningList(svc)
+
+	na := bank.NewAccount{
+		AccountName:          *name,
+		Currency:             *currency,
+		InitialDepositAmount: *deposit,
+	}
+
+	if *savings {
+		na.Product = bank.AccountProductSavings
+	}
+
+	account, err := svc.account.CreateAccount(na)
+
+	entry := bank.AuditEntry{
+		Action:     bank.AuditActionAccountCreate,
+		EntityType: bank.AuditEntityAccount,
+		After:      na,
+		Err:        err,
+	}
+
+	if err == nil {
+		entry.EntityIds = []string{account.AccountUuid.String(), account.AccountNumber}
+		entry.After = account
+	}
+
+	svc.audit.Record(cliAuditContext("account create"), entry)
+
+	if err != nil {
+		log.Fatalln("can't create account", err)
+	}
+
+	printAccounts(*output, account)
+}
+
+func runAccountShowCommand(args []string) {
+	fs := flag.NewFlagSet("account show", flag.ExitOnError)
+	number := fs.String("number", "", "account number")
+	accountUuid := fs.String("uuid", "", "account uuid, instead of the number")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if (*number == "") == (*accountUuid == "") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	svc := newServices(openDatabaseAdapter())
+
+	var account bank.Account
+	var err error
+
+	if *accountUuid != "" {
+		id, parseErr := uuid.Parse(*accountUuid)
+		if parseErr != nil {
+			log.Fatalln("invalid account uuid", parseErr)
+		}
+
+		account, err = svc.account.FindAccountByUuid(id)
+	} else {
+		account, err = svc.account.FindAccountByNumber(*number)
+	}
+
+	if err != nil {
+		log.Fatalln("can't find account", err)
+	}
+
+	printAccounts(*output, account)
+}
+
+func runAccountStatusCommand(command string, args []string) {
+	fs := flag.NewFlagSet("account "+command, flag.ExitOnError)
+	number := fs.String("number", "", "account number")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *number == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	svc := newServices(openDatabaseAdapter())
+
+	before, err := svc.account.FindAccountByNumber(*number)
+	if err != nil {
+		log.Fatalln("can't find account", err)
+	}
+
+	var account bank.Account
+	var action string
+
+	switch command {
+	case "freeze":
+		action = bank.AuditActionAccountFreeze
+		account, err = svc.account.FreezeAccount(*number)
+	case "unfreeze":
+		action = bank.AuditActionAccountUnfreeze
+		account, err = svc.account.UnfreezeAccount(*number)
+	default:
+		action = bank.AuditActionAccountClose
+		account, err = svc.account.CloseAccount(*number)
+	}
+
+	svc.audit.Record(cliAuditContext("account "+command), bank.AuditEntry{
+		Action:     action,
+		EntityType: bank.AuditEntityAccount,
+		EntityIds:  []string{before.AccountUuid.String(), before.AccountNumber},
+		Before:     before,
+		After:      account,
+		Err:        err,
+	})
+
+	if err != nil {
+		log.Fatalf("can't %v account : %v", command, err)
+	}
+
+	printAccounts(*output, account)
+}
+
+func runAccountRenameCommand(args []string) {
+	fs := flag.NewFlagSet("account rename", flag.ExitOnError)
+	number := fs.String("number", "", "account number")
+	name := fs.String("name", "", "new account name")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *number == "" || *name == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	svc := newServices(openDatabaseAdapter())
+	loadScreeningList(svc)
+
+	before, err := svc.account.FindAccountByNumber(*number)
+	if err != nil {
+		log.Fatalln("can't find account", err)
+	}
+
+	account, err := svc.account.RenameAccount(*number, *name)
+
+	svc.audit.Record(cliAuditContext("account rename"), bank.AuditEntry{
+		Action:     bank.AuditActionAccountRename,
+		EntityType: bank.AuditEntityAccount,
+		EntityIds:  []string{before.AccountUuid.String(), before.AccountNumber},
+		Before:     before,
+		After:      account,
+		Err:        err,
+	})
+
+	if err != nil {
+		log.Fatalln("can't rename account", err)
+	}
+
+	printAccounts(*output, account)
+}
+
+func runAccountOverdraftCommand(args []string) {
+	fs := flag.NewFlagSet("account overdraft", flag.ExitOnError)
+	number := fs.String("number", "", "account number")
+	limit := fs.Float64("limit", -1, "overdraft limit, 0 to allow no overdraft")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *number == "" || *limit < 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	svc := newServices(openDatabaseAdapter())
+
+	before, err := svc.account.FindAccountByNumber(*number)
+	if err != nil {
+		log.Fatalln("can't find account", err)
+	}
+
+	account, err := svc.account.SetOverdraftLimit(*number, *limit)
+
+	svc.audit.Record(cliAuditContext("account overdraft"), bank.AuditEntry{
+		Action:     bank.AuditActionAccountOverdraft,
+		EntityType: bank.AuditEntityAccount,
+		EntityIds:  []string{before.AccountUuid.String(), before.AccountNumber},
+		Before:     before,
+		After:      account,
+		Err:        err,
+	})
+
+	if err != nil {
+		log.Fatalln("can't set overdraft limit", err)
+	}
+
+	printAccounts(*output, account)
+}
+
+func printAccounts(format string, accounts ...bank.Account) {
+	rows := make([][]string, 0, len(accounts))
+	for _, a := range accounts {
+		rows = append(rows, []string{a.AccountNumber, a.AccountName, a.Currency, formatAmount(a.CurrentBalance),
+			formatAmount(a.OverdraftLimit), a.Product, a.Status, a.CreatedAt.Format(time.RFC3339), a.AccountUuid.String()})
+	}
+
+	var v interface{} = accounts
+	if len(accounts) == 1 {
+		v = accounts[0]
+	}
+
+	printOutput(format, v, []string{"NUMBER", "NAME", "CURRENCY", "BALANCE", "OVERDRAFT", "PRODUCT", "STATUS",
+		"CREATED", "UUID"}, rows)
+}
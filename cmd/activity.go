@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+)
+
+// watchAccountActivity runs the activity feed and wakes it up on outbox notifications, polling keeps the feed going
+// while the listener reconnects
+func watchAccountActivity(feed *app.ActivityFeed, dbAdapter *database.DatabaseAdapter, interval time.Duration) {
+	go func() {
+		for {
+			if err := dbAdapter.ListenOutboxEvents(context.Background(), feed.Notify); err != nil {
+				log.Println("outbox listener stopped", err)
+			}
+
+			time.Sleep(interval)
+		}
+	}()
+
+	for {
+		if err := feed.Run(context.Background(), interval); err != nil {
+			log.Println("account activity feed stopped", err)
+		}
+
+		time.Sleep(interval)
+	}
+}
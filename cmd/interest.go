@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+)
+
+// accrueInterest accrues interest up to the previous day (UTC), catching up the days missed since the last complete
+// run. A day is accrued once, so only the first run after midnight posts anything
+func accrueInterest(is *app.InterestService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for range ticker.C {
+		reports, err := is.AccrueInterestThrough(time.Now().UTC().AddDate(0, 0, -1))
+
+		for _, report := range reports {
+			if len(report.Accruals) > 0 || report.Failed > 0 {
+				log.Printf("interest accrued for %v : %d accounts checked, %d accruals, %d failed\n",
+					report.AccrualDate.Format(time.DateOnly), report.AccountsChecked, len(report.Accruals),
+					report.Failed)
+			}
+		}
+
+		if err != nil {
+			log.Println("interest accrual failed", err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+// runLimitsCommand shows and changes the limits on outgoing money of an account, e.g.
+// my-grpc-server limits show -number 7835697001
+// my-grpc-server limits tier -number 7835697001 -tier PREMIUM
+// my-grpc-server limits set -number 7835697001 -daily-out 5000 -max-transfers-per-hour 10
+func runLimitsCommand(args []string) {
+	if len(args) == 0 {
+		printLimitsUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "show":
+		runLimitsShowCommand(args[1:])
+	case "tier":
+		runLimitsTierCommand(args[1:])
+	case "set":
+		runLimitsSetCommand(args[1:])
+	default:
+		printLimitsUsage()
+		os.Exit(2)
+	}
+}
+
+func printLimitsUsage() {
+	fmt.Fprintln(os.Stderr, "usage : my-grpc-server limits show|tier|set [flags]")
+}
+
+func runLimitsShowCommand(args []string) {
+	fs := flag.NewFlagSet("limits show", flag.ExitOnError)
+	number := fs.String("number", "", "account number")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *number == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	limits, err := newServices(openDatabaseAdapter()).limits.FindAccountLimits(*number)
+	if err != nil {
+		log.Fatalln("can't find account limits", err)
+	}
+
+	printLimits(*output, limits)
+}
+
+func runLimitsTierCommand(args []string) {
+	fs := flag.NewFlagSet("limits tier", flag.ExitOnError)
+	number := fs.String("number", "", "account number")
+	tier := fs.String("tier", "", "limit tier of the account")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *number == "" || *tier == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	svc := newServices(openDatabaseAdapter())
+
+	before, err := svc.limits.FindAccountLimits(*number)
+	if err != nil {
+		log.Fatalln("can't find account limits", err)
+	}
+
+	err = svc.limits.SetAccountTier(*number, *tier)
+
+	recordLimitsChange(svc, "limits tier", bank.AuditActionAccountTier, *number, before, err)
+
+	if err != nil {
+		log.Fatalln("can't set account tier", err)
+	}
+
+	showLimits(svc, *output, *number)
+}
+
+// runLimitsSetCommand overrides the limits of the tier for the account, a limit left empty falls back to the tier
+func runLimitsSetCommand(args []string) {
+	fs := flag.NewFlagSet("limits set", flag.ExitOnError)
+	number := fs.String("number", "", "account number")
+	maxSingleOut := fs.String("max-single-out", "", "largest single outgoing amount, empty for the tier limit")
+	dailyOut := fs.String("daily-out", "", "outgoing amount per day, empty for the tier limit")
+	monthlyOut := fs.String("monthly-out", "", "outgoing amount per month, empty for the tier limit")
+	transfersPerHour := fs.String("max-transfers-per-hour", "", "transfers per hour, empty for the tier limit")
+	minimumBalance := fs.Float64("minimum-balance", 0, "balance the account must keep")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *number == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	limits := bank.AccountLimits{
+		MaxSingleOut:   parseLimitAmount("max-single-out", *maxSingleOut),
+		DailyOut:       parseLimitAmount("daily-out", *dailyOut),
+		MonthlyOut:     parseLimitAmount("monthly-out", *monthlyOut),
+		MinimumBalance: *minimumBalance,
+	}
+
+	if *transfersPerHour != "" {
+		n, err := strconv.Atoi(*transfersPerHour)
+		if err != nil {
+			log.Fatalf("invalid max-transfers-per-hour %q : %v", *transfersPerHour, err)
+		}
+
+		limits.MaxTransfersPerHour = &n
+	}
+
+	svc := newServices(openDatabaseAdapter())
+
+	before, err := svc.limits.FindAccountLimits(*number)
+	if err != nil {
+		log.Fatalln("can't find account limits", err)
+	}
+
+	err = svc.limits.SetAccountLimits(*number, limits)
+
+	recordLimitsChange(svc, "limits set", bank.AuditActionAccountLimits, *number, before, err)
+
+	if err != nil {
+		log.Fatalln("can't set account limits", err)
+	}
+
+	showLimits(svc, *output, *number)
+}
+
+func parseLimitAmount(name string, value string) *float64 {
+	if value == "" {
+		return nil
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("invalid %v %q : %v", name, value, err)
+	}
+
+	return &amount
+}
+
+// recordLimitsChange writes the audit record with the effective limits before and after the change
+func recordLimitsChange(svc services, command string, action string, accountNumber string, before bank.AccountLimits,
+	err error) {
+	entry := bank.AuditEntry{
+		Action:     action,
+		EntityType: bank.AuditEntityAccount,
+		EntityIds:  []string{accountNumber},
+		Before:     before,
+		Err:        err,
+	}
+
+	if err == nil {
+		if after, findErr := svc.limits.FindAccountLimits(accountNumber); findErr == nil {
+			entry.After = after
+		}
+	}
+
+	svc.audit.Record(cliAuditContext(command), entry)
+}
+
+func showLimits(svc services, format string, accountNumber string) {
+	limits, err := svc.limits.FindAccountLimits(accountNumber)
+	if err != nil {
+		log.Fatalln("can't find account limits", err)
+	}
+
+	printLimits(format, limits)
+}
+
+func printLimits(format string, limits bank.AccountLimits) {
+	amount := func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+
+		return formatAmount(*v)
+	}
+
+	transfersPerHour := "-"
+	if limits.MaxTransfersPerHour != nil {
+		transfersPerHour = strconv.Itoa(*limits.MaxTransfersPerHour)
+	}
+
+	printOutput(format, limits,
+		[]string{"TIER", "MAX SINGLE OUT", "DAILY OUT", "MONTHLY OUT", "TRANSFERS PER HOUR", "MINIMUM BALANCE"},
+		[][]string{{limits.AccountTier, amount(limits.MaxSingleOut), amount(limits.DailyOut),
+			amount(limits.MonthlyOut), transfersPerHour, formatAmount(limits.MinimumBalance)}})
+}
@@ -2,12 +2,21 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
 )
 
+// logWriter prefixes the time, out is stdout when nil
 type logWriter struct {
+	out io.Writer
 }
 
 func (l logWriter) Write(bytes []byte) (int, error) {
-	return fmt.Print(time.Now().Format("15:04:05") + " " + string(bytes))
+	out := l.out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	return fmt.Fprint(out, time.Now().Format("15:04:05")+" "+string(bytes))
 }
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"log"
+
+	dbmigration "github.com/Just-Goo/grpc-go-server/db"
+)
+
+// runMigrateCommand applies the pending migrations, -reset drops everything first like the server does, e.g.
+// my-grpc-server migrate
+func runMigrateCommand(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	reset := fs.Bool("reset", false, "migrate down first, this deletes all the data")
+	fs.Parse(args)
+
+	db, err := sql.Open("pgx", databaseUrl)
+	if err != nil {
+		log.Fatalln("can't connect to database", err)
+	}
+	defer db.Close()
+
+	if *reset {
+		dbmigration.Migrate(db)
+		return
+	}
+
+	dbmigration.MigrateUp(db)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/eventsink"
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+)
+
+// newEventSink returns where the outbox events go, spec is "stdout", "file:<path>", an http(s) URL for a webhook, or
+// "memory"
+func newEventSink(spec string) port.EventSinkPort {
+	switch {
+	case spec == "stdout":
+		return eventsink.NewStdoutSink()
+	case spec == "memory":
+		return eventsink.NewBrokerSink(eventsink.NewMemoryBroker(), "bank.")
+	case strings.HasPrefix(spec, "file:"):
+		sink, _, err := eventsink.NewFileSink(strings.TrimPrefix(spec, "file:"))
+		if err != nil {
+			log.Fatalln("can't open outbox file", err)
+		}
+
+		return sink
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return eventsink.NewWebhookSink(spec)
+	default:
+		log.Fatalln("unknown outbox sink", spec)
+		return nil
+	}
+}
+
+func relayOutboxEvents(relay *app.OutboxRelay, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for range ticker.C {
+		if _, err := relay.Relay(context.Background()); err != nil {
+			log.Println("outbox relay failed", err)
+		}
+	}
+}
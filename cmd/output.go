@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJson  = "json"
+)
+
+func outputFlag(fs *flag.FlagSet) *string {
+	return fs.String("output", outputTable, "output format (table or json)")
+}
+
+// checkOutputFormat is called before the command runs, so a typo doesn't throw the result of a transfer away
+func checkOutputFormat(format string) {
+	if format != outputTable && format != outputJson {
+		log.Fatalf("unknown output format %v, use table or json", format)
+	}
+}
+
+// printOutput writes v as indented JSON, or the rows under the header as an aligned table
+func printOutput(format string, v interface{}, header []string, rows [][]string) {
+	checkOutputFormat(format)
+
+	switch format {
+	case outputJson:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(v); err != nil {
+			log.Fatalln("can't write output", err)
+		}
+	case outputTable:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+
+		w.Flush()
+	}
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+// the CSV columns of rates import and export, times are RFC 3339
+var ratesCsvHeader = []string{"from_currency", "to_currency", "rate", "valid_from", "valid_to"}
+
+// runRatesCommand loads or dumps exchange rates as CSV, e.g.
+// my-grpc-server rates import -file rates.csv
+// my-grpc-server rates export -from USD -to IDR -start 2024-05-01 -end 2024-06-01 -file rates.csv
+func runRatesCommand(args []string) {
+	if len(args) == 0 {
+		printRatesUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "import":
+		runRatesImportCommand(args[1:])
+	case "export":
+		runRatesExportCommand(args[1:])
+	default:
+		printRatesUsage()
+		os.Exit(2)
+	}
+}
+
+func printRatesUsage() {
+	fmt.Fprintln(os.Stderr, "usage : my-grpc-server rates import|export [flags]")
+}
+
+func runRatesImportCommand(args []string) {
+	fs := flag.NewFlagSet("rates import", flag.ExitOnError)
+	file := fs.String("file", "", "CSV file ("+strings.Join(ratesCsvHeader, ",")+"), - for stdin")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *file == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	var in io.Reader = os.Stdin
+
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("can't open %v : %v", *file, err)
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	r := csv.NewReader(in)
+	r.FieldsPerRecord = len(ratesCsvHeader)
+
+	if _, err := r.Read(); err != nil {
+		log.Fatalf("can't read header of %v : %v", *file, err)
+	}
+
+	svc := newServices(openDatabaseAdapter())
+	ctx := cliAuditContext("rates import")
+
+	var imported []bank.ExchangeRate
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			log.Fatalf("can't read %v : %v", *file, err)
+		}
+
+		line, _ := r.FieldPos(0)
+
+		rate, err := parseRateRecord(record)
+		if err != nil {
+			log.Fatalf("line %d : %v (%d rates imported before)", line, err, len(imported))
+		}
+
+		if err := createExchangeRate(svc, ctx, rate); err != nil {
+			log.Fatalf("line %d : can't create rate : %v (%d rates imported before)", line, err, len(imported))
+		}
+
+		imported = append(imported, rate)
+	}
+
+	printRates(*output, imported)
+}
+
+// createExchangeRate creates and audits one rate
+func createExchangeRate(svc services, ctx context.Context, rate bank.ExchangeRate) error {
+	rateUuid, err := svc.bank.CreateExchangeRate(rate)
+
+	entry := bank.AuditEntry{
+		Action:     bank.AuditActionExchangeRateCreate,
+		EntityType: bank.AuditEntityExchangeRate,
+		After:      rate,
+		Err:        err,
+	}
+
+	if err == nil {
+		entry.EntityIds = []string{rateUuid.String()}
+	}
+
+	svc.audit.Record(ctx, entry)
+
+	return err
+}
+
+func parseRateRecord(record []string) (bank.ExchangeRate, error) {
+	rate, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+	if err != nil || rate <= 0 {
+		return bank.ExchangeRate{}, fmt.Errorf("invalid rate %v", record[2])
+	}
+
+	validFrom, err := time.Parse(time.RFC3339, strings.TrimSpace(record[3]))
+	if err != nil {
+		return bank.ExchangeRate{}, fmt.Errorf("invalid valid_from : %v", err)
+	}
+
+	validTo, err := time.Parse(time.RFC3339, strings.TrimSpace(record[4]))
+	if err != nil {
+		return bank.ExchangeRate{}, fmt.Errorf("invalid valid_to : %v", err)
+	}
+
+	if !validFrom.Before(validTo) {
+		return bank.ExchangeRate{}, bank.ErrInvalidTimeRange
+	}
+
+	return bank.ExchangeRate{
+		FromCurrency:       strings.TrimSpace(record[0]),
+		ToCurrency:         strings.TrimSpace(record[1]),
+		Rate:               rate,
+		ValidFromTimestamp: validFrom,
+		ValidToTimestamp:   validTo,
+	}, nil
+}
+
+func runRatesExportCommand(args []string) {
+	now := time.Now()
+
+	fs := flag.NewFlagSet("rates export", flag.ExitOnError)
+	from := fs.String("from", "USD", "from currency")
+	to := fs.String("to", "IDR", "to currency")
+	start := fs.String("start", now.Add(-24*time.Hour).Format(time.RFC3339), "rates valid from (RFC 3339 or YYYY-MM-DD)")
+	end := fs.String("end", now.Format(time.RFC3339), "rates valid from before (RFC 3339 or YYYY-MM-DD)")
+	file := fs.String("file", "-", "CSV file, - for stdout")
+	fs.Parse(args)
+
+	startTime, err := parseCommandTime(*start)
+	if err != nil {
+		log.Fatalf("invalid start %v : %v", *start, err)
+	}
+
+	endTime, err := parseCommandTime(*end)
+	if err != nil {
+		log.Fatalf("invalid end %v : %v", *end, err)
+	}
+
+	var out io.Writer = os.Stdout
+
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			log.Fatalf("can't create %v : %v", *file, err)
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	w := csv.NewWriter(out)
+	w.Write(ratesCsvHeader)
+
+	svc := newServices(openDatabaseAdapter())
+
+	pageToken := ""
+	count := 0
+
+	for {
+		page, err := svc.bank.FindExchangeRateHistory(*from, *to, startTime, endTime, pageToken, 1000)
+		if err != nil {
+			log.Fatalln("can't read exchange rates", err)
+		}
+
+		for _, r := range page.Rates {
+			w.Write([]string{r.FromCurrency, r.ToCurrency, strconv.FormatFloat(r.Rate, 'f', -1, 64),
+				r.ValidFromTimestamp.Format(time.RFC3339Nano), r.ValidToTimestamp.Format(time.RFC3339Nano)})
+		}
+
+		count += len(page.Rates)
+
+		if page.NextPageToken == "" {
+			break
+		}
+
+		pageToken = page.NextPageToken
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalln("can't write exchange rates", err)
+	}
+
+	log.Printf("%d %v/%v rates exported\n", count, *from, *to)
+}
+
+func printRates(format string, rates []bank.ExchangeRate) {
+	rows := make([][]string, 0, len(rates))
+	for _, r := range rates {
+		rows = append(rows, []string{r.FromCurrency, r.ToCurrency, strconv.FormatFloat(r.Rate, 'f', -1, 64),
+			r.ValidFromTimestamp.Format(time.RFC3339), r.ValidToTimestamp.Format(time.RFC3339)})
+	}
+
+	printOutput(format, rates, []string{"FROM", "TO", "RATE", "VALID FROM", "VALID TO"}, rows)
+}
+
+// parseCommandTime takes an RFC 3339 time or a date, which is midnight UTC
+func parseCommandTime(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strconv"
+	"time"
+
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+// runReconcileCommand runs one reconciliation and prints the drift report, e.g.
+// my-grpc-server reconcile -repair -output json
+func runReconcileCommand(args []string) {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "post adjusting journal entries for balances that drifted from their history")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	checkOutputFormat(*output)
+
+	dbAdapter := openDatabaseAdapter()
+
+	report, err := app.NewReconciliationService(dbAdapter).Reconcile(*repair)
+	if err != nil {
+		log.Fatalln("reconciliation failed", err)
+	}
+
+	// every adjustment changed a balance, it gets its own audit record
+	audit := app.NewAuditService(dbAdapter)
+	ctx := cliAuditContext("reconcile")
+
+	for _, m := range report.Mismatches {
+		if !m.Repaired {
+			continue
+		}
+
+		audit.Record(ctx, bank.AuditEntry{
+			Action:     bank.AuditActionAccountAdjust,
+			EntityType: bank.AuditEntityAccount,
+			EntityIds:  []string{m.AccountNumber, m.AdjustmentJournalUuid.String(), report.RunUuid.String()},
+			Before:     map[string]float64{"balance": m.StoredBalance, "ledger_balance": m.LedgerBalance},
+			After:      m,
+		})
+	}
+
+	log.Printf("reconciliation %v : %d accounts checked, %d mismatches (repair %v)\n", report.RunUuid,
+		report.AccountsChecked, len(report.Mismatches), report.Repair)
+
+	rows := make([][]string, 0, len(report.Mismatches))
+	for _, m := range report.Mismatches {
+		rows = append(rows, []string{m.AccountNumber, formatAmount(m.StoredBalance), formatAmount(m.ExpectedBalance),
+			formatAmount(m.LedgerBalance), formatAmount(m.Difference), strconv.FormatBool(m.Repaired)})
+	}
+
+	printOutput(*output, report, []string{"ACCOUNT", "STORED", "EXPECTED", "LEDGER", "DIFFERENCE", "REPAIRED"},
+		rows)
+}
+
+func reconcileBalances(rs *app.ReconciliationService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for range ticker.C {
+		report, err := rs.Reconcile(false)
+		if err != nil {
+			log.Println("reconciliation failed", err)
+			continue
+		}
+
+		printReconciliationReport(report)
+	}
+}
+
+func printReconciliationReport(report bank.ReconciliationReport) {
+	log.Printf("reconciliation %v : %d accounts checked, %d mismatches (repair %v)\n", report.RunUuid,
+		report.AccountsChecked, len(report.Mismatches), report.Repair)
+
+	for _, m := range report.Mismatches {
+		log.Printf("  account %v : stored %.2f, expected %.2f, ledger %.2f, difference %.2f, repaired %v\n",
+			m.AccountNumber, m.StoredBalance, m.ExpectedBalance, m.LedgerBalance, m.Difference, m.Repaired)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+)
+
+func executeScheduledTransfers(ss *app.ScheduledTransferService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for range ticker.C {
+		if _, err := ss.ExecuteDueTransfers(); err != nil {
+			log.Println("can't execute scheduled transfers", err)
+		}
+	}
+}
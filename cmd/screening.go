@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+)
+
+// screeningListPath is the sanctions list, relative to the working directory
+const screeningListPath = "config/screening_list.csv"
+
+// loadScreeningList loads the list for a subcommand that screens, screening fails closed so the command can't run
+// without it
+func loadScreeningList(svc services) {
+	if _, err := svc.screening.Reload(); err != nil {
+		log.Fatalln("can't load the screening list", err)
+	}
+}
+
+// reloadScreeningList picks up the changes of the list file, a broken file keeps the previous list
+func reloadScreeningList(ss *app.ScreeningService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for range ticker.C {
+		if _, err := ss.Reload(); err != nil {
+			log.Println(err)
+		}
+	}
+}
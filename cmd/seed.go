@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+// runSeedCommand creates demo accounts and exchange rates on a migrated database, e.g.
+// my-grpc-server seed -accounts 3 -currencies USD,IDR -rates USD/IDR=16000
+func runSeedCommand(args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	count := fs.Int("accounts", 2, "accounts per currency")
+	currencies := fs.String("currencies", "USD,IDR", "comma separated account currencies")
+	deposit := fs.Float64("deposit", 1000, "initial deposit of each account")
+	rates := fs.String("rates", "USD/IDR=16000", "comma separated FROM/TO=RATE exchange rates")
+	validity := fs.Duration("validity", 24*time.Hour, "how long the exchange rates are valid from now")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	checkOutputFormat(*output)
+
+	seedRates, err := parseSeedRates(*rates, *validity)
+	if err != nil {
+		log.Fatalln("invalid rates", err)
+	}
+
+	svc := newServices(openDatabaseAdapter())
+	loadScreeningList(svc)
+	ctx := cliAuditContext("seed")
+
+	for _, r := range seedRates {
+		if err := createExchangeRate(svc, ctx, r); err != nil {
+			log.Fatalf("can't create %v/%v rate : %v", r.FromCurrency, r.ToCurrency, err)
+		}
+	}
+
+	var accounts []bank.Account
+
+	for _, currency := range splitList(*currencies) {
+		for i := 1; i <= *count; i++ {
+			na := bank.NewAccount{
+				AccountName:          fmt.Sprintf("Demo %v %d", currency, i),
+				Currency:             currency,
+				InitialDepositAmount: *deposit,
+			}
+
+			account, err := svc.account.CreateAccount(na)
+
+			entry := bank.AuditEntry{
+				Action:     bank.AuditActionAccountCreate,
+				EntityType: bank.AuditEntityAccount,
+				After:      na,
+				Err:        err,
+			}
+
+			if err == nil {
+				entry.EntityIds = []string{account.AccountUuid.String(), account.AccountNumber}
+				entry.After = account
+			}
+
+			svc.audit.Record(ctx, entry)
+
+			if err != nil {
+				log.Fatalf("can't create %v account : %v", currency, err)
+			}
+
+			accounts = append(accounts, account)
+		}
+	}
+
+	log.Printf("seeded %d exchange rates and %d accounts\n", len(seedRates), len(accounts))
+
+	printAccounts(*output, accounts...)
+}
+
+// parseSeedRates reads FROM/TO=RATE pairs, valid from now for validity
+func parseSeedRates(s string, validity time.Duration) ([]bank.ExchangeRate, error) {
+	now := time.Now().Truncate(time.Second)
+
+	var rates []bank.ExchangeRate
+
+	for _, pair := range splitList(s) {
+		currencies, value, ok := strings.Cut(pair, "=")
+		from, to, ok2 := strings.Cut(currencies, "/")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("%v is not FROM/TO=RATE", pair)
+		}
+
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate %v", value)
+		}
+
+		rates = append(rates, bank.ExchangeRate{
+			FromCurrency:       strings.ToUpper(from),
+			ToCurrency:         strings.ToUpper(to),
+			Rate:               rate,
+			ValidFromTimestamp: now,
+			ValidToTimestamp:   now.Add(validity),
+		})
+	}
+
+	return rates, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
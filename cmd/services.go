@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/user"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+)
+
+// services are built the same way for the server and the subcommands, so an operation from the command line goes
+// through the same limits, fees, risk and screening checks. The screening list isn't loaded yet, the server and the
+// subcommands that screen load it themselves
+type services struct {
+	screening *app.ScreeningService
+	bank      *app.BankService
+	account   *app.AccountService
+	limits    *app.LimitService
+	audit     *app.AuditService
+}
+
+func newServices(dbAdapter *database.DatabaseAdapter) services {
+	currencies := bank.DefaultCurrencyRegistry()
+
+	screening := app.NewScreeningService(dbAdapter, screeningListPath, bank.DefaultNameMatchThreshold)
+
+	// the seeded accounts kept their numbers without check digit
+	legacyNumbers, err := dbAdapter.GetLegacyAccountNumbers()
+	if err != nil {
+		log.Fatalln("can't read the legacy account numbers", err)
+	}
+
+	limits := app.NewLimitService(dbAdapter)
+
+	accountNumbers := bank.NewGrandfatheredAccountNumberScheme(bank.DefaultAccountNumberScheme(), legacyNumbers...)
+
+	return services{
+		screening: screening,
+		bank: app.NewBankService(dbAdapter).
+			WithCurrencyRegistry(currencies).
+			WithLimitService(limits).
+			WithFeeService(app.NewFeeService(dbAdapter)).
+			WithRiskEvaluator(app.NewRuleBasedRiskEvaluator(dbAdapter, bank.DefaultRiskRules())).
+			WithScreeningService(screening),
+		account: app.NewAccountService(dbAdapter, currencies, accountNumbers).
+			WithScreeningService(screening),
+		limits: limits,
+		audit:  app.NewAuditService(dbAdapter),
+	}
+}
+
+// cliAuditContext records the subcommands in the audit log as the operating system user
+func cliAuditContext(command string) context.Context {
+	actor := "cli"
+	if u, err := user.Current(); err == nil {
+		actor = "cli:" + u.Username
+	}
+
+	return bank.WithAuditContext(context.Background(), bank.AuditContext{
+		Actor:     actor,
+		Method:    "cli " + command,
+		RequestId: uuid.NewString(),
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/statement"
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+// runStatementCommand writes the monthly statement of an account to a file, e.g.
+// my-grpc-server statement -account 7835697001 -month 2024-05 -format pdf -out statement.pdf
+func runStatementCommand(args []string) {
+	fs := flag.NewFlagSet("statement", flag.ExitOnError)
+	account := fs.String("account", "", "account number")
+	month := fs.String("month", time.Now().Format("2006-01"), "statement month (YYYY-MM)")
+	format := fs.String("format", bank.StatementFormatPDF, "output format (csv or pdf)")
+	out := fs.String("out", "", "output file (default statement-<account>-<month>.<format>)")
+	fs.Parse(args)
+
+	if *account == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	m, err := time.Parse("2006-01", *month)
+	if err != nil {
+		log.Fatalf("invalid month %v : %v", *month, err)
+	}
+
+	if *out == "" {
+		*out = "statement-" + *account + "-" + *month + "." + *format
+	}
+
+	dbAdapter := openDatabaseAdapter()
+
+	start, end := bank.MonthPeriod(m.Year(), m.Month())
+
+	stmt, err := app.NewBankService(dbAdapter).GenerateStatement(*account, start, end)
+	if err != nil {
+		log.Fatalf("can't generate statement for %v : %v", *account, err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("can't create %v : %v", *out, err)
+	}
+	defer f.Close()
+
+	if err := statement.Render(f, stmt, *format); err != nil {
+		log.Fatalf("can't render statement : %v", err)
+	}
+
+	log.Printf("statement written to %v (%d transactions)\n", *out, len(stmt.Lines))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+)
+
+// runSummariesCommand manages the daily transaction summaries, e.g.
+// my-grpc-server summaries rebuild
+// my-grpc-server summaries show -account 7835697001 -from 2024-05-01 -to 2024-05-31 -output csv
+func runSummariesCommand(args []string) {
+	if len(args) == 0 {
+		printSummariesUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "rebuild":
+		runSummariesRebuildCommand(args[1:])
+	case "show":
+		runSummariesShowCommand(args[1:])
+	default:
+		printSummariesUsage()
+		os.Exit(2)
+	}
+}
+
+func printSummariesUsage() {
+	fmt.Fprintln(os.Stderr, "usage : my-grpc-server summaries rebuild|show [flags]")
+}
+
+// runSummariesRebuildCommand recomputes every daily summary from the transactions, e.g. after a backfill
+func runSummariesRebuildCommand(args []string) {
+	fs := flag.NewFlagSet("summaries rebuild", flag.ExitOnError)
+	fs.Parse(args)
+
+	rows, err := app.NewBankService(openDatabaseAdapter()).RebuildTransactionSummaries()
+	if err != nil {
+		log.Fatalln("can't rebuild transaction summaries", err)
+	}
+
+	fmt.Printf("%d daily summaries rebuilt\n", rows)
+}
+
+func runSummariesShowCommand(args []string) {
+	fs := flag.NewFlagSet("summaries show", flag.ExitOnError)
+	account := fs.String("account", "", "account number")
+	from := fs.String("from", time.Now().UTC().AddDate(0, 0, -30).Format(time.DateOnly), "first day (YYYY-MM-DD)")
+	to := fs.String("to", time.Now().UTC().Format(time.DateOnly), "last day (YYYY-MM-DD), included")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *account == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	startDate, err := time.Parse(time.DateOnly, *from)
+	if err != nil {
+		log.Fatalf("invalid -from %v : %v", *from, err)
+	}
+
+	endDate, err := time.Parse(time.DateOnly, *to)
+	if err != nil {
+		log.Fatalf("invalid -to %v : %v", *to, err)
+	}
+
+	summaries, err := app.NewBankService(openDatabaseAdapter()).FindTransactionSummaries(*account, startDate, endDate)
+	if err != nil {
+		log.Fatalf("can't find transaction summaries of %v : %v", *account, err)
+	}
+
+	rows := make([][]string, 0, len(summaries))
+	for _, s := range summaries {
+		rows = append(rows, []string{s.SummaryOnDate.Format(time.DateOnly), formatAmount(s.SumIn),
+			formatAmount(s.SumOut), formatAmount(s.SumTotal), strconv.Itoa(s.TransactionCount)})
+	}
+
+	printOutput(*output, summaries, []string{"DATE", "IN", "OUT", "TOTAL", "COUNT"}, rows)
+}
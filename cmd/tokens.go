@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// readActorTokens reads the token file given to serve -actor-tokens, one "<actor> <hex sha256 of the token>" per
+// line. Lines starting with # are comments. It returns the actors by token hash
+func readActorTokens(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%v line %d : expected an actor and a token hash", path, line)
+		}
+
+		hash := strings.ToLower(fields[1])
+		if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("%v line %d : the token hash must be a hex sha256", path, line)
+		}
+
+		if actor, ok := tokens[hash]; ok {
+			return nil, fmt.Errorf("%v line %d : the token of %v is already used by %v", path, line, fields[0], actor)
+		}
+
+		tokens[hash] = fields[0]
+	}
+
+	return tokens, scanner.Err()
+}
+
+// runTokenCommand creates a token for an actor, e.g.
+// my-grpc-server token -actor alice
+// the token is printed once, only the line with its hash goes into the token file
+func runTokenCommand(args []string) {
+	fs := flag.NewFlagSet("token", flag.ExitOnError)
+	actor := fs.String("actor", "", "who the token authenticates")
+	fs.Parse(args)
+
+	if *actor == "" || strings.ContainsAny(*actor, " \t") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalln("can't create token", err)
+	}
+
+	token := hex.EncodeToString(b)
+	sum := sha256.Sum256([]byte(token))
+
+	fmt.Printf("token : %v\n", token)
+	fmt.Printf("token file line : %v %v\n", *actor, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+)
+
+// runTransferCommand transfers money with the same checks as the TransferMultiple RPC, e.g.
+// my-grpc-server transfer -from 7835697001 -to 7835697002 -currency USD -amount 25
+func runTransferCommand(args []string) {
+	fs := flag.NewFlagSet("transfer", flag.ExitOnError)
+	from := fs.String("from", "", "source account number")
+	to := fs.String("to", "", "destination account number")
+	currency := fs.String("currency", "USD", "transfer currency")
+	amount := fs.Float64("amount", 0, "transfer amount")
+	output := outputFlag(fs)
+	fs.Parse(args)
+
+	if *from == "" || *to == "" || *amount <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	checkOutputFormat(*output)
+
+	svc := newServices(openDatabaseAdapter())
+	loadScreeningList(svc)
+
+	tt := bank.TransferTransaction{
+		FromAccountNumber: *from,
+		ToAccountNumber:   *to,
+		Currency:          *currency,
+		Amount:            *amount,
+	}
+
+	result, err := svc.bank.Transfer(tt)
+
+	entry := bank.AuditEntry{
+		Action:     bank.AuditActionTransferExecute,
+		EntityType: bank.AuditEntityTransfer,
+		EntityIds:  []string{tt.FromAccountNumber, tt.ToAccountNumber},
+		After:      result,
+		Err:        err,
+	}
+
+	if result.TransferUuid != uuid.Nil {
+		entry.EntityIds = append([]string{result.TransferUuid.String()}, entry.EntityIds...)
+	}
+
+	svc.audit.Record(cliAuditContext("transfer"), entry)
+
+	if err != nil {
+		if result.TransferUuid != uuid.Nil {
+			log.Fatalf("transfer %v failed : %v", result.TransferUuid, err)
+		}
+
+		log.Fatalln("transfer failed", err)
+	}
+
+	status := bank.TransferStatusSettled
+	if result.Held {
+		status = bank.TransferStatusHeld
+	}
+
+	q := result.Quote
+
+	printOutput(*output, result, []string{"TRANSFER", "STATUS", "DEBIT", "CREDIT", "FEE"}, [][]string{
+		{
+			result.TransferUuid.String(),
+			status,
+			formatAmount(q.DebitAmount) + " " + q.DebitCurrency,
+			formatAmount(q.CreditAmount) + " " + q.CreditCurrency,
+			formatAmount(q.Fee) + " " + q.DebitCurrency,
+		},
+	})
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+)
+
+// transfers younger than this may still be running on another server
+const transferRecoveryAge = 1 * time.Minute
+
+// recoverTransfers resolves the stuck transfers once at startup and then at every interval
+func recoverTransfers(ts *app.TransferRecoveryService, interval time.Duration) {
+	for {
+		if _, err := ts.RecoverTransfers(transferRecoveryAge); err != nil {
+			log.Println("transfer recovery failed", err)
+		}
+
+		time.Sleep(interval)
+	}
+}
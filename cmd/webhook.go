@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	app "github.com/Just-Goo/grpc-go-server/internal/application"
+)
+
+func deliverWebhooks(ws *app.WebhookService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for range ticker.C {
+		if _, err := ws.DeliverDueWebhooks(context.Background()); err != nil {
+			log.Println("can't deliver webhooks", err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package dbmigration
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 
 	migrate "github.com/golang-migrate/migrate/v4"
@@ -12,16 +13,7 @@ import (
 func Migrate(conn *sql.DB) {
 	log.Println("Database migration start")
 
-	driver, err := postgres.WithInstance(conn, &postgres.Config{})
-	if err != nil {
-		log.Fatalf("could not start migration: %s", err)
-	}
-
-	m, err := migrate.NewWithDatabaseInstance("file://db/migrations", "postgres", driver)
-
-	if err != nil {
-		log.Fatalf("database migration failed: %s", err)
-	}
+	m := newMigrate(conn)
 
 	// run migration down
 	if err := m.Down(); err != nil {
@@ -35,3 +27,29 @@ func Migrate(conn *sql.DB) {
 
 	log.Println("database migration complete")
 }
+
+// MigrateUp only applies the migrations not applied yet, the data is kept
+func MigrateUp(conn *sql.DB) {
+	log.Println("Database migration start")
+
+	if err := newMigrate(conn).Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		log.Fatalf("database migration up failed: %s", err)
+	}
+
+	log.Println("database migration complete")
+}
+
+func newMigrate(conn *sql.DB) *migrate.Migrate {
+	driver, err := postgres.WithInstance(conn, &postgres.Config{})
+	if err != nil {
+		log.Fatalf("could not start migration: %s", err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance("file://db/migrations", "postgres", driver)
+
+	if err != nil {
+		log.Fatalf("database migration failed: %s", err)
+	}
+
+	return m
+}
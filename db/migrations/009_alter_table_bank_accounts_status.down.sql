@@ -0,0 +1,5 @@
+DROP SEQUENCE IF EXISTS bank_account_number_seq;
+
+ALTER TABLE bank_accounts
+    DROP COLUMN IF EXISTS closed_at,
+    DROP COLUMN IF EXISTS account_status;
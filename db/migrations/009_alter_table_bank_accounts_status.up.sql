@@ -0,0 +1,5 @@
+ALTER TABLE bank_accounts
+    ADD COLUMN IF NOT EXISTS account_status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    ADD COLUMN IF NOT EXISTS closed_at      TIMESTAMPTZ;
+
+CREATE SEQUENCE IF NOT EXISTS bank_account_number_seq START WITH 1;
@@ -0,0 +1,6 @@
+SELECT setval('bank_account_number_seq', last_value, is_called) FROM bank_account_number_seq_previous;
+
+DROP TABLE IF EXISTS bank_account_number_seq_previous;
+
+ALTER TABLE bank_accounts
+    DROP COLUMN IF EXISTS legacy_account_number;
@@ -0,0 +1,14 @@
+-- the accounts opened before the prefix + sequence + Luhn check digit format keep their number, the validator accepts
+-- them as they are
+ALTER TABLE bank_accounts
+    ADD COLUMN IF NOT EXISTS legacy_account_number BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE bank_accounts SET legacy_account_number = TRUE;
+
+-- keep the sequence as it was for the down migration
+CREATE TABLE IF NOT EXISTS bank_account_number_seq_previous AS
+SELECT last_value, is_called FROM bank_account_number_seq;
+
+-- the new numbers continue after the seeded ones, the sequence 69700 would share its digits with the legacy numbers
+-- 7835697001 to 7835697005
+ALTER SEQUENCE bank_account_number_seq RESTART WITH 69701;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bank_transactions_account_type_timestamp;
+
+DROP INDEX IF EXISTS idx_bank_transactions_account_timestamp;
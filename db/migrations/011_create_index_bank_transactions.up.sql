@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_bank_transactions_account_timestamp
+    ON bank_transactions (account_uuid, transaction_timestamp, transaction_uuid);
+
+CREATE INDEX IF NOT EXISTS idx_bank_transactions_account_type_timestamp
+    ON bank_transactions (account_uuid, transaction_type, transaction_timestamp, transaction_uuid);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS bank_transaction_daily_summaries CASCADE;
\ No newline at end of file
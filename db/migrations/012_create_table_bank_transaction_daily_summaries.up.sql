@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS bank_transaction_daily_summaries(
+    account_uuid            UUID            NOT NULL REFERENCES bank_accounts,
+    summary_date            DATE            NOT NULL,
+    sum_in                  NUMERIC(15,2)   NOT NULL DEFAULT 0,
+    sum_out                 NUMERIC(15,2)   NOT NULL DEFAULT 0,
+    transaction_count       INTEGER         NOT NULL DEFAULT 0,
+    created_at 			    TIMESTAMPTZ,
+    updated_at 			    TIMESTAMPTZ,
+    PRIMARY KEY (account_uuid, summary_date)
+);
+
+-- backfill from the transactions inserted by the previous migrations, days are in UTC
+INSERT
+	INTO
+	bank_transaction_daily_summaries (account_uuid,
+	summary_date,
+	sum_in,
+	sum_out,
+	transaction_count,
+	created_at,
+	updated_at)
+SELECT
+	account_uuid,
+	(transaction_timestamp AT TIME ZONE 'UTC')::DATE,
+	COALESCE(SUM(amount) FILTER (WHERE transaction_type = 'IN'), 0),
+	COALESCE(SUM(amount) FILTER (WHERE transaction_type = 'OUT'), 0),
+	COUNT(*),
+	now(),
+	now()
+FROM
+	bank_transactions
+GROUP BY
+	1,
+	2
+ON CONFLICT DO NOTHING;
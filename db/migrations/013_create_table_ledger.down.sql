@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS ledger_postings CASCADE;
+
+DROP TABLE IF EXISTS ledger_journal_entries CASCADE;
+
+DROP FUNCTION IF EXISTS ledger_reject_modification();
+
+DELETE FROM bank_transaction_daily_summaries
+WHERE account_uuid IN (SELECT account_uuid FROM bank_accounts WHERE account_type = 'SYSTEM');
+
+DELETE FROM bank_accounts WHERE account_type = 'SYSTEM';
+
+ALTER TABLE bank_accounts DROP COLUMN IF EXISTS account_type;
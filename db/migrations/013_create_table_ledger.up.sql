@@ -0,0 +1,118 @@
+ALTER TABLE bank_accounts
+    ADD COLUMN IF NOT EXISTS account_type VARCHAR(20) NOT NULL DEFAULT 'CUSTOMER';
+
+CREATE TABLE IF NOT EXISTS ledger_journal_entries(
+    journal_uuid            UUID            PRIMARY KEY,
+    entry_type              VARCHAR(30)     NOT NULL,
+    description             TEXT,
+    created_at 			    TIMESTAMPTZ     NOT NULL
+);
+
+-- amount is the signed change of the account balance, the postings of a journal entry sum to zero per currency
+CREATE TABLE IF NOT EXISTS ledger_postings(
+    posting_uuid            UUID            PRIMARY KEY,
+    journal_uuid            UUID            NOT NULL REFERENCES ledger_journal_entries,
+    account_uuid            UUID            NOT NULL REFERENCES bank_accounts,
+    transaction_uuid        UUID            REFERENCES bank_transactions,
+    currency                VARCHAR(5)      NOT NULL,
+    amount                  NUMERIC(15,2)   NOT NULL,
+    created_at 			    TIMESTAMPTZ     NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_journal ON ledger_postings (journal_uuid);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account ON ledger_postings (account_uuid, created_at);
+
+CREATE OR REPLACE FUNCTION ledger_reject_modification() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION '% is append only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_journal_entries_immutable
+    BEFORE UPDATE OR DELETE ON ledger_journal_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_modification();
+
+CREATE TRIGGER ledger_postings_immutable
+    BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_modification();
+
+-- system accounts (cash, fees, fx) for every currency already in use
+INSERT
+	INTO
+	bank_accounts (account_uuid,
+	account_number,
+	account_name,
+	currency,
+	current_balance,
+	account_type,
+	created_at,
+	updated_at)
+SELECT
+	gen_random_uuid(),
+	'SYS-' || kind || '-' || currency,
+	'System ' || lower(kind) || ' ' || currency,
+	currency,
+	0,
+	'SYSTEM',
+	now(),
+	now()
+FROM
+	(SELECT DISTINCT currency FROM bank_accounts) currencies
+CROSS JOIN (VALUES ('CASH'), ('FEES'), ('FX')) kinds(kind)
+ON CONFLICT DO NOTHING;
+
+-- one journal entry per existing transaction, balanced against the system cash account
+INSERT
+	INTO
+	ledger_journal_entries (journal_uuid,
+	entry_type,
+	description,
+	created_at)
+SELECT
+	t.transaction_uuid,
+	CASE WHEN t.transaction_type = 'IN' THEN 'DEPOSIT' ELSE 'WITHDRAWAL' END,
+	t.notes,
+	t.transaction_timestamp
+FROM
+	bank_transactions t;
+
+INSERT
+	INTO
+	ledger_postings (posting_uuid,
+	journal_uuid,
+	account_uuid,
+	transaction_uuid,
+	currency,
+	amount,
+	created_at)
+SELECT
+	gen_random_uuid(),
+	t.transaction_uuid,
+	t.account_uuid,
+	t.transaction_uuid,
+	a.currency,
+	CASE WHEN t.transaction_type = 'IN' THEN t.amount ELSE -t.amount END,
+	t.transaction_timestamp
+FROM
+	bank_transactions t
+JOIN bank_accounts a ON a.account_uuid = t.account_uuid
+UNION ALL
+SELECT
+	gen_random_uuid(),
+	t.transaction_uuid,
+	c.account_uuid,
+	NULL,
+	a.currency,
+	CASE WHEN t.transaction_type = 'IN' THEN -t.amount ELSE t.amount END,
+	t.transaction_timestamp
+FROM
+	bank_transactions t
+JOIN bank_accounts a ON a.account_uuid = t.account_uuid
+JOIN bank_accounts c ON c.account_number = 'SYS-CASH-' || a.currency;
+
+UPDATE bank_accounts c
+SET current_balance = (
+	SELECT COALESCE(SUM(p.amount), 0) FROM ledger_postings p WHERE p.account_uuid = c.account_uuid
+)
+WHERE c.account_type = 'SYSTEM';
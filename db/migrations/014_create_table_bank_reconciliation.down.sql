@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS bank_reconciliation_items CASCADE;
+
+DROP TABLE IF EXISTS bank_reconciliation_runs CASCADE;
+
+DROP TABLE IF EXISTS bank_balance_baselines CASCADE;
@@ -0,0 +1,29 @@
+-- the expected balance of an account is baseline_balance + the transactions after baseline_timestamp,
+-- accounts without a baseline start from zero
+CREATE TABLE IF NOT EXISTS bank_balance_baselines(
+    account_uuid            UUID            PRIMARY KEY REFERENCES bank_accounts,
+    baseline_balance        NUMERIC(15,2)   NOT NULL,
+    baseline_timestamp      TIMESTAMPTZ     NOT NULL,
+    created_at 			    TIMESTAMPTZ,
+    updated_at 			    TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS bank_reconciliation_runs(
+    run_uuid                UUID            PRIMARY KEY,
+    started_at              TIMESTAMPTZ     NOT NULL,
+    finished_at             TIMESTAMPTZ     NOT NULL,
+    accounts_checked        INTEGER         NOT NULL,
+    mismatches              INTEGER         NOT NULL,
+    repair                  BOOLEAN         NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS bank_reconciliation_items(
+    item_uuid               UUID            PRIMARY KEY,
+    run_uuid                UUID            NOT NULL REFERENCES bank_reconciliation_runs,
+    account_uuid            UUID            NOT NULL REFERENCES bank_accounts,
+    stored_balance          NUMERIC(15,2)   NOT NULL,
+    expected_balance        NUMERIC(15,2)   NOT NULL,
+    ledger_balance          NUMERIC(15,2)   NOT NULL,
+    repaired                BOOLEAN         NOT NULL DEFAULT FALSE,
+    created_at 			    TIMESTAMPTZ
+);
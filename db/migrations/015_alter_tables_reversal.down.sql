@@ -0,0 +1,14 @@
+ALTER TABLE bank_transfers
+    DROP COLUMN IF EXISTS reversed_at,
+    DROP COLUMN IF EXISTS reversal_reason,
+    DROP COLUMN IF EXISTS reversed;
+
+DROP INDEX IF EXISTS idx_ledger_journal_entries_reference;
+
+ALTER TABLE ledger_journal_entries
+    DROP COLUMN IF EXISTS reference_uuid;
+
+DROP INDEX IF EXISTS idx_bank_transactions_reversal_of;
+
+ALTER TABLE bank_transactions
+    DROP COLUMN IF EXISTS reversal_of;
@@ -0,0 +1,18 @@
+ALTER TABLE bank_transactions
+    ADD COLUMN IF NOT EXISTS reversal_of UUID REFERENCES bank_transactions;
+
+-- a transaction can be reversed only once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bank_transactions_reversal_of
+    ON bank_transactions (reversal_of) WHERE reversal_of IS NOT NULL;
+
+-- transfer uuid for transfer entries, reversed journal uuid for reversal entries
+ALTER TABLE ledger_journal_entries
+    ADD COLUMN IF NOT EXISTS reference_uuid UUID;
+
+CREATE INDEX IF NOT EXISTS idx_ledger_journal_entries_reference
+    ON ledger_journal_entries (reference_uuid);
+
+ALTER TABLE bank_transfers
+    ADD COLUMN IF NOT EXISTS reversed            BOOLEAN     NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS reversal_reason     TEXT,
+    ADD COLUMN IF NOT EXISTS reversed_at         TIMESTAMPTZ;
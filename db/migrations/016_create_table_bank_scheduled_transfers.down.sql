@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS bank_scheduled_transfer_executions CASCADE;
+
+DROP TABLE IF EXISTS bank_scheduled_transfers CASCADE;
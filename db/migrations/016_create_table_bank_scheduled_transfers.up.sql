@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS bank_scheduled_transfers(
+    schedule_uuid           UUID            PRIMARY KEY,
+    from_account_number     VARCHAR(20)     NOT NULL REFERENCES bank_accounts (account_number),
+    to_account_number       VARCHAR(20)     NOT NULL REFERENCES bank_accounts (account_number),
+    currency                VARCHAR(5)      NOT NULL,
+    amount                  NUMERIC(15,2)   NOT NULL,
+    recurrence              VARCHAR(10)     NOT NULL,
+    start_at                TIMESTAMPTZ     NOT NULL,
+    end_at                  TIMESTAMPTZ,
+    -- occurrence_count occurrences are done, next_run_at is the next one and due_at is when to try it (later than
+    -- next_run_at while retrying)
+    occurrence_count        INTEGER         NOT NULL DEFAULT 0,
+    next_run_at             TIMESTAMPTZ     NOT NULL,
+    due_at                  TIMESTAMPTZ     NOT NULL,
+    attempt                 INTEGER         NOT NULL DEFAULT 0,
+    locked_until            TIMESTAMPTZ,
+    status                  VARCHAR(20)     NOT NULL,
+    created_at 			    TIMESTAMPTZ,
+    updated_at 			    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_scheduled_transfers_due
+    ON bank_scheduled_transfers (due_at) WHERE status = 'ACTIVE';
+
+CREATE TABLE IF NOT EXISTS bank_scheduled_transfer_executions(
+    execution_uuid          UUID            PRIMARY KEY,
+    schedule_uuid           UUID            NOT NULL REFERENCES bank_scheduled_transfers,
+    scheduled_for           TIMESTAMPTZ     NOT NULL,
+    executed_at             TIMESTAMPTZ     NOT NULL,
+    attempt                 INTEGER         NOT NULL,
+    outcome                 VARCHAR(20)     NOT NULL,
+    transfer_uuid           UUID            REFERENCES bank_transfers,
+    reason                  TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_scheduled_transfer_executions_schedule
+    ON bank_scheduled_transfer_executions (schedule_uuid, executed_at);
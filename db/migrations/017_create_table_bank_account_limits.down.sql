@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_bank_transfers_from_account_created;
+
+ALTER TABLE bank_accounts
+    DROP CONSTRAINT IF EXISTS fk_bank_accounts_account_tier,
+    DROP COLUMN IF EXISTS account_tier;
+
+DROP TABLE IF EXISTS bank_account_limits CASCADE;
+
+DROP TABLE IF EXISTS bank_limit_tiers CASCADE;
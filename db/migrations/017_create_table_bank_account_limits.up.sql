@@ -0,0 +1,41 @@
+ALTER TABLE bank_accounts
+    ADD COLUMN IF NOT EXISTS account_tier VARCHAR(20) NOT NULL DEFAULT 'STANDARD';
+
+-- a NULL limit means no limit, amounts are in the account currency
+CREATE TABLE IF NOT EXISTS bank_limit_tiers(
+    account_tier            VARCHAR(20)     PRIMARY KEY,
+    max_single_out          NUMERIC(15,2),
+    daily_out_limit         NUMERIC(15,2),
+    monthly_out_limit       NUMERIC(15,2),
+    max_transfers_per_hour  INTEGER,
+    minimum_balance         NUMERIC(15,2)   NOT NULL DEFAULT 0,
+    created_at 			    TIMESTAMPTZ,
+    updated_at 			    TIMESTAMPTZ
+);
+
+-- per account overrides, a NULL column falls back to the tier
+CREATE TABLE IF NOT EXISTS bank_account_limits(
+    account_uuid            UUID            PRIMARY KEY REFERENCES bank_accounts,
+    max_single_out          NUMERIC(15,2),
+    daily_out_limit         NUMERIC(15,2),
+    monthly_out_limit       NUMERIC(15,2),
+    max_transfers_per_hour  INTEGER,
+    minimum_balance         NUMERIC(15,2),
+    created_at 			    TIMESTAMPTZ,
+    updated_at 			    TIMESTAMPTZ
+);
+
+INSERT INTO bank_limit_tiers
+    (account_tier, max_single_out, daily_out_limit, monthly_out_limit, max_transfers_per_hour, minimum_balance,
+    created_at, updated_at)
+VALUES
+    ('STANDARD', NULL, NULL, NULL, NULL, 0, current_timestamp, current_timestamp),
+    ('BASIC', 1000, 2500, 20000, 10, 0, current_timestamp, current_timestamp),
+    ('PREMIUM', 50000, 100000, 1000000, 100, 0, current_timestamp, current_timestamp)
+ON CONFLICT (account_tier) DO NOTHING;
+
+ALTER TABLE bank_accounts
+    ADD CONSTRAINT fk_bank_accounts_account_tier FOREIGN KEY (account_tier) REFERENCES bank_limit_tiers;
+
+CREATE INDEX IF NOT EXISTS idx_bank_transfers_from_account_created
+    ON bank_transfers (from_account_uuid, created_at);
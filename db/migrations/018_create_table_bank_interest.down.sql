@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS bank_interest_accruals CASCADE;
+
+DROP TABLE IF EXISTS bank_interest_rates CASCADE;
+
+ALTER TABLE bank_accounts
+    DROP COLUMN IF EXISTS overdraft_limit;
@@ -0,0 +1,38 @@
+ALTER TABLE bank_accounts
+    ADD COLUMN IF NOT EXISTS overdraft_limit NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0);
+
+-- DEBIT rates are charged on negative balances, CREDIT rates are paid on positive balances. The row with the
+-- highest min_balance not above the absolute balance applies, a NULL currency applies to every currency
+CREATE TABLE IF NOT EXISTS bank_interest_rates(
+    rate_uuid               UUID            PRIMARY KEY,
+    currency                VARCHAR(5),
+    rate_type               VARCHAR(10)     NOT NULL,
+    min_balance             NUMERIC(15,2)   NOT NULL DEFAULT 0,
+    annual_rate             NUMERIC(9,6)    NOT NULL,
+    valid_from              DATE            NOT NULL,
+    valid_to                DATE,
+    created_at 			    TIMESTAMPTZ,
+    updated_at 			    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_interest_rates_lookup
+    ON bank_interest_rates (rate_type, currency, valid_from);
+
+-- one accrual per account and day, so running the job twice for the same day doesn't charge twice
+CREATE TABLE IF NOT EXISTS bank_interest_accruals(
+    account_uuid            UUID            NOT NULL REFERENCES bank_accounts,
+    accrual_date            DATE            NOT NULL,
+    rate_type               VARCHAR(10)     NOT NULL,
+    balance                 NUMERIC(15,2)   NOT NULL,
+    annual_rate             NUMERIC(9,6)    NOT NULL,
+    amount                  NUMERIC(15,2)   NOT NULL,
+    transaction_uuid        UUID            REFERENCES bank_transactions,
+    created_at 			    TIMESTAMPTZ,
+    PRIMARY KEY (account_uuid, accrual_date)
+);
+
+INSERT INTO bank_interest_rates (rate_uuid, currency, rate_type, min_balance, annual_rate, valid_from, created_at, updated_at)
+VALUES
+    (gen_random_uuid(), NULL, 'DEBIT', 0, 0.18, '2020-01-01', current_timestamp, current_timestamp),
+    (gen_random_uuid(), NULL, 'CREDIT', 0, 0.005, '2020-01-01', current_timestamp, current_timestamp),
+    (gen_random_uuid(), NULL, 'CREDIT', 10000, 0.015, '2020-01-01', current_timestamp, current_timestamp);
@@ -0,0 +1,5 @@
+ALTER TABLE bank_transfers
+    DROP COLUMN IF EXISTS fee_currency,
+    DROP COLUMN IF EXISTS fee_amount;
+
+DROP TABLE IF EXISTS bank_fee_rules CASCADE;
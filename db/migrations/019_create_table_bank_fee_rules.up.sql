@@ -0,0 +1,33 @@
+-- fee_type is FLAT, PERCENTAGE, TIERED or CROSS_CURRENCY. Amounts are in the source account currency, rules for
+-- the currency replace the rules without currency (NULL). For TIERED rules only the tier with the highest
+-- min_amount not above the amount applies
+CREATE TABLE IF NOT EXISTS bank_fee_rules(
+    rule_uuid               UUID            PRIMARY KEY,
+    currency                VARCHAR(5),
+    fee_type                VARCHAR(20)     NOT NULL,
+    min_amount              NUMERIC(15,2)   NOT NULL DEFAULT 0,
+    flat_amount             NUMERIC(15,2)   NOT NULL DEFAULT 0,
+    percentage              NUMERIC(9,6)    NOT NULL DEFAULT 0,
+    min_fee                 NUMERIC(15,2),
+    max_fee                 NUMERIC(15,2),
+    valid_from              DATE            NOT NULL,
+    valid_to                DATE,
+    created_at 			    TIMESTAMPTZ,
+    updated_at 			    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_fee_rules_lookup
+    ON bank_fee_rules (currency, valid_from);
+
+ALTER TABLE bank_transfers
+    ADD COLUMN IF NOT EXISTS fee_amount     NUMERIC(15,2)   NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS fee_currency   VARCHAR(5);
+
+INSERT INTO bank_fee_rules
+    (rule_uuid, currency, fee_type, min_amount, flat_amount, percentage, min_fee, max_fee, valid_from, created_at,
+    updated_at)
+VALUES
+    (gen_random_uuid(), NULL, 'TIERED', 0, 0.50, 0, NULL, NULL, '2020-01-01', current_timestamp, current_timestamp),
+    (gen_random_uuid(), NULL, 'TIERED', 1000, 0, 0.001, 1, 25, '2020-01-01', current_timestamp, current_timestamp),
+    (gen_random_uuid(), NULL, 'CROSS_CURRENCY', 0, 0, 0.01, NULL, NULL, '2020-01-01', current_timestamp,
+    current_timestamp);
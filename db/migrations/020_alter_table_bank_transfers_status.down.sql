@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_bank_transfers_in_progress;
+
+ALTER TABLE bank_transfers
+    DROP COLUMN IF EXISTS failed_at,
+    DROP COLUMN IF EXISTS settled_at,
+    DROP COLUMN IF EXISTS authorized_at,
+    DROP COLUMN IF EXISTS failure_reason,
+    DROP COLUMN IF EXISTS transfer_status;
@@ -0,0 +1,27 @@
+-- PENDING -> AUTHORIZED -> SETTLED -> REVERSED, PENDING and AUTHORIZED can also end in FAILED.
+-- transfer_success is kept for existing readers and is true once the transfer is settled
+ALTER TABLE bank_transfers
+    ADD COLUMN IF NOT EXISTS transfer_status    VARCHAR(20)     NOT NULL DEFAULT 'PENDING',
+    ADD COLUMN IF NOT EXISTS failure_reason     TEXT,
+    ADD COLUMN IF NOT EXISTS authorized_at      TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS settled_at         TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS failed_at          TIMESTAMPTZ;
+
+-- a transfer with a journal entry was posted, whatever transfer_success says
+UPDATE bank_transfers t
+SET transfer_status = CASE WHEN t.reversed THEN 'REVERSED' WHEN p.posted THEN 'SETTLED' ELSE 'FAILED' END,
+    transfer_success = p.posted,
+    settled_at = CASE WHEN p.posted THEN t.updated_at END,
+    failed_at = CASE WHEN NOT p.posted THEN t.updated_at END,
+    failure_reason = CASE WHEN NOT p.posted THEN 'failed before transfer states were recorded' END
+FROM (
+    SELECT tr.transfer_uuid,
+        tr.transfer_success OR tr.reversed OR EXISTS (
+            SELECT 1 FROM ledger_journal_entries j WHERE j.reference_uuid = tr.transfer_uuid
+        ) AS posted
+    FROM bank_transfers tr
+) p
+WHERE p.transfer_uuid = t.transfer_uuid;
+
+CREATE INDEX IF NOT EXISTS idx_bank_transfers_in_progress
+    ON bank_transfers (created_at) WHERE transfer_status IN ('PENDING', 'AUTHORIZED');
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bank_transfers_to_account_timestamp;
+
+DROP INDEX IF EXISTS idx_bank_transfers_from_account_timestamp;
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_bank_transfers_from_account_timestamp
+    ON bank_transfers (from_account_uuid, transfer_timestamp, transfer_uuid);
+
+CREATE INDEX IF NOT EXISTS idx_bank_transfers_to_account_timestamp
+    ON bank_transfers (to_account_uuid, transfer_timestamp, transfer_uuid);
@@ -0,0 +1,18 @@
+-- events are written in the same database transaction as the change they describe and published by the relay in
+-- event_id order, published_at is set once the sink accepted the event
+CREATE TABLE IF NOT EXISTS outbox_events(
+    event_id                BIGSERIAL       PRIMARY KEY,
+    event_uuid              UUID            NOT NULL UNIQUE,
+    event_type              VARCHAR(50)     NOT NULL,
+    event_version           INTEGER         NOT NULL,
+    aggregate_type          VARCHAR(30)     NOT NULL,
+    aggregate_id            VARCHAR(64)     NOT NULL,
+    payload                 JSONB           NOT NULL,
+    created_at              TIMESTAMPTZ     NOT NULL,
+    published_at            TIMESTAMPTZ,
+    attempts                INTEGER         NOT NULL DEFAULT 0,
+    last_error              TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished
+    ON outbox_events (event_id) WHERE published_at IS NULL;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS webhook_dead_letters CASCADE;
+
+DROP TABLE IF EXISTS webhook_delivery_attempts CASCADE;
+
+DROP TABLE IF EXISTS webhook_deliveries CASCADE;
+
+DROP TABLE IF EXISTS webhook_subscriptions CASCADE;
@@ -0,0 +1,65 @@
+-- a subscription without account_number receives the events of every account (partner systems), event_types is a
+-- comma separated list of webhook event types
+CREATE TABLE IF NOT EXISTS webhook_subscriptions(
+    subscription_uuid       UUID            PRIMARY KEY,
+    account_number          VARCHAR(20)     REFERENCES bank_accounts (account_number),
+    url                     TEXT            NOT NULL,
+    event_types             TEXT            NOT NULL,
+    secret                  VARCHAR(100)    NOT NULL,
+    large_withdrawal_amount NUMERIC(15,2),
+    low_balance_amount      NUMERIC(15,2),
+    status                  VARCHAR(20)     NOT NULL,
+    created_at 			    TIMESTAMPTZ,
+    updated_at 			    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_account
+    ON webhook_subscriptions (account_number) WHERE status = 'ACTIVE';
+
+-- one delivery per subscription and webhook event, the unique key makes a re-published outbox event a no-op
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+    delivery_uuid           UUID            PRIMARY KEY,
+    subscription_uuid       UUID            NOT NULL REFERENCES webhook_subscriptions,
+    event_uuid              UUID            NOT NULL,
+    event_type              VARCHAR(50)     NOT NULL,
+    payload                 JSONB           NOT NULL,
+    status                  VARCHAR(20)     NOT NULL,
+    attempts                INTEGER         NOT NULL DEFAULT 0,
+    next_attempt_at         TIMESTAMPTZ     NOT NULL,
+    locked_until            TIMESTAMPTZ,
+    last_status_code        INTEGER,
+    last_error              TEXT,
+    created_at 			    TIMESTAMPTZ,
+    updated_at 			    TIMESTAMPTZ,
+    delivered_at            TIMESTAMPTZ,
+    UNIQUE (subscription_uuid, event_uuid, event_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries (next_attempt_at) WHERE status = 'PENDING';
+
+-- the delivery log, one row per HTTP attempt
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts(
+    attempt_uuid            UUID            PRIMARY KEY,
+    delivery_uuid           UUID            NOT NULL REFERENCES webhook_deliveries,
+    attempt                 INTEGER         NOT NULL,
+    attempted_at            TIMESTAMPTZ     NOT NULL,
+    status_code             INTEGER,
+    error                   TEXT,
+    duration_ms             INTEGER         NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery
+    ON webhook_delivery_attempts (delivery_uuid, attempt);
+
+-- deliveries that ran out of attempts, redelivered_at is set when one is put back in the queue
+CREATE TABLE IF NOT EXISTS webhook_dead_letters(
+    delivery_uuid           UUID            PRIMARY KEY REFERENCES webhook_deliveries,
+    subscription_uuid       UUID            NOT NULL REFERENCES webhook_subscriptions,
+    event_type              VARCHAR(50)     NOT NULL,
+    payload                 JSONB           NOT NULL,
+    attempts                INTEGER         NOT NULL,
+    last_error              TEXT,
+    dead_at                 TIMESTAMPTZ     NOT NULL,
+    redelivered_at          TIMESTAMPTZ
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_outbox_events_aggregate_feed;
+
+DROP INDEX IF EXISTS idx_outbox_events_feed;
+
+ALTER TABLE outbox_events
+    DROP COLUMN IF EXISTS transaction_id;
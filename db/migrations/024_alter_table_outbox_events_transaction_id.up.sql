@@ -0,0 +1,10 @@
+-- the activity feed reads events in the order their transactions become visible, transaction_id is the id of the
+-- database transaction that wrote the event
+ALTER TABLE outbox_events
+    ADD COLUMN IF NOT EXISTS transaction_id XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_feed
+    ON outbox_events (transaction_id, event_id);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate_feed
+    ON outbox_events (aggregate_type, aggregate_id, transaction_id, event_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS audit_log CASCADE;
+
+DROP FUNCTION IF EXISTS audit_log_append_only();
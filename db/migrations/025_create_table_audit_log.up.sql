@@ -0,0 +1,43 @@
+-- append-only audit log, sequence is gapless and hash chains every record to the previous one (prev_hash) so a
+-- changed or removed record can be detected. before_value and after_value are kept as text, the hash covers their
+-- exact bytes
+CREATE TABLE IF NOT EXISTS audit_log(
+    sequence                BIGINT          PRIMARY KEY,
+    audit_uuid              UUID            NOT NULL UNIQUE,
+    actor                   VARCHAR(100)    NOT NULL,
+    method                  VARCHAR(200)    NOT NULL,
+    request_id              VARCHAR(100)    NOT NULL,
+    action                  VARCHAR(50)     NOT NULL,
+    entity_type             VARCHAR(30)     NOT NULL,
+    entity_ids              TEXT            NOT NULL,
+    before_value            TEXT            NOT NULL,
+    after_value             TEXT            NOT NULL,
+    outcome                 VARCHAR(20)     NOT NULL,
+    error                   TEXT            NOT NULL,
+    created_at              TIMESTAMPTZ     NOT NULL,
+    prev_hash               VARCHAR(64)     NOT NULL,
+    hash                    VARCHAR(64)     NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor
+    ON audit_log (actor, sequence);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_request_id
+    ON audit_log (request_id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at
+    ON audit_log (created_at);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
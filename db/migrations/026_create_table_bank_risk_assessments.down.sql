@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bank_transfers_held;
+
+DROP TABLE IF EXISTS bank_risk_assessments CASCADE;
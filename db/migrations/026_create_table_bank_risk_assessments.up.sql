@@ -0,0 +1,29 @@
+-- assessments that weren't ALLOW, reference_uuid is the held transfer or the flagged transaction (NULL when denied,
+-- nothing was written). signals is the list of rules that fired with their score
+CREATE TABLE IF NOT EXISTS bank_risk_assessments(
+    assessment_uuid         UUID            PRIMARY KEY,
+    operation               VARCHAR(20)     NOT NULL,
+    account_number          VARCHAR(20)     NOT NULL,
+    counterparty_number     VARCHAR(20),
+    currency                VARCHAR(5)      NOT NULL,
+    amount                  NUMERIC(15,2)   NOT NULL,
+    decision                VARCHAR(20)     NOT NULL,
+    score                   INTEGER         NOT NULL,
+    signals                 JSONB           NOT NULL,
+    reference_uuid          UUID,
+    reviewed_by             VARCHAR(100),
+    review_decision         VARCHAR(20),
+    review_note             TEXT,
+    reviewed_at             TIMESTAMPTZ,
+    created_at 			    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_risk_assessments_reference
+    ON bank_risk_assessments (reference_uuid);
+
+CREATE INDEX IF NOT EXISTS idx_bank_risk_assessments_account
+    ON bank_risk_assessments (account_number, created_at);
+
+-- transfers held for review wait for an approval, they are not in progress for the recovery job
+CREATE INDEX IF NOT EXISTS idx_bank_transfers_held
+    ON bank_transfers (created_at) WHERE transfer_status = 'HELD';
@@ -0,0 +1,23 @@
+-- every match of a party against the screening list, reference_uuid is the account or transfer that went ahead
+-- with a FLAG hit (NULL when the operation was blocked, nothing was written)
+CREATE TABLE IF NOT EXISTS bank_screening_hits(
+    hit_uuid                UUID            PRIMARY KEY,
+    operation               VARCHAR(30)     NOT NULL,
+    party_role              VARCHAR(20)     NOT NULL,
+    account_number          VARCHAR(20),
+    party_name              VARCHAR(100)    NOT NULL,
+    listed_name             TEXT            NOT NULL,
+    listed_account_number   VARCHAR(20)     NOT NULL,
+    list_reason             TEXT            NOT NULL,
+    match_type              VARCHAR(20)     NOT NULL,
+    score                   NUMERIC(5,4)    NOT NULL,
+    action                  VARCHAR(20)     NOT NULL,
+    reference_uuid          UUID,
+    created_at 			    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_screening_hits_account
+    ON bank_screening_hits (account_number, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_bank_screening_hits_reference
+    ON bank_screening_hits (reference_uuid);
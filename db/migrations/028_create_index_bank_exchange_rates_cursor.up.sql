@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_bank_exchange_rates_pair_cursor
+    ON bank_exchange_rates (from_currency, to_currency, valid_from_timestamp, exchange_rate_uuid);
@@ -0,0 +1,5 @@
+ALTER TABLE bank_scheduled_transfers
+    DROP COLUMN IF EXISTS requested_by;
+
+ALTER TABLE bank_transfers
+    DROP COLUMN IF EXISTS requested_by;
@@ -0,0 +1,7 @@
+-- the actor that asked for the transfer (or scheduled it), a held transfer can't be reviewed by them.
+-- NULL for the transfers created before it was recorded
+ALTER TABLE bank_transfers
+    ADD COLUMN IF NOT EXISTS requested_by       VARCHAR(255);
+
+ALTER TABLE bank_scheduled_transfers
+    ADD COLUMN IF NOT EXISTS requested_by       VARCHAR(255);
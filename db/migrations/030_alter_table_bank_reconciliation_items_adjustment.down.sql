@@ -0,0 +1,2 @@
+ALTER TABLE bank_reconciliation_items
+    DROP COLUMN IF EXISTS adjustment_journal_uuid;
@@ -0,0 +1,3 @@
+-- a repaired mismatch refers to the journal entry that adjusted the balance
+ALTER TABLE bank_reconciliation_items
+    ADD COLUMN IF NOT EXISTS adjustment_journal_uuid    UUID    REFERENCES ledger_journal_entries;
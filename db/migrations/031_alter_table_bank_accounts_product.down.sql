@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS bank_interest_runs CASCADE;
+
+ALTER TABLE bank_accounts
+    DROP COLUMN IF EXISTS account_product;
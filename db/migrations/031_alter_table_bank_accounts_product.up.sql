@@ -0,0 +1,9 @@
+-- only SAVINGS accounts are paid CREDIT interest, the existing accounts are CURRENT accounts
+ALTER TABLE bank_accounts
+    ADD COLUMN IF NOT EXISTS account_product VARCHAR(20) NOT NULL DEFAULT 'CURRENT';
+
+-- the days accrued for every account, the job catches up from the last one
+CREATE TABLE IF NOT EXISTS bank_interest_runs(
+    accrual_date            DATE            PRIMARY KEY,
+    completed_at            TIMESTAMPTZ     NOT NULL
+);
@@ -0,0 +1 @@
+DELETE FROM bank_fee_rules WHERE currency IN ('JPY', 'KRW', 'VND');
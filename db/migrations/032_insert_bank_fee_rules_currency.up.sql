@@ -0,0 +1,22 @@
+-- the generic rules are in units of a 2 decimal currency, a 0.50 flat fee rounds to nothing in JPY, KRW or VND.
+-- These currencies get their own rules, which replace the generic ones
+INSERT INTO bank_fee_rules
+    (rule_uuid, currency, fee_type, min_amount, flat_amount, percentage, min_fee, max_fee, valid_from, created_at,
+    updated_at)
+VALUES
+    (gen_random_uuid(), 'JPY', 'TIERED', 0, 80, 0, NULL, NULL, '2020-01-01', current_timestamp, current_timestamp),
+    (gen_random_uuid(), 'JPY', 'TIERED', 150000, 0, 0.001, 150, 3800, '2020-01-01', current_timestamp,
+    current_timestamp),
+    (gen_random_uuid(), 'JPY', 'CROSS_CURRENCY', 0, 0, 0.01, NULL, NULL, '2020-01-01', current_timestamp,
+    current_timestamp),
+    (gen_random_uuid(), 'KRW', 'TIERED', 0, 700, 0, NULL, NULL, '2020-01-01', current_timestamp, current_timestamp),
+    (gen_random_uuid(), 'KRW', 'TIERED', 1400000, 0, 0.001, 1400, 35000, '2020-01-01', current_timestamp,
+    current_timestamp),
+    (gen_random_uuid(), 'KRW', 'CROSS_CURRENCY', 0, 0, 0.01, NULL, NULL, '2020-01-01', current_timestamp,
+    current_timestamp),
+    (gen_random_uuid(), 'VND', 'TIERED', 0, 13000, 0, NULL, NULL, '2020-01-01', current_timestamp,
+    current_timestamp),
+    (gen_random_uuid(), 'VND', 'TIERED', 25000000, 0, 0.001, 25000, 630000, '2020-01-01', current_timestamp,
+    current_timestamp),
+    (gen_random_uuid(), 'VND', 'CROSS_CURRENCY', 0, 0, 0.01, NULL, NULL, '2020-01-01', current_timestamp,
+    current_timestamp);
@@ -0,0 +1,4 @@
+-- the relay claims a batch until claimed_until and publishes it outside the database transaction, a batch whose
+-- relay died is claimed again once the claim expires
+ALTER TABLE outbox_events
+    ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
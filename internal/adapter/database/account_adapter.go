@@ -0,0 +1,166 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (d *DatabaseAdapter) NextAccountNumberSequence() (int64, error) {
+	var seq int64
+
+	if err := d.db.Raw("SELECT nextval('bank_account_number_seq')").Scan(&seq).Error; err != nil {
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+// CreateBankAccount inserts the account and, if given, posts the initial deposit in one database transaction
+func (d *DatabaseAdapter) CreateBankAccount(acct BankAccountOrm, initialDeposit *LedgerEntry,
+	events ...OutboxEventOrm) (uuid.UUID, error) {
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Transactions").Create(&acct).Error; err != nil {
+			return err
+		}
+
+		if err := insertOutboxEvents(tx, events); err != nil {
+			return err
+		}
+
+		if initialDeposit != nil {
+			return postLedgerEntry(tx, *initialDeposit)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return acct.AccountUuid, nil
+}
+
+// GetLegacyAccountNumbers returns the account numbers issued before the check digit scheme
+func (d *DatabaseAdapter) GetLegacyAccountNumbers() ([]string, error) {
+	var accountNumbers []string
+
+	if err := d.db.Model(&BankAccountOrm{}).
+		Where("legacy_account_number").
+		Pluck("account_number", &accountNumbers).Error; err != nil {
+		return nil, err
+	}
+
+	return accountNumbers, nil
+}
+
+func (d *DatabaseAdapter) GetBankAccountByUuid(accountUuid uuid.UUID) (BankAccountOrm, error) {
+	var bankAccountOrm BankAccountOrm
+
+	if err := d.db.First(&bankAccountOrm, "account_uuid = ?", accountUuid).Error; err != nil {
+		return bankAccountOrm, err
+	}
+
+	return bankAccountOrm, nil
+}
+
+func (d *DatabaseAdapter) UpdateBankAccountName(acct BankAccountOrm, name string, events ...OutboxEventOrm) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&acct).Updates(
+			map[string]interface{}{
+				"account_name": name,
+				"updated_at":   time.Now(),
+			},
+		).Error; err != nil {
+			return err
+		}
+
+		return insertOutboxEvents(tx, events)
+	})
+}
+
+func (d *DatabaseAdapter) UpdateBankAccountStatus(acct BankAccountOrm, status string, events ...OutboxEventOrm) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&acct).Updates(
+			map[string]interface{}{
+				"account_status": status,
+				"updated_at":     time.Now(),
+			},
+		).Error; err != nil {
+			return err
+		}
+
+		return insertOutboxEvents(tx, events)
+	})
+}
+
+// UpdateBankAccountOverdraftLimit only lowers the limit if the balance stays within it
+func (d *DatabaseAdapter) UpdateBankAccountOverdraftLimit(acct BankAccountOrm, limit float64,
+	events ...OutboxEventOrm) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&acct).
+			Where("current_balance >= ?", -limit).
+			Updates(
+				map[string]interface{}{
+					"overdraft_limit": limit,
+					"updated_at":      time.Now(),
+				},
+			)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("account %v overdraft limit not changed : %w", acct.AccountNumber,
+				bank.ErrInvalidOverdraftLimit)
+		}
+
+		return insertOutboxEvents(tx, events)
+	})
+}
+
+// CloseBankAccount only closes the account if the balance is still zero at the time of the update, so a
+// concurrent deposit can't be lost on a closed account. The closed account is read back as stored
+func (d *DatabaseAdapter) CloseBankAccount(acct BankAccountOrm, events ...OutboxEventOrm) (BankAccountOrm, error) {
+	now := time.Now()
+
+	closedAt := now
+	if acct.ClosedAt != nil {
+		closedAt = *acct.ClosedAt
+	}
+
+	var closedOrm BankAccountOrm
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&acct).
+			Where("current_balance = 0 AND account_status <> ?", bank.AccountStatusClosed).
+			Updates(
+				map[string]interface{}{
+					"account_status": bank.AccountStatusClosed,
+					"closed_at":      closedAt,
+					"updated_at":     now,
+				},
+			)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("account %v not closed : %w", acct.AccountNumber, bank.ErrAccountBalanceNotZero)
+		}
+
+		if err := tx.First(&closedOrm, "account_uuid = ?", acct.AccountUuid).Error; err != nil {
+			return err
+		}
+
+		return insertOutboxEvents(tx, events)
+	})
+
+	return closedOrm, err
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"gorm.io/gorm"
+)
+
+// auditLogLockKey is the postgres advisory lock key that serializes appends to the audit log, every record needs
+// the hash of the one before it
+const auditLogLockKey = 7835004
+
+// AppendAuditRecord passes the sequence and hash of the last record to seal and appends the record it returns
+func (d *DatabaseAdapter) AppendAuditRecord(seal func(sequence int64, prevHash string) (AuditLogOrm, error)) (
+	AuditLogOrm, error) {
+	var recordOrm AuditLogOrm
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditLogLockKey).Error; err != nil {
+			return err
+		}
+
+		var last AuditLogOrm
+
+		err := tx.Order("sequence DESC").First(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if recordOrm, err = seal(last.Sequence+1, last.Hash); err != nil {
+			return err
+		}
+
+		return tx.Create(&recordOrm).Error
+	})
+
+	return recordOrm, err
+}
+
+// GetAuditRecords returns up to limit records matching the filter after the given sequence
+func (d *DatabaseAdapter) GetAuditRecords(filter bank.AuditFilter, afterSequence int64, limit int) (
+	[]AuditLogOrm, error) {
+	var recordOrms []AuditLogOrm
+
+	query := d.db.Where("sequence > ?", afterSequence)
+
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+
+	if filter.RequestId != "" {
+		query = query.Where("request_id = ?", filter.RequestId)
+	}
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	if filter.EntityId != "" {
+		query = query.Where("',' || entity_ids || ',' LIKE ?", "%,"+filter.EntityId+",%")
+	}
+
+	if !filter.StartTimestamp.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTimestamp)
+	}
+
+	if !filter.EndTimestamp.IsZero() {
+		query = query.Where("created_at <= ?", filter.EndTimestamp)
+	}
+
+	err := query.Order("sequence").Limit(limit).Find(&recordOrms).Error
+
+	return recordOrms, err
+}
@@ -1,12 +1,14 @@
 package database
 
 import (
-	"fmt"
+	"database/sql"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func (d *DatabaseAdapter) GetBankAccountByAccountNumber(acct string) (BankAccountOrm, error) {
@@ -20,8 +22,14 @@ func (d *DatabaseAdapter) GetBankAccountByAccountNumber(acct string) (BankAccoun
 	return bankAccountOrm, nil
 }
 
-func (d *DatabaseAdapter) CreateExchangeRate(r *BankExchangeRateOrm) (uuid.UUID, error) {
-	if err := d.db.Create(r).Error; err != nil {
+func (d *DatabaseAdapter) CreateExchangeRate(r *BankExchangeRateOrm, events ...OutboxEventOrm) (uuid.UUID, error) {
+	if err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(r).Error; err != nil {
+			return err
+		}
+
+		return insertOutboxEvents(tx, events)
+	}); err != nil {
 		return uuid.Nil, err
 	}
 
@@ -36,113 +44,138 @@ func (d *DatabaseAdapter) GetExchangeRateAtTimestamp(fromCur string, toCur strin
 	return exchangeRateOrm, err
 }
 
-func (d *DatabaseAdapter) CreateTransaction(acct BankAccountOrm, t BankTransactionOrm) (uuid.UUID, error) {
-	tx := d.db.Begin()
-
-	if err := tx.Create(t).Error; err != nil {
-		tx.Rollback()
+func (d *DatabaseAdapter) CreateTransfer(transfer BankTransferOrm) (uuid.UUID, error) {
+	if err := d.db.Create(transfer).Error; err != nil {
 		return uuid.Nil, err
 	}
 
-	// calculate new account balance
-	newAmount := t.Amount
+	return transfer.TransferUuid, nil
+}
 
-	if t.TransactionType == bank.TransactionTypeOUT {
-		newAmount = -1 * t.Amount
-	}
+// GetExchangeRatesInRange pages over (valid_from_timestamp, exchange_rate_uuid) so rates sharing a timestamp are not
+// skipped at a page boundary
+func (d *DatabaseAdapter) GetExchangeRatesInRange(fromCur string, toCur string, start time.Time, end time.Time,
+	cursor *bank.ExchangeRateCursor, limit int) ([]BankExchangeRateOrm, error) {
+	var exchangeRateOrms []BankExchangeRateOrm
 
-	newAccountBalance := acct.CurrentBalance + newAmount
+	query := d.db.Where("from_currency = ? AND to_currency = ?", fromCur, toCur).
+		Where("valid_from_timestamp >= ? AND valid_from_timestamp < ?", start, end)
 
-	if err := tx.Model(&acct).Updates(
-		map[string]interface{}{
-			"current_balance": newAccountBalance,
-			"updated_at":      time.Now(),
-		},
-	).Error; err != nil {
-		tx.Rollback()
-		return uuid.Nil, err
+	if cursor != nil {
+		query = query.Where("(valid_from_timestamp, exchange_rate_uuid) > (?, ?)", cursor.ValidFrom,
+			cursor.ExchangeRateUuid)
 	}
 
-	tx.Commit()
+	err := query.Order("valid_from_timestamp, exchange_rate_uuid").
+		Limit(limit).
+		Find(&exchangeRateOrms).Error
 
-	return t.TransactionUuid, nil
+	return exchangeRateOrms, err
 }
 
-func (d *DatabaseAdapter) CreateTransfer(transfer BankTransferOrm) (uuid.UUID, error) {
-	if err := d.db.Create(transfer).Error; err != nil {
-		return uuid.Nil, err
-	}
-
-	return transfer.TransferUuid, nil
+// GetExchangeRateCandles aggregates the rates into OHLC buckets. 'bucket' is a postgres date_trunc field
+// (minute, hour, day)
+func (d *DatabaseAdapter) GetExchangeRateCandles(fromCur string, toCur string, bucket string, start time.Time,
+	end time.Time, after time.Time, limit int) ([]BankExchangeRateCandleOrm, error) {
+	var candleOrms []BankExchangeRateCandleOrm
+
+	err := d.db.Raw(`
+		SELECT * FROM (
+			SELECT date_trunc(?, valid_from_timestamp) AS bucket_start,
+				(array_agg(rate ORDER BY valid_from_timestamp))[1] AS open,
+				max(rate) AS high,
+				min(rate) AS low,
+				(array_agg(rate ORDER BY valid_from_timestamp DESC))[1] AS close,
+				count(*) AS count
+			FROM bank_exchange_rates
+			WHERE from_currency = ? AND to_currency = ?
+				AND valid_from_timestamp >= ? AND valid_from_timestamp < ?
+			GROUP BY 1
+		) candles
+		WHERE bucket_start > ?
+		ORDER BY bucket_start
+		LIMIT ?`,
+		bucket, fromCur, toCur, start, end, after, limit).Scan(&candleOrms).Error
+
+	return candleOrms, err
 }
 
-func (d *DatabaseAdapter) CreateTransferTransactionPair(fromAccountOrm BankAccountOrm, toAccountOrm BankAccountOrm,
-	fromTransactionOrm BankTransactionOrm, toTransactionOrm BankTransactionOrm) (bool, error) {
+func (d *DatabaseAdapter) ListTransactions(accountUuid uuid.UUID, filter bank.TransactionFilter,
+	cursor *bank.TransactionCursor, limit int) ([]BankTransactionOrm, error) {
+	var transactionOrms []BankTransactionOrm
+
+	query := d.db.Where("account_uuid = ?", accountUuid)
 
-	tx := d.db.Begin()
+	if !filter.StartTimestamp.IsZero() {
+		query = query.Where("transaction_timestamp >= ?", filter.StartTimestamp)
+	}
 
-	// create from account transaction
-	if err := d.db.Create(fromTransactionOrm).Error; err != nil {
-		tx.Rollback()
-		return false, err
+	if !filter.EndTimestamp.IsZero() {
+		query = query.Where("transaction_timestamp < ?", filter.EndTimestamp)
 	}
 
-	// create from to transaction
-	if err := d.db.Create(toTransactionOrm).Error; err != nil {
-		tx.Rollback()
-		return false, err
+	if filter.TransactionType != "" {
+		query = query.Where("transaction_type = ?", filter.TransactionType)
 	}
 
-	// check if customer wants to send money to him/herself
-	if fromAccountOrm.AccountNumber == toAccountOrm.AccountNumber {
-		return false, fmt.Errorf("cannot send money to yourself")
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
 	}
 
-	// calculate new account balance (fromAccount)
-	fromAccountBalanceNew := fromAccountOrm.CurrentBalance - fromTransactionOrm.Amount
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
 
-	// check if fromAccount has enough money to send
-	if fromAccountBalanceNew < 0 {
-		return false, fmt.Errorf("insufficient funds")
+	if filter.NotesContains != "" {
+		query = query.Where(`notes ILIKE ? ESCAPE '\'`, "%"+escapeLike(filter.NotesContains)+"%")
 	}
 
-	if err := tx.Model(&fromAccountOrm).Updates(
-		map[string]interface{}{
-			"current_balance": fromAccountBalanceNew,
-			"updated_at":      time.Now(),
-		},
-	).Error; err != nil {
-		tx.Rollback()
-		return false, err
+	order := "transaction_timestamp, transaction_uuid"
+	keyset := "(transaction_timestamp, transaction_uuid) > (?, ?)"
+
+	if filter.SortDescending {
+		order = "transaction_timestamp DESC, transaction_uuid DESC"
+		keyset = "(transaction_timestamp, transaction_uuid) < (?, ?)"
 	}
 
-	// calculate new account balance (toAccount)
-	toAccountBalanceNew := toAccountOrm.CurrentBalance + toTransactionOrm.Amount
-
-	if err := tx.Model(&toAccountOrm).Updates(
-		map[string]interface{}{
-			"current_balance": toAccountBalanceNew,
-			"updated_at":      time.Now(),
-		},
-	).Error; err != nil {
-		tx.Rollback()
-		return false, err
+	if cursor != nil {
+		query = query.Where(keyset, cursor.Timestamp, cursor.TransactionUuid)
 	}
 
-	tx.Commit()
+	err := query.Order(order).Limit(limit).Find(&transactionOrms).Error
 
-	return true, nil
+	return transactionOrms, err
 }
 
-func (d *DatabaseAdapter) UpdateTransferStatus(transfer BankTransferOrm, status bool) error {
-	if err := d.db.Model(&transfer).Updates(
-		map[string]interface{}{
-			"transfer_success": status,
-			"updated_at":       time.Now(),
-		},
-	).Error; err != nil {
-		return err
-	}
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
 
-	return nil
+// GetStatementSnapshot reads the account and its transactions in one REPEATABLE READ transaction, so a transaction
+// posted meanwhile can't be in the balance and missing from the net amount (or the other way round). The period is
+// [start, end), the transactions are in chronological order
+func (d *DatabaseAdapter) GetStatementSnapshot(acct string, start time.Time, end time.Time) (StatementSnapshot,
+	error) {
+	var snapshot StatementSnapshot
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&snapshot.Account, "account_number = ?", acct).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Raw(`
+			SELECT COALESCE(SUM(CASE WHEN transaction_type = ? THEN amount ELSE -amount END), 0)
+			FROM bank_transactions
+			WHERE account_uuid = ? AND transaction_timestamp >= ?`,
+			bank.TransactionTypeIN, snapshot.Account.AccountUuid, start).Scan(&snapshot.NetSinceStart).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("account_uuid = ? AND transaction_timestamp >= ? AND transaction_timestamp < ?",
+			snapshot.Account.AccountUuid, start, end).
+			Order("transaction_timestamp, transaction_uuid").
+			Find(&snapshot.Transactions).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+
+	return snapshot, err
 }
@@ -12,9 +12,18 @@ type BankAccountOrm struct {
 	AccountName    string
 	Currency       string
 	CurrentBalance float64
+	OverdraftLimit float64
+	AccountStatus  string
+	AccountType    string
+	AccountTier    string
+	AccountProduct string
+	ClosedAt       *time.Time
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	Transactions   []BankTransactionOrm `gorm:"foreignKey:AccountUuid"`
+
+	// LegacyAccountNumber is set on the accounts numbered before the check digit scheme
+	LegacyAccountNumber bool
 }
 
 func (BankAccountOrm) TableName() string {
@@ -28,6 +37,7 @@ type BankTransactionOrm struct {
 	Amount               float64
 	TransactionType      string
 	Notes                string
+	ReversalOf           *uuid.UUID
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 }
@@ -59,6 +69,17 @@ type BankTransferOrm struct {
 	Amount            float64
 	TransferTimestamp time.Time
 	TransferSuccess   bool
+	TransferStatus    string
+	FailureReason     string
+	AuthorizedAt      *time.Time
+	SettledAt         *time.Time
+	FailedAt          *time.Time
+	FeeAmount         float64
+	FeeCurrency       *string
+	Reversed          bool
+	ReversalReason    string
+	ReversedAt        *time.Time
+	RequestedBy       *string
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
 }
@@ -66,3 +87,460 @@ type BankTransferOrm struct {
 func (BankTransferOrm) TableName() string {
 	return "bank_transfers"
 }
+
+// BankTransferDetailOrm is a transfer with the account numbers of both sides
+type BankTransferDetailOrm struct {
+	BankTransferOrm   `gorm:"embedded"`
+	FromAccountNumber string
+	ToAccountNumber   string
+}
+
+// BankExchangeRateCandleOrm is not a table, it holds the aggregated rows from bank_exchange_rates
+type BankExchangeRateCandleOrm struct {
+	BucketStart time.Time
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Count       int
+}
+
+type BankTransactionDailySummaryOrm struct {
+	AccountUuid      uuid.UUID `gorm:"primaryKey"`
+	SummaryDate      time.Time `gorm:"primaryKey;type:date"`
+	SumIn            float64
+	SumOut           float64
+	TransactionCount int
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+func (BankTransactionDailySummaryOrm) TableName() string {
+	return "bank_transaction_daily_summaries"
+}
+
+type LedgerJournalEntryOrm struct {
+	JournalUuid   uuid.UUID `gorm:"primaryKey"`
+	EntryType     string
+	Description   string
+	ReferenceUuid *uuid.UUID
+	CreatedAt     time.Time
+}
+
+func (LedgerJournalEntryOrm) TableName() string {
+	return "ledger_journal_entries"
+}
+
+type LedgerPostingOrm struct {
+	PostingUuid     uuid.UUID `gorm:"primaryKey"`
+	JournalUuid     uuid.UUID
+	AccountUuid     uuid.UUID
+	TransactionUuid *uuid.UUID
+	Currency        string
+	Amount          float64
+	CreatedAt       time.Time
+}
+
+func (LedgerPostingOrm) TableName() string {
+	return "ledger_postings"
+}
+
+// StatementSnapshot is what a statement is built from, read at a single point in time : the account with its current
+// balance, sum(IN) - sum(OUT) of the transactions since the period start and the transactions of the period
+type StatementSnapshot struct {
+	Account       BankAccountOrm
+	NetSinceStart float64
+	Transactions  []BankTransactionOrm
+}
+
+// LedgerEntry is what gets written for every money movement : the journal entry, its postings and the customer
+// facing bank_transactions rows that the postings refer to
+type LedgerEntry struct {
+	Journal      LedgerJournalEntryOrm
+	Postings     []LedgerPostingOrm
+	Transactions []BankTransactionOrm
+	Events       []OutboxEventOrm
+	// Charge lets bank charges such as overdraft interest take an account past its overdraft limit
+	Charge bool
+	// Limit is checked with the accounts of the entry locked, before the postings are applied
+	Limit *LimitCheck
+}
+
+// LimitCheck holds the limit check of the account an entry takes money out of, Check gets the limits of the account
+// and what it used in the windows starting at Day, Month and Hour. TransferUuid is the transfer being settled, if any
+type LimitCheck struct {
+	AccountUuid  uuid.UUID
+	TransferUuid uuid.UUID
+	Day          time.Time
+	Month        time.Time
+	Hour         time.Time
+	Check        func(limits BankLimitTierOrm, usage BankLimitUsageOrm) error
+}
+
+// BankAccountBalanceOrm is not a table, it holds the balances computed by GetAccountBalancesForReconciliation
+// BankAccountBalanceOrm is not a table. CheckpointBalance is the expected balance up to the checkpoint time of the
+// reconciliation, it becomes the new baseline of an account that reconciles
+type BankAccountBalanceOrm struct {
+	AccountUuid       uuid.UUID
+	AccountNumber     string
+	Currency          string
+	StoredBalance     float64
+	ExpectedBalance   float64
+	LedgerBalance     float64
+	CheckpointBalance float64
+}
+
+type BankBalanceBaselineOrm struct {
+	AccountUuid       uuid.UUID `gorm:"primaryKey"`
+	BaselineBalance   float64
+	BaselineTimestamp time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (BankBalanceBaselineOrm) TableName() string {
+	return "bank_balance_baselines"
+}
+
+type BankReconciliationRunOrm struct {
+	RunUuid         uuid.UUID `gorm:"primaryKey"`
+	StartedAt       time.Time
+	FinishedAt      time.Time
+	AccountsChecked int
+	Mismatches      int
+	Repair          bool
+}
+
+func (BankReconciliationRunOrm) TableName() string {
+	return "bank_reconciliation_runs"
+}
+
+// BankReconciliationItemOrm is one mismatch of a run. Adjustment is the journal entry that repairs it, it is posted
+// with the run and AdjustmentJournalUuid refers to it once it is
+type BankReconciliationItemOrm struct {
+	ItemUuid              uuid.UUID `gorm:"primaryKey"`
+	RunUuid               uuid.UUID
+	AccountUuid           uuid.UUID
+	StoredBalance         float64
+	ExpectedBalance       float64
+	LedgerBalance         float64
+	Repaired              bool
+	AdjustmentJournalUuid *uuid.UUID
+	Adjustment            *LedgerEntry `gorm:"-"`
+	CreatedAt             time.Time
+}
+
+func (BankReconciliationItemOrm) TableName() string {
+	return "bank_reconciliation_items"
+}
+
+type BankScheduledTransferOrm struct {
+	ScheduleUuid      uuid.UUID `gorm:"primaryKey"`
+	FromAccountNumber string
+	ToAccountNumber   string
+	Currency          string
+	Amount            float64
+	Recurrence        string
+	StartAt           time.Time
+	EndAt             *time.Time
+	OccurrenceCount   int
+	NextRunAt         time.Time
+	DueAt             time.Time
+	Attempt           int
+	LockedUntil       *time.Time
+	Status            string
+	RequestedBy       *string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (BankScheduledTransferOrm) TableName() string {
+	return "bank_scheduled_transfers"
+}
+
+type BankScheduledTransferExecutionOrm struct {
+	ExecutionUuid uuid.UUID `gorm:"primaryKey"`
+	ScheduleUuid  uuid.UUID
+	ScheduledFor  time.Time
+	ExecutedAt    time.Time
+	Attempt       int
+	Outcome       string
+	TransferUuid  *uuid.UUID
+	Reason        string
+}
+
+func (BankScheduledTransferExecutionOrm) TableName() string {
+	return "bank_scheduled_transfer_executions"
+}
+
+// BankLimitTierOrm is a row of bank_limit_tiers, it is also used for the limits of an account after the per
+// account overrides are applied
+type BankLimitTierOrm struct {
+	AccountTier         string `gorm:"primaryKey"`
+	MaxSingleOut        *float64
+	DailyOutLimit       *float64
+	MonthlyOutLimit     *float64
+	MaxTransfersPerHour *int
+	MinimumBalance      float64
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+func (BankLimitTierOrm) TableName() string {
+	return "bank_limit_tiers"
+}
+
+type BankAccountLimitsOrm struct {
+	AccountUuid         uuid.UUID `gorm:"primaryKey"`
+	MaxSingleOut        *float64
+	DailyOutLimit       *float64
+	MonthlyOutLimit     *float64
+	MaxTransfersPerHour *int
+	MinimumBalance      *float64
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+func (BankAccountLimitsOrm) TableName() string {
+	return "bank_account_limits"
+}
+
+type BankLimitUsageOrm struct {
+	Balance           float64
+	OverdraftLimit    float64
+	DailyOut          float64
+	MonthlyOut        float64
+	TransfersLastHour int
+}
+
+type BankInterestRateOrm struct {
+	RateUuid   uuid.UUID `gorm:"primaryKey"`
+	Currency   *string
+	RateType   string
+	MinBalance float64
+	AnnualRate float64
+	ValidFrom  time.Time
+	ValidTo    *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (BankInterestRateOrm) TableName() string {
+	return "bank_interest_rates"
+}
+
+type BankInterestAccrualOrm struct {
+	AccountUuid     uuid.UUID `gorm:"primaryKey"`
+	AccrualDate     time.Time `gorm:"primaryKey"`
+	RateType        string
+	Balance         float64
+	AnnualRate      float64
+	Amount          float64
+	TransactionUuid *uuid.UUID
+	CreatedAt       time.Time
+}
+
+func (BankInterestAccrualOrm) TableName() string {
+	return "bank_interest_accruals"
+}
+
+// BankInterestBalanceOrm is not a table, it is an account with its balance at the end of the accrual day
+type BankInterestBalanceOrm struct {
+	BankAccountOrm `gorm:"embedded"`
+	ClosingBalance float64
+}
+
+// BankInterestRunOrm is a day whose accrual went through for every account
+type BankInterestRunOrm struct {
+	AccrualDate time.Time `gorm:"primaryKey"`
+	CompletedAt time.Time
+}
+
+func (BankInterestRunOrm) TableName() string {
+	return "bank_interest_runs"
+}
+
+type BankFeeRuleOrm struct {
+	RuleUuid   uuid.UUID `gorm:"primaryKey"`
+	Currency   *string
+	FeeType    string
+	MinAmount  float64
+	FlatAmount float64
+	Percentage float64
+	MinFee     *float64
+	MaxFee     *float64
+	ValidFrom  time.Time
+	ValidTo    *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (BankFeeRuleOrm) TableName() string {
+	return "bank_fee_rules"
+}
+
+type OutboxEventOrm struct {
+	EventId       int64 `gorm:"primaryKey"`
+	EventUuid     uuid.UUID
+	EventType     string
+	EventVersion  int
+	AggregateType string
+	AggregateId   string
+	Payload       string
+	CreatedAt     time.Time
+	PublishedAt   *time.Time
+	Attempts      int
+	LastError     string
+	ClaimedUntil  *time.Time
+}
+
+func (OutboxEventOrm) TableName() string {
+	return "outbox_events"
+}
+
+// OutboxFeedEventOrm is an outbox event with the id of the database transaction that wrote it, read by the
+// activity feed
+type OutboxFeedEventOrm struct {
+	OutboxEventOrm
+	TransactionId int64
+}
+
+type WebhookSubscriptionOrm struct {
+	SubscriptionUuid      uuid.UUID `gorm:"primaryKey"`
+	AccountNumber         *string
+	Url                   string
+	EventTypes            string
+	Secret                string
+	LargeWithdrawalAmount *float64
+	LowBalanceAmount      *float64
+	Status                string
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+func (WebhookSubscriptionOrm) TableName() string {
+	return "webhook_subscriptions"
+}
+
+type WebhookDeliveryOrm struct {
+	DeliveryUuid     uuid.UUID `gorm:"primaryKey"`
+	SubscriptionUuid uuid.UUID
+	EventUuid        uuid.UUID
+	EventType        string
+	Payload          string
+	Status           string
+	Attempts         int
+	NextAttemptAt    time.Time
+	LockedUntil      *time.Time
+	LastStatusCode   *int
+	LastError        string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	DeliveredAt      *time.Time
+}
+
+func (WebhookDeliveryOrm) TableName() string {
+	return "webhook_deliveries"
+}
+
+type WebhookDeliveryAttemptOrm struct {
+	AttemptUuid  uuid.UUID `gorm:"primaryKey"`
+	DeliveryUuid uuid.UUID
+	Attempt      int
+	AttemptedAt  time.Time
+	StatusCode   *int
+	Error        string
+	DurationMs   int64
+}
+
+func (WebhookDeliveryAttemptOrm) TableName() string {
+	return "webhook_delivery_attempts"
+}
+
+type WebhookDeadLetterOrm struct {
+	DeliveryUuid     uuid.UUID `gorm:"primaryKey"`
+	SubscriptionUuid uuid.UUID
+	EventType        string
+	Payload          string
+	Attempts         int
+	LastError        string
+	DeadAt           time.Time
+	RedeliveredAt    *time.Time
+}
+
+func (WebhookDeadLetterOrm) TableName() string {
+	return "webhook_dead_letters"
+}
+
+type AuditLogOrm struct {
+	Sequence    int64 `gorm:"primaryKey;autoIncrement:false"`
+	AuditUuid   uuid.UUID
+	Actor       string
+	Method      string
+	RequestId   string
+	Action      string
+	EntityType  string
+	EntityIds   string
+	BeforeValue string
+	AfterValue  string
+	Outcome     string
+	Error       string
+	CreatedAt   time.Time
+	PrevHash    string
+	Hash        string
+}
+
+func (AuditLogOrm) TableName() string {
+	return "audit_log"
+}
+
+type BankRiskAssessmentOrm struct {
+	AssessmentUuid     uuid.UUID `gorm:"primaryKey"`
+	Operation          string
+	AccountNumber      string
+	CounterpartyNumber *string
+	Currency           string
+	Amount             float64
+	Decision           string
+	Score              int
+	Signals            string
+	ReferenceUuid      *uuid.UUID
+	ReviewedBy         *string
+	ReviewDecision     *string
+	ReviewNote         *string
+	ReviewedAt         *time.Time
+	CreatedAt          time.Time
+}
+
+func (BankRiskAssessmentOrm) TableName() string {
+	return "bank_risk_assessments"
+}
+
+// BankRiskHistoryOrm is not a table, it holds the account history computed by GetRiskHistory
+type BankRiskHistoryOrm struct {
+	TransfersToCounterparty int
+	OutCount                int
+	OutAverage              float64
+	OutStdDev               float64
+	RecentOutCount          int
+}
+
+type BankScreeningHitOrm struct {
+	HitUuid             uuid.UUID `gorm:"primaryKey"`
+	Operation           string
+	PartyRole           string
+	AccountNumber       *string
+	PartyName           string
+	ListedName          string
+	ListedAccountNumber string
+	ListReason          string
+	MatchType           string
+	Score               float64
+	Action              string
+	ReferenceUuid       *uuid.UUID
+	CreatedAt           time.Time
+}
+
+func (BankScreeningHitOrm) TableName() string {
+	return "bank_screening_hits"
+}
@@ -0,0 +1,19 @@
+package database
+
+import "time"
+
+// GetFeeRules returns the fee rules valid at ts for the currency and the rules without currency, the currency
+// rules first
+func (d *DatabaseAdapter) GetFeeRules(currency string, ts time.Time) ([]BankFeeRuleOrm, error) {
+	var ruleOrms []BankFeeRuleOrm
+
+	err := d.db.
+		Where("currency = ? OR currency IS NULL", currency).
+		Where("valid_from <= ? AND (valid_to IS NULL OR valid_to >= ?)", ts, ts).
+		Order("currency NULLS LAST").
+		Order("fee_type").
+		Order("min_amount").
+		Find(&ruleOrms).Error
+
+	return ruleOrms, err
+}
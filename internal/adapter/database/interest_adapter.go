@@ -0,0 +1,111 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"gorm.io/gorm"
+)
+
+// GetAccountsForInterest returns the customer accounts that weren't closed by the end of date, with their balance
+// at the end of date taken from the ledger postings. Accounts with a zero closing balance are left out
+func (d *DatabaseAdapter) GetAccountsForInterest(date time.Time) ([]BankInterestBalanceOrm, error) {
+	var balanceOrms []BankInterestBalanceOrm
+
+	endOfDay := date.AddDate(0, 0, 1)
+
+	err := d.db.Raw(`
+		SELECT * FROM (
+			SELECT a.*,
+				COALESCE((
+					SELECT SUM(p.amount) FROM ledger_postings p
+					WHERE p.account_uuid = a.account_uuid AND p.created_at < ?
+				), 0) AS closing_balance
+			FROM bank_accounts a
+			WHERE a.account_type = ? AND a.created_at < ? AND (a.closed_at IS NULL OR a.closed_at >= ?)
+		) balances
+		WHERE closing_balance <> 0
+		ORDER BY account_number`,
+		endOfDay, bank.AccountTypeCustomer, endOfDay, endOfDay).
+		Scan(&balanceOrms).Error
+
+	return balanceOrms, err
+}
+
+// GetLastInterestRun returns the last day accrued for every account, nil if interest was never accrued
+func (d *DatabaseAdapter) GetLastInterestRun() (*time.Time, error) {
+	var runOrm BankInterestRunOrm
+
+	err := d.db.Order("accrual_date DESC").First(&runOrm).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &runOrm.AccrualDate, nil
+}
+
+func (d *DatabaseAdapter) SaveInterestRun(run BankInterestRunOrm) error {
+	return d.db.Exec(`
+		INSERT INTO bank_interest_runs (accrual_date, completed_at) VALUES (?, ?)
+		ON CONFLICT (accrual_date) DO UPDATE SET completed_at = EXCLUDED.completed_at`,
+		run.AccrualDate, run.CompletedAt).Error
+}
+
+// GetInterestRate returns the rate valid on date for the balance band, a rate for the currency is preferred over a
+// rate for every currency
+func (d *DatabaseAdapter) GetInterestRate(currency string, rateType string, balance float64, date time.Time) (
+	BankInterestRateOrm, error) {
+	var rateOrm BankInterestRateOrm
+
+	err := d.db.
+		Where("rate_type = ? AND (currency = ? OR currency IS NULL) AND min_balance <= ?", rateType, currency, balance).
+		Where("valid_from <= ? AND (valid_to IS NULL OR valid_to >= ?)", date, date).
+		Order("currency NULLS LAST").
+		Order("min_balance DESC").
+		First(&rateOrm).Error
+
+	return rateOrm, err
+}
+
+// PostInterestAccrual records the accrual and posts its journal entry in one database transaction. It returns false
+// without posting anything if the account already has an accrual for that day
+func (d *DatabaseAdapter) PostInterestAccrual(accrual BankInterestAccrualOrm, entry LedgerEntry) (bool, error) {
+	posted := false
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		// the transaction row must exist before the accrual refers to it
+		transactionUuid := accrual.TransactionUuid
+		accrual.TransactionUuid = nil
+
+		res := tx.Exec(`
+			INSERT INTO bank_interest_accruals
+				(account_uuid, accrual_date, rate_type, balance, annual_rate, amount, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (account_uuid, accrual_date) DO NOTHING`,
+			accrual.AccountUuid, accrual.AccrualDate, accrual.RateType, accrual.Balance, accrual.AnnualRate,
+			accrual.Amount, accrual.CreatedAt)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := postLedgerEntry(tx, entry); err != nil {
+			return err
+		}
+
+		posted = true
+
+		return tx.Model(&BankInterestAccrualOrm{}).
+			Where("account_uuid = ? AND accrual_date = ?", accrual.AccountUuid, accrual.AccrualDate).
+			Update("transaction_uuid", transactionUuid).Error
+	})
+
+	return posted, err
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PostLedgerEntry writes the journal entry, its transactions and postings and applies the postings to the account
+// balances in one database transaction
+func (d *DatabaseAdapter) PostLedgerEntry(entry LedgerEntry) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		return postLedgerEntry(tx, entry)
+	})
+}
+
+func postLedgerEntry(tx *gorm.DB, entry LedgerEntry) error {
+	if entry.Limit != nil {
+		if err := checkLimit(tx, entry); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Create(&entry.Journal).Error; err != nil {
+		return err
+	}
+
+	for _, t := range entry.Transactions {
+		if err := tx.Create(&t).Error; err != nil {
+			return err
+		}
+
+		if err := upsertDailySummary(tx, t); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Create(&entry.Postings).Error; err != nil {
+		return err
+	}
+
+	// update the balances in a stable order so two opposite transfers can't deadlock on the account rows
+	postings := make([]LedgerPostingOrm, len(entry.Postings))
+	copy(postings, entry.Postings)
+
+	sort.Slice(postings, func(i, j int) bool {
+		return postings[i].AccountUuid.String() < postings[j].AccountUuid.String()
+	})
+
+	var balances []BankAccountOrm
+
+	for _, p := range postings {
+		acct, err := applyPosting(tx, p, entry.Charge)
+		if err != nil {
+			return err
+		}
+
+		if acct.AccountType != bank.AccountTypeCustomer {
+			continue
+		}
+
+		// an account with several postings gets one event with its final balance
+		if n := len(balances); n > 0 && balances[n-1].AccountUuid == acct.AccountUuid {
+			balances[n-1] = acct
+		} else {
+			balances = append(balances, acct)
+		}
+	}
+
+	// the new balances are only known once the postings are applied, so their events come after the entry's events
+	events := entry.Events
+
+	for _, acct := range balances {
+		e, err := bank.NewEvent(bank.EventAccountBalanceChanged, bank.AggregateAccount, acct.AccountNumber,
+			bank.BalanceEventPayload{
+				AccountNumber: acct.AccountNumber,
+				Currency:      acct.Currency,
+				Balance:       acct.CurrentBalance,
+				JournalUuid:   entry.Journal.JournalUuid,
+				Timestamp:     entry.Journal.CreatedAt,
+			})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, toOutboxEventOrm(e))
+	}
+
+	return insertOutboxEvents(tx, events)
+}
+
+// applyPosting adds the posting to the account balance and returns the updated account. Customer accounts can't go
+// below their overdraft limit unless the posting is a charge, the check is part of the update so concurrent debits
+// can't both pass it
+func applyPosting(tx *gorm.DB, p LedgerPostingOrm, charge bool) (BankAccountOrm, error) {
+	var acct BankAccountOrm
+
+	err := tx.Raw(`
+		UPDATE bank_accounts
+		SET current_balance = current_balance + ?, updated_at = ?
+		WHERE account_uuid = ?
+			AND (account_type <> ? OR ? OR ? >= 0 OR current_balance + ? >= -overdraft_limit)
+		RETURNING *`,
+		p.Amount, time.Now(), p.AccountUuid, bank.AccountTypeCustomer, charge, p.Amount, p.Amount).Scan(&acct).Error
+
+	if err != nil {
+		return acct, err
+	}
+
+	if acct.AccountUuid == uuid.Nil {
+		return acct, fmt.Errorf("%w : account %v can't be debited %.2f", bank.ErrInsufficientFunds, p.AccountUuid,
+			-p.Amount)
+	}
+
+	return acct, nil
+}
+
+// GetSystemAccount returns the system account of the given kind (cash, fees, fx) for the currency, creating it on
+// first use
+func (d *DatabaseAdapter) GetSystemAccount(kind string, currency string) (BankAccountOrm, error) {
+	accountNumber := bank.SystemAccountNumber(kind, currency)
+
+	acct, err := d.GetBankAccountByAccountNumber(accountNumber)
+	if err == nil {
+		return acct, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return acct, err
+	}
+
+	now := time.Now()
+
+	if err := d.db.Exec(`
+		INSERT INTO bank_accounts
+			(account_uuid, account_number, account_name, currency, current_balance, account_status, account_type,
+			created_at, updated_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?, ?, ?)
+		ON CONFLICT (account_number) DO NOTHING`,
+		uuid.New(), accountNumber, "System "+kind+" "+currency, currency, bank.AccountStatusActive,
+		bank.AccountTypeSystem, now, now).Error; err != nil {
+		return acct, fmt.Errorf("%w : %v : %v", bank.ErrSystemAccountNotFound, accountNumber, err)
+	}
+
+	return d.GetBankAccountByAccountNumber(accountNumber)
+}
+
+// GetLedgerBalance returns the sum of all postings of an account
+func (d *DatabaseAdapter) GetLedgerBalance(accountUuid uuid.UUID) (float64, error) {
+	var balance float64
+
+	err := d.db.Raw("SELECT COALESCE(SUM(amount), 0) FROM ledger_postings WHERE account_uuid = ?", accountUuid).
+		Scan(&balance).Error
+
+	return balance, err
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetEffectiveAccountLimits returns the limits of the account tier with the per account overrides applied
+func (d *DatabaseAdapter) GetEffectiveAccountLimits(accountUuid uuid.UUID) (BankLimitTierOrm, error) {
+	return getEffectiveAccountLimits(d.db, accountUuid)
+}
+
+func getEffectiveAccountLimits(tx *gorm.DB, accountUuid uuid.UUID) (BankLimitTierOrm, error) {
+	var limitsOrm BankLimitTierOrm
+
+	err := tx.Raw(`
+		SELECT a.account_tier,
+			COALESCE(l.max_single_out, t.max_single_out) AS max_single_out,
+			COALESCE(l.daily_out_limit, t.daily_out_limit) AS daily_out_limit,
+			COALESCE(l.monthly_out_limit, t.monthly_out_limit) AS monthly_out_limit,
+			COALESCE(l.max_transfers_per_hour, t.max_transfers_per_hour) AS max_transfers_per_hour,
+			COALESCE(l.minimum_balance, t.minimum_balance, 0) AS minimum_balance
+		FROM bank_accounts a
+		LEFT JOIN bank_limit_tiers t ON t.account_tier = a.account_tier
+		LEFT JOIN bank_account_limits l ON l.account_uuid = a.account_uuid
+		WHERE a.account_uuid = ?`,
+		accountUuid).Scan(&limitsOrm).Error
+
+	return limitsOrm, err
+}
+
+// getLimitUsage sums the outgoing transactions since day and month and counts the transfers since hour. Reversal
+// transactions, failed transfers, transfers still being authorized or held for review and the transfer being
+// settled don't count against the limits
+func getLimitUsage(tx *gorm.DB, check LimitCheck) (BankLimitUsageOrm, error) {
+	var usageOrm BankLimitUsageOrm
+
+	err := tx.Raw(`
+		SELECT a.current_balance AS balance,
+			a.overdraft_limit,
+			COALESCE((
+				SELECT SUM(t.amount) FROM bank_transactions t
+				WHERE t.account_uuid = a.account_uuid AND t.transaction_type = ? AND t.reversal_of IS NULL
+					AND t.transaction_timestamp >= ?
+			), 0) AS daily_out,
+			COALESCE((
+				SELECT SUM(t.amount) FROM bank_transactions t
+				WHERE t.account_uuid = a.account_uuid AND t.transaction_type = ? AND t.reversal_of IS NULL
+					AND t.transaction_timestamp >= ?
+			), 0) AS monthly_out,
+			(
+				SELECT COUNT(*) FROM bank_transfers tr
+				WHERE tr.from_account_uuid = a.account_uuid AND tr.created_at >= ?
+					AND tr.transfer_status NOT IN (?, ?, ?) AND tr.transfer_uuid <> ?
+			) AS transfers_last_hour
+		FROM bank_accounts a
+		WHERE a.account_uuid = ?`,
+		bank.TransactionTypeOUT, check.Day, bank.TransactionTypeOUT, check.Month, check.Hour,
+		bank.TransferStatusPending, bank.TransferStatusFailed, bank.TransferStatusHeld, check.TransferUuid,
+		check.AccountUuid).Scan(&usageOrm).Error
+
+	return usageOrm, err
+}
+
+// checkLimit locks the accounts of the entry in the order their balances are updated and runs the limit check of
+// the entry on what the debited account used, so concurrent payments can't both pass it
+func checkLimit(tx *gorm.DB, entry LedgerEntry) error {
+	accountUuids := make([]string, 0, len(entry.Postings))
+	for _, p := range entry.Postings {
+		accountUuids = append(accountUuids, p.AccountUuid.String())
+	}
+
+	sort.Strings(accountUuids)
+
+	if err := tx.Exec(`SELECT 1 FROM bank_accounts WHERE account_uuid IN ? ORDER BY account_uuid FOR UPDATE`,
+		accountUuids).Error; err != nil {
+		return err
+	}
+
+	limitsOrm, err := getEffectiveAccountLimits(tx, entry.Limit.AccountUuid)
+	if err != nil {
+		return err
+	}
+
+	usageOrm, err := getLimitUsage(tx, *entry.Limit)
+	if err != nil {
+		return err
+	}
+
+	return entry.Limit.Check(limitsOrm, usageOrm)
+}
+
+func (d *DatabaseAdapter) GetLimitTier(tier string) (BankLimitTierOrm, error) {
+	var tierOrm BankLimitTierOrm
+
+	if err := d.db.First(&tierOrm, "account_tier = ?", tier).Error; err != nil {
+		return tierOrm, err
+	}
+
+	return tierOrm, nil
+}
+
+func (d *DatabaseAdapter) UpdateBankAccountTier(acct BankAccountOrm, tier string) error {
+	return d.db.Model(&acct).Updates(
+		map[string]interface{}{
+			"account_tier": tier,
+			"updated_at":   time.Now(),
+		},
+	).Error
+}
+
+// SaveAccountLimits replaces the per account overrides, nil columns fall back to the tier
+func (d *DatabaseAdapter) SaveAccountLimits(limits BankAccountLimitsOrm) error {
+	return d.db.Exec(`
+		INSERT INTO bank_account_limits
+			(account_uuid, max_single_out, daily_out_limit, monthly_out_limit, max_transfers_per_hour, minimum_balance,
+			created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (account_uuid) DO UPDATE SET
+			max_single_out = EXCLUDED.max_single_out,
+			daily_out_limit = EXCLUDED.daily_out_limit,
+			monthly_out_limit = EXCLUDED.monthly_out_limit,
+			max_transfers_per_hour = EXCLUDED.max_transfers_per_hour,
+			minimum_balance = EXCLUDED.minimum_balance,
+			updated_at = EXCLUDED.updated_at`,
+		limits.AccountUuid, limits.MaxSingleOut, limits.DailyOutLimit, limits.MonthlyOutLimit,
+		limits.MaxTransfersPerHour, limits.MinimumBalance, limits.CreatedAt, limits.UpdatedAt).Error
+}
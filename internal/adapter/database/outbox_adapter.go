@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+const outboxRelayLockKey = 7835002
+
+// outboxChannel is notified when a database transaction that wrote outbox events commits
+const outboxChannel = "outbox_events"
+
+func insertOutboxEvents(tx *gorm.DB, events []OutboxEventOrm) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&events).Error; err != nil {
+		return err
+	}
+
+	// postgres delivers the notification on commit and folds duplicates within a transaction
+	return tx.Exec("SELECT pg_notify(?, '')", outboxChannel).Error
+}
+
+func toOutboxEventOrm(e bank.Event) OutboxEventOrm {
+	return OutboxEventOrm{
+		EventUuid:     e.EventUuid,
+		EventType:     e.EventType,
+		EventVersion:  e.EventVersion,
+		AggregateType: e.AggregateType,
+		AggregateId:   e.AggregateId,
+		Payload:       string(e.Payload),
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+// ClaimOutboxEvents claims up to limit unpublished events in event_id order until now + lease, so they can be
+// published outside of a database transaction. Nothing is claimed while another relay holds a live claim, only one
+// replica relays at a time and the events of an aggregate are published in order
+func (d *DatabaseAdapter) ClaimOutboxEvents(limit int, lease time.Duration) ([]OutboxEventOrm, error) {
+	var eventOrms []OutboxEventOrm
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxRelayLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+
+		// another replica is claiming right now
+		if !locked {
+			return nil
+		}
+
+		now := time.Now()
+
+		var claimed int64
+
+		if err := tx.Model(&OutboxEventOrm{}).Where("published_at IS NULL AND claimed_until > ?", now).
+			Count(&claimed).Error; err != nil {
+			return err
+		}
+
+		// another replica is still publishing its batch
+		if claimed > 0 {
+			return nil
+		}
+
+		if err := tx.Where("published_at IS NULL").Order("event_id").Limit(limit).Find(&eventOrms).Error; err != nil {
+			return err
+		}
+
+		if len(eventOrms) == 0 {
+			return nil
+		}
+
+		eventIds := make([]int64, 0, len(eventOrms))
+		for _, e := range eventOrms {
+			eventIds = append(eventIds, e.EventId)
+		}
+
+		return tx.Model(&OutboxEventOrm{}).Where("event_id IN ?", eventIds).
+			Update("claimed_until", now.Add(lease)).Error
+	})
+
+	return eventOrms, err
+}
+
+// CompleteOutboxClaim marks the published events as published, counts the failed attempts with their error and
+// releases the claim on every claimed event, the ones not published are claimed again by the next run
+func (d *DatabaseAdapter) CompleteOutboxClaim(claimed []int64, published []int64, failed map[int64]string) error {
+	if len(claimed) == 0 {
+		return nil
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if len(published) > 0 {
+			if err := tx.Model(&OutboxEventOrm{}).Where("event_id IN ?", published).
+				Update("published_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+
+		for eventId, lastError := range failed {
+			if err := tx.Model(&OutboxEventOrm{}).Where("event_id = ?", eventId).Updates(map[string]interface{}{
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": lastError,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&OutboxEventOrm{}).Where("event_id IN ?", claimed).
+			Update("claimed_until", nil).Error
+	})
+}
+
+// GetActivityFeedStart returns the feed position before the events that are not visible to every session yet, a
+// feed starting there only sees what is committed from now on
+func (d *DatabaseAdapter) GetActivityFeedStart() (bank.ActivityCursor, error) {
+	var txid int64
+
+	err := d.db.Raw("SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint - 1").Scan(&txid).Error
+
+	return bank.ActivityCursor{TransactionId: txid, EventId: math.MaxInt64}, err
+}
+
+// GetActivityFeedEvents returns up to limit events after the cursor in feed order, only for the aggregate when
+// aggregateId is set. Without upTo it stops before the oldest running transaction, so an event can't show up
+// behind a position that was already returned
+func (d *DatabaseAdapter) GetActivityFeedEvents(after bank.ActivityCursor, upTo *bank.ActivityCursor,
+	aggregateType string, aggregateId string, limit int) ([]OutboxFeedEventOrm, error) {
+	var eventOrms []OutboxFeedEventOrm
+
+	query := d.db.Table("outbox_events").
+		Select("event_id, event_uuid, event_type, event_version, aggregate_type, aggregate_id, payload, created_at, "+
+			"published_at, attempts, last_error, transaction_id::text::bigint AS transaction_id").
+		Where("(transaction_id, event_id) > (?::text::xid8, ?)", after.TransactionId, after.EventId)
+
+	if upTo != nil {
+		query = query.Where("(transaction_id, event_id) <= (?::text::xid8, ?)", upTo.TransactionId, upTo.EventId)
+	} else {
+		query = query.Where("transaction_id < pg_snapshot_xmin(pg_current_snapshot())")
+	}
+
+	if aggregateId != "" {
+		query = query.Where("aggregate_type = ? AND aggregate_id = ?", aggregateType, aggregateId)
+	}
+
+	err := query.Order("transaction_id, event_id").Limit(limit).Scan(&eventOrms).Error
+
+	return eventOrms, err
+}
+
+// ListenOutboxEvents calls notify whenever a transaction with outbox events commits, until ctx is done or the
+// connection breaks. It holds one pooled connection while listening
+func (d *DatabaseAdapter) ListenOutboxEvents(ctx context.Context, notify func()) error {
+	sqlDB, err := d.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("can't listen on %T, a pgx connection is needed", driverConn)
+		}
+
+		pgConn := stdlibConn.Conn()
+
+		if _, err := pgConn.Exec(ctx, "LISTEN "+outboxChannel); err != nil {
+			return err
+		}
+
+		for {
+			if _, err := pgConn.WaitForNotification(ctx); err != nil {
+				return err
+			}
+
+			notify()
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"gorm.io/gorm"
+)
+
+// GetAccountBalancesForReconciliation returns, for every customer account, the stored balance, the balance
+// recomputed from the baseline and the transaction history, and the sum of the ledger postings. The checkpoint
+// balance is the recomputed balance up to checkpoint
+func (d *DatabaseAdapter) GetAccountBalancesForReconciliation(checkpoint time.Time) ([]BankAccountBalanceOrm, error) {
+	var balanceOrms []BankAccountBalanceOrm
+
+	err := d.db.Raw(`
+		SELECT a.account_uuid,
+			a.account_number,
+			a.currency,
+			a.current_balance AS stored_balance,
+			COALESCE(b.baseline_balance, 0) + COALESCE((
+				SELECT SUM(CASE WHEN t.transaction_type = ? THEN t.amount ELSE -t.amount END)
+				FROM bank_transactions t
+				WHERE t.account_uuid = a.account_uuid
+					AND (b.baseline_timestamp IS NULL OR t.transaction_timestamp > b.baseline_timestamp)
+			), 0) AS expected_balance,
+			COALESCE((
+				SELECT SUM(p.amount) FROM ledger_postings p WHERE p.account_uuid = a.account_uuid
+			), 0) AS ledger_balance,
+			COALESCE(b.baseline_balance, 0) + COALESCE((
+				SELECT SUM(CASE WHEN t.transaction_type = ? THEN t.amount ELSE -t.amount END)
+				FROM bank_transactions t
+				WHERE t.account_uuid = a.account_uuid
+					AND (b.baseline_timestamp IS NULL OR t.transaction_timestamp > b.baseline_timestamp)
+					AND t.transaction_timestamp <= ?
+			), 0) AS checkpoint_balance
+		FROM bank_accounts a
+		LEFT JOIN bank_balance_baselines b ON b.account_uuid = a.account_uuid
+		WHERE a.account_type = ?
+		ORDER BY a.account_number`,
+		bank.TransactionTypeIN, bank.TransactionTypeIN, checkpoint, bank.AccountTypeCustomer).
+		Scan(&balanceOrms).Error
+
+	return balanceOrms, err
+}
+
+// SaveReconciliationRun records the run and its mismatches. The adjustment of an item is posted in the same
+// transaction, but only if the balance didn't change since it was read, the Repaired flag of items is updated
+// accordingly. The baselines are moved forward, a baseline is never moved back
+func (d *DatabaseAdapter) SaveReconciliationRun(run BankReconciliationRunOrm, items []BankReconciliationItemOrm,
+	baselines []BankBalanceBaselineOrm) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&run).Error; err != nil {
+			return err
+		}
+
+		for i := range items {
+			if items[i].Adjustment != nil {
+				res := tx.Exec(`SELECT 1 FROM bank_accounts WHERE account_uuid = ? AND current_balance = ? FOR UPDATE`,
+					items[i].AccountUuid, items[i].StoredBalance)
+				if res.Error != nil {
+					return res.Error
+				}
+
+				// the balance moved in between, leave it for the next run
+				items[i].Repaired = res.RowsAffected == 1
+
+				if items[i].Repaired {
+					if err := postLedgerEntry(tx, *items[i].Adjustment); err != nil {
+						return err
+					}
+
+					items[i].AdjustmentJournalUuid = &items[i].Adjustment.Journal.JournalUuid
+				}
+			}
+
+			if err := tx.Create(&items[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, b := range baselines {
+			if err := tx.Exec(`
+				INSERT INTO bank_balance_baselines
+					(account_uuid, baseline_balance, baseline_timestamp, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (account_uuid) DO UPDATE
+				SET baseline_balance = EXCLUDED.baseline_balance,
+					baseline_timestamp = EXCLUDED.baseline_timestamp,
+					updated_at = EXCLUDED.updated_at
+				WHERE bank_balance_baselines.baseline_timestamp < EXCLUDED.baseline_timestamp`,
+				b.AccountUuid, b.BaselineBalance, b.BaselineTimestamp, b.CreatedAt, b.UpdatedAt).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (d *DatabaseAdapter) GetTransactionByUuid(transactionUuid uuid.UUID) (BankTransactionOrm, error) {
+	var transactionOrm BankTransactionOrm
+
+	err := d.db.First(&transactionOrm, "transaction_uuid = ?", transactionUuid).Error
+
+	return transactionOrm, err
+}
+
+// GetJournalByTransaction returns the journal entry that posted the transaction, with all its postings
+func (d *DatabaseAdapter) GetJournalByTransaction(transactionUuid uuid.UUID) (LedgerEntry, error) {
+	var posting LedgerPostingOrm
+
+	if err := d.db.First(&posting, "transaction_uuid = ?", transactionUuid).Error; err != nil {
+		return LedgerEntry{}, err
+	}
+
+	return d.getJournal("journal_uuid = ?", posting.JournalUuid)
+}
+
+// GetJournalByReference returns the journal entry referring to the given uuid, e.g. the entry of a transfer
+func (d *DatabaseAdapter) GetJournalByReference(referenceUuid uuid.UUID) (LedgerEntry, error) {
+	return d.getJournal("reference_uuid = ?", referenceUuid)
+}
+
+func (d *DatabaseAdapter) getJournal(query string, arg uuid.UUID) (LedgerEntry, error) {
+	var entry LedgerEntry
+
+	if err := d.db.First(&entry.Journal, query, arg).Error; err != nil {
+		return entry, err
+	}
+
+	if err := d.db.Order("created_at, posting_uuid").
+		Find(&entry.Postings, "journal_uuid = ?", entry.Journal.JournalUuid).Error; err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+// PostReversal posts the compensating entry. If a transfer is given, it moves from SETTLED to REVERSED in the same
+// database transaction, failing if it was already reversed
+func (d *DatabaseAdapter) PostReversal(entry LedgerEntry, transfer *BankTransferOrm, reason string) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if transfer != nil {
+			err := updateTransferState(tx, *transfer, bank.TransferStatusSettled, bank.TransferStatusReversed, reason)
+			if errors.Is(err, bank.ErrInvalidTransferTransition) {
+				return fmt.Errorf("transfer %v : %w", transfer.TransferUuid, bank.ErrAlreadyReversed)
+			} else if err != nil {
+				return err
+			}
+		}
+
+		return postLedgerEntry(tx, entry)
+	})
+}
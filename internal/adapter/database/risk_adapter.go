@@ -0,0 +1,97 @@
+package database
+
+import (
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetRiskHistory returns the settled transfers from the account to the counterparty and the statistics of the
+// outgoing transfers and withdrawals since since, one per journal entry so a transfer and its fee count once.
+// Reversals are not outgoing operations
+func (d *DatabaseAdapter) GetRiskHistory(accountNumber string, counterpartyNumber string, since time.Time,
+	recentSince time.Time) (BankRiskHistoryOrm, error) {
+	var historyOrm BankRiskHistoryOrm
+
+	err := d.db.Raw(`
+		WITH acct AS (
+			SELECT account_uuid FROM bank_accounts WHERE account_number = ?
+		), outs AS (
+			SELECT j.created_at, -SUM(p.amount) AS amount
+			FROM ledger_postings p
+			JOIN ledger_journal_entries j ON j.journal_uuid = p.journal_uuid
+			WHERE p.account_uuid = (SELECT account_uuid FROM acct) AND j.entry_type IN (?, ?)
+				AND j.created_at >= ?
+			GROUP BY j.journal_uuid, j.created_at
+			HAVING SUM(p.amount) < 0
+		)
+		SELECT (
+				SELECT COUNT(*) FROM bank_transfers tr
+				JOIN bank_accounts ta ON ta.account_uuid = tr.to_account_uuid
+				WHERE tr.from_account_uuid = (SELECT account_uuid FROM acct) AND ta.account_number = ?
+					AND tr.transfer_status IN (?, ?)
+			) AS transfers_to_counterparty,
+			COUNT(*) AS out_count,
+			COALESCE(AVG(amount), 0) AS out_average,
+			COALESCE(STDDEV_POP(amount), 0) AS out_std_dev,
+			COUNT(*) FILTER (WHERE created_at >= ?) AS recent_out_count
+		FROM outs`,
+		accountNumber, bank.JournalTypeTransfer, bank.JournalTypeWithdrawal, since, counterpartyNumber,
+		bank.TransferStatusSettled, bank.TransferStatusReversed, recentSince).Scan(&historyOrm).Error
+
+	return historyOrm, err
+}
+
+func (d *DatabaseAdapter) CreateRiskAssessment(assessment BankRiskAssessmentOrm) error {
+	return d.db.Create(&assessment).Error
+}
+
+// CreateHeldTransfer records a transfer held for review together with the assessment that held it
+func (d *DatabaseAdapter) CreateHeldTransfer(transfer BankTransferOrm, assessment BankRiskAssessmentOrm) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&transfer).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&assessment).Error
+	})
+}
+
+// GetRiskAssessmentByReference returns the latest assessment of the transfer or transaction
+func (d *DatabaseAdapter) GetRiskAssessmentByReference(referenceUuid uuid.UUID) (BankRiskAssessmentOrm, error) {
+	var assessmentOrm BankRiskAssessmentOrm
+
+	err := d.db.Where("reference_uuid = ?", referenceUuid).Order("created_at DESC").Take(&assessmentOrm).Error
+
+	return assessmentOrm, err
+}
+
+// ReviewRiskAssessment records the decision of the reviewer, an assessment is only reviewed once
+func (d *DatabaseAdapter) ReviewRiskAssessment(assessmentUuid uuid.UUID, reviewer string, decision string,
+	note string) (bool, error) {
+	res := d.db.Model(&BankRiskAssessmentOrm{}).
+		Where("assessment_uuid = ? AND reviewed_at IS NULL", assessmentUuid).
+		Updates(map[string]interface{}{
+			"reviewed_by":     reviewer,
+			"review_decision": decision,
+			"review_note":     note,
+			"reviewed_at":     time.Now(),
+		})
+
+	return res.RowsAffected > 0, res.Error
+}
+
+// GetHeldTransfers returns the transfers waiting for review, oldest first
+func (d *DatabaseAdapter) GetHeldTransfers(limit int) ([]BankTransferDetailOrm, error) {
+	var transferOrms []BankTransferDetailOrm
+
+	err := d.transferDetails().
+		Where("t.transfer_status = ?", bank.TransferStatusHeld).
+		Order("t.created_at").
+		Limit(limit).
+		Find(&transferOrms).Error
+
+	return transferOrms, err
+}
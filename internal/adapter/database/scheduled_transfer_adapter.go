@@ -0,0 +1,106 @@
+package database
+
+import (
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// scheduledTransferLockKey is the postgres advisory lock key held while a replica claims due scheduled transfers
+const scheduledTransferLockKey = 7835001
+
+func (d *DatabaseAdapter) CreateScheduledTransfer(s BankScheduledTransferOrm) (uuid.UUID, error) {
+	if err := d.db.Create(&s).Error; err != nil {
+		return uuid.Nil, err
+	}
+
+	return s.ScheduleUuid, nil
+}
+
+func (d *DatabaseAdapter) GetScheduledTransfer(scheduleUuid uuid.UUID) (BankScheduledTransferOrm, error) {
+	var scheduleOrm BankScheduledTransferOrm
+
+	err := d.db.First(&scheduleOrm, "schedule_uuid = ?", scheduleUuid).Error
+
+	return scheduleOrm, err
+}
+
+func (d *DatabaseAdapter) GetScheduledTransferExecutions(scheduleUuid uuid.UUID) (
+	[]BankScheduledTransferExecutionOrm, error) {
+	var executionOrms []BankScheduledTransferExecutionOrm
+
+	err := d.db.Where("schedule_uuid = ?", scheduleUuid).Order("executed_at").Find(&executionOrms).Error
+
+	return executionOrms, err
+}
+
+func (d *DatabaseAdapter) CancelScheduledTransfer(scheduleUuid uuid.UUID) (bool, error) {
+	res := d.db.Model(&BankScheduledTransferOrm{}).
+		Where("schedule_uuid = ? AND status = ?", scheduleUuid, bank.ScheduleStatusActive).
+		Updates(map[string]interface{}{
+			"status":     bank.ScheduleStatusCancelled,
+			"updated_at": time.Now(),
+		})
+
+	return res.RowsAffected == 1, res.Error
+}
+
+// ClaimDueScheduledTransfers leases up to limit due schedules to the caller until leaseUntil. The advisory lock
+// makes replicas take turns, the lease keeps a schedule from being picked again while it is being executed
+func (d *DatabaseAdapter) ClaimDueScheduledTransfers(now time.Time, leaseUntil time.Time, limit int) (
+	[]BankScheduledTransferOrm, error) {
+	var scheduleOrms []BankScheduledTransferOrm
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", scheduledTransferLockKey).
+			Scan(&locked).Error; err != nil {
+			return err
+		}
+
+		// another replica is claiming right now
+		if !locked {
+			return nil
+		}
+
+		return tx.Raw(`
+			UPDATE bank_scheduled_transfers
+			SET locked_until = ?, updated_at = ?
+			WHERE schedule_uuid IN (
+				SELECT schedule_uuid
+				FROM bank_scheduled_transfers
+				WHERE status = ? AND due_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+				ORDER BY due_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *`,
+			leaseUntil, now, bank.ScheduleStatusActive, now, now, limit).Scan(&scheduleOrms).Error
+	})
+
+	return scheduleOrms, err
+}
+
+// RecordScheduledTransferExecution saves the outcome and the next state of the schedule, releasing its lease
+func (d *DatabaseAdapter) RecordScheduledTransferExecution(s BankScheduledTransferOrm,
+	execution BankScheduledTransferExecutionOrm) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&execution).Error; err != nil {
+			return err
+		}
+
+		// a schedule cancelled while it was running stays cancelled
+		return tx.Model(&s).Where("status = ?", bank.ScheduleStatusActive).Updates(map[string]interface{}{
+			"occurrence_count": s.OccurrenceCount,
+			"next_run_at":      s.NextRunAt,
+			"due_at":           s.DueAt,
+			"attempt":          s.Attempt,
+			"status":           s.Status,
+			"locked_until":     nil,
+			"updated_at":       time.Now(),
+		}).Error
+	})
+}
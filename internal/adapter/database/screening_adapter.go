@@ -0,0 +1,9 @@
+package database
+
+func (d *DatabaseAdapter) CreateScreeningHits(hits []BankScreeningHitOrm) error {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	return d.db.Create(&hits).Error
+}
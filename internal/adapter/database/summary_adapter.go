@@ -0,0 +1,78 @@
+package database
+
+import (
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// upsertDailySummary adds the transaction to the per account per day summary. It must be called with the same
+// gorm transaction that inserts the bank transaction so both are committed or rolled back together
+func upsertDailySummary(tx *gorm.DB, t BankTransactionOrm) error {
+	var sumIn, sumOut float64
+
+	switch t.TransactionType {
+	case bank.TransactionTypeIN:
+		sumIn = t.Amount
+	case bank.TransactionTypeOUT:
+		sumOut = t.Amount
+	}
+
+	now := time.Now()
+
+	return tx.Exec(`
+		INSERT INTO bank_transaction_daily_summaries
+			(account_uuid, summary_date, sum_in, sum_out, transaction_count, created_at, updated_at)
+		VALUES (?, (?::TIMESTAMPTZ AT TIME ZONE 'UTC')::DATE, ?, ?, 1, ?, ?)
+		ON CONFLICT (account_uuid, summary_date) DO UPDATE SET
+			sum_in = bank_transaction_daily_summaries.sum_in + EXCLUDED.sum_in,
+			sum_out = bank_transaction_daily_summaries.sum_out + EXCLUDED.sum_out,
+			transaction_count = bank_transaction_daily_summaries.transaction_count + 1,
+			updated_at = EXCLUDED.updated_at`,
+		t.AccountUuid, t.TransactionTimestamp, sumIn, sumOut, now, now).Error
+}
+
+// GetDailySummaries returns the stored summaries of an account between two dates (both inclusive)
+func (d *DatabaseAdapter) GetDailySummaries(accountUuid uuid.UUID, startDate time.Time,
+	endDate time.Time) ([]BankTransactionDailySummaryOrm, error) {
+	var summaryOrms []BankTransactionDailySummaryOrm
+
+	err := d.db.Where("account_uuid = ? AND summary_date BETWEEN ?::DATE AND ?::DATE", accountUuid,
+		startDate.Format(time.DateOnly), endDate.Format(time.DateOnly)).
+		Order("summary_date").
+		Find(&summaryOrms).Error
+
+	return summaryOrms, err
+}
+
+// RebuildDailySummaries recomputes every summary from bank_transactions
+func (d *DatabaseAdapter) RebuildDailySummaries() (int64, error) {
+	var rows int64
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM bank_transaction_daily_summaries").Error; err != nil {
+			return err
+		}
+
+		res := tx.Exec(`
+			INSERT INTO bank_transaction_daily_summaries
+				(account_uuid, summary_date, sum_in, sum_out, transaction_count, created_at, updated_at)
+			SELECT account_uuid,
+				(transaction_timestamp AT TIME ZONE 'UTC')::DATE,
+				COALESCE(SUM(amount) FILTER (WHERE transaction_type = ?), 0),
+				COALESCE(SUM(amount) FILTER (WHERE transaction_type = ?), 0),
+				COUNT(*),
+				now(),
+				now()
+			FROM bank_transactions
+			GROUP BY 1, 2`,
+			bank.TransactionTypeIN, bank.TransactionTypeOUT)
+
+		rows = res.RowsAffected
+		return res.Error
+	})
+
+	return rows, err
+}
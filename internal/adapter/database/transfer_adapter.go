@@ -0,0 +1,154 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UpdateTransferState moves the transfer to the next status, failing if it is no longer in the from status
+func (d *DatabaseAdapter) UpdateTransferState(transfer BankTransferOrm, from string, to string, reason string,
+	events ...OutboxEventOrm) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateTransferState(tx, transfer, from, to, reason); err != nil {
+			return err
+		}
+
+		return insertOutboxEvents(tx, events)
+	})
+}
+
+// SettleTransfer posts the journal entry of an authorized transfer and settles it in one database transaction, so
+// a transfer is settled if and only if its entry is posted. The limits of the entry are checked in the same
+// transaction
+func (d *DatabaseAdapter) SettleTransfer(transfer BankTransferOrm, entry LedgerEntry) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateTransferState(tx, transfer, bank.TransferStatusAuthorized, bank.TransferStatusSettled,
+			""); err != nil {
+			return err
+		}
+
+		return postLedgerEntry(tx, entry)
+	})
+}
+
+func updateTransferState(tx *gorm.DB, transfer BankTransferOrm, from string, to string, reason string) error {
+	if err := bank.CheckTransferTransition(from, to); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	updates := map[string]interface{}{
+		"transfer_status": to,
+		"updated_at":      now,
+	}
+
+	switch to {
+	case bank.TransferStatusAuthorized:
+		updates["authorized_at"] = now
+	case bank.TransferStatusSettled:
+		updates["settled_at"] = now
+		updates["transfer_success"] = true
+	case bank.TransferStatusFailed:
+		updates["failed_at"] = now
+		updates["failure_reason"] = reason
+	case bank.TransferStatusReversed:
+		updates["reversed"] = true
+		updates["reversed_at"] = now
+		updates["reversal_reason"] = reason
+	}
+
+	res := tx.Model(&transfer).Where("transfer_status = ?", from).Updates(updates)
+
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("transfer %v is no longer %v : %w", transfer.TransferUuid, from,
+			bank.ErrInvalidTransferTransition)
+	}
+
+	return nil
+}
+
+// GetTransfersInProgress returns the transfers still PENDING or AUTHORIZED that were created before the given time
+func (d *DatabaseAdapter) GetTransfersInProgress(before time.Time) ([]BankTransferDetailOrm, error) {
+	var transferOrms []BankTransferDetailOrm
+
+	err := d.transferDetails().
+		Where("t.transfer_status IN ? AND t.created_at < ?",
+			[]string{bank.TransferStatusPending, bank.TransferStatusAuthorized}, before).
+		Order("t.created_at").
+		Find(&transferOrms).Error
+
+	return transferOrms, err
+}
+
+func (d *DatabaseAdapter) transferDetails() *gorm.DB {
+	return d.db.Table("bank_transfers t").
+		Select("t.*, fa.account_number AS from_account_number, ta.account_number AS to_account_number").
+		Joins("JOIN bank_accounts fa ON fa.account_uuid = t.from_account_uuid").
+		Joins("JOIN bank_accounts ta ON ta.account_uuid = t.to_account_uuid")
+}
+
+func (d *DatabaseAdapter) GetTransferDetail(transferUuid uuid.UUID) (BankTransferDetailOrm, error) {
+	var transferOrm BankTransferDetailOrm
+
+	err := d.transferDetails().Where("t.transfer_uuid = ?", transferUuid).Take(&transferOrm).Error
+
+	return transferOrm, err
+}
+
+// ListTransfers returns the transfers sent and / or received by the account. Transfers that never settled are only
+// listed on the sending side
+func (d *DatabaseAdapter) ListTransfers(accountUuid uuid.UUID, filter bank.TransferFilter,
+	cursor *bank.TransactionCursor, limit int) ([]BankTransferDetailOrm, error) {
+	var transferOrms []BankTransferDetailOrm
+
+	received := d.db.Where("t.to_account_uuid = ? AND t.transfer_status IN ?", accountUuid,
+		[]string{bank.TransferStatusSettled, bank.TransferStatusReversed})
+
+	query := d.transferDetails()
+
+	switch filter.Direction {
+	case bank.TransferDirectionSent:
+		query = query.Where("t.from_account_uuid = ?", accountUuid)
+	case bank.TransferDirectionReceived:
+		query = query.Where(received)
+	default:
+		query = query.Where(d.db.Where("t.from_account_uuid = ?", accountUuid).Or(received))
+	}
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("t.transfer_status IN ?", filter.Statuses)
+	}
+
+	if !filter.StartTimestamp.IsZero() {
+		query = query.Where("t.transfer_timestamp >= ?", filter.StartTimestamp)
+	}
+
+	if !filter.EndTimestamp.IsZero() {
+		query = query.Where("t.transfer_timestamp < ?", filter.EndTimestamp)
+	}
+
+	order := "t.transfer_timestamp, t.transfer_uuid"
+	keyset := "(t.transfer_timestamp, t.transfer_uuid) > (?, ?)"
+
+	if filter.SortDescending {
+		order = "t.transfer_timestamp DESC, t.transfer_uuid DESC"
+		keyset = "(t.transfer_timestamp, t.transfer_uuid) < (?, ?)"
+	}
+
+	if cursor != nil {
+		query = query.Where(keyset, cursor.Timestamp, cursor.TransactionUuid)
+	}
+
+	err := query.Order(order).Limit(limit).Find(&transferOrms).Error
+
+	return transferOrms, err
+}
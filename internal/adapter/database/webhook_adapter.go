@@ -0,0 +1,193 @@
+package database
+
+import (
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// webhookDeliveryLockKey is the postgres advisory lock key held while a replica claims due webhook deliveries
+const webhookDeliveryLockKey = 7835003
+
+func (d *DatabaseAdapter) CreateWebhookSubscription(s WebhookSubscriptionOrm) (uuid.UUID, error) {
+	if err := d.db.Create(&s).Error; err != nil {
+		return uuid.Nil, err
+	}
+
+	return s.SubscriptionUuid, nil
+}
+
+func (d *DatabaseAdapter) GetWebhookSubscription(subscriptionUuid uuid.UUID) (WebhookSubscriptionOrm, error) {
+	var subscriptionOrm WebhookSubscriptionOrm
+
+	err := d.db.First(&subscriptionOrm, "subscription_uuid = ?", subscriptionUuid).Error
+
+	return subscriptionOrm, err
+}
+
+func (d *DatabaseAdapter) DisableWebhookSubscription(subscriptionUuid uuid.UUID) (bool, error) {
+	res := d.db.Model(&WebhookSubscriptionOrm{}).
+		Where("subscription_uuid = ? AND status = ?", subscriptionUuid, bank.WebhookStatusActive).
+		Updates(map[string]interface{}{
+			"status":     bank.WebhookStatusDisabled,
+			"updated_at": time.Now(),
+		})
+
+	return res.RowsAffected == 1, res.Error
+}
+
+// GetActiveWebhookSubscriptions returns the active subscriptions to eventType of the account and of every account
+func (d *DatabaseAdapter) GetActiveWebhookSubscriptions(accountNumber string, eventType string) (
+	[]WebhookSubscriptionOrm, error) {
+	var subscriptionOrms []WebhookSubscriptionOrm
+
+	err := d.db.
+		Where("status = ? AND (account_number = ? OR account_number IS NULL)", bank.WebhookStatusActive,
+			accountNumber).
+		Where("',' || event_types || ',' LIKE ?", "%,"+eventType+",%").
+		Find(&subscriptionOrms).Error
+
+	return subscriptionOrms, err
+}
+
+// EnqueueWebhookDeliveries skips the deliveries that already exist, so an event published twice is delivered once
+func (d *DatabaseAdapter) EnqueueWebhookDeliveries(deliveries []WebhookDeliveryOrm) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return d.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// ClaimDueWebhookDeliveries leases up to limit due deliveries to the caller until leaseUntil, the same way
+// scheduled transfers are claimed
+func (d *DatabaseAdapter) ClaimDueWebhookDeliveries(now time.Time, leaseUntil time.Time, limit int) (
+	[]WebhookDeliveryOrm, error) {
+	var deliveryOrms []WebhookDeliveryOrm
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", webhookDeliveryLockKey).
+			Scan(&locked).Error; err != nil {
+			return err
+		}
+
+		// another replica is claiming right now
+		if !locked {
+			return nil
+		}
+
+		return tx.Raw(`
+			UPDATE webhook_deliveries
+			SET locked_until = ?, updated_at = ?
+			WHERE delivery_uuid IN (
+				SELECT delivery_uuid
+				FROM webhook_deliveries
+				WHERE status = ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+				ORDER BY next_attempt_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *`,
+			leaseUntil, now, bank.WebhookDeliveryPending, now, now, limit).Scan(&deliveryOrms).Error
+	})
+
+	return deliveryOrms, err
+}
+
+// RecordWebhookDeliveryAttempt logs the attempt and saves the next state of the delivery, releasing its lease. A
+// delivery that ran out of attempts is also written to the dead letters
+func (d *DatabaseAdapter) RecordWebhookDeliveryAttempt(delivery WebhookDeliveryOrm, attempt WebhookDeliveryAttemptOrm,
+	deadLetter *WebhookDeadLetterOrm) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&delivery).Updates(map[string]interface{}{
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"last_status_code": delivery.LastStatusCode,
+			"last_error":       delivery.LastError,
+			"delivered_at":     delivery.DeliveredAt,
+			"locked_until":     nil,
+			"updated_at":       time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		if deadLetter == nil {
+			return nil
+		}
+
+		// a redelivered dead letter that dies again replaces its earlier row
+		return tx.Exec(`
+			INSERT INTO webhook_dead_letters
+				(delivery_uuid, subscription_uuid, event_type, payload, attempts, last_error, dead_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (delivery_uuid) DO UPDATE SET
+				attempts = EXCLUDED.attempts,
+				last_error = EXCLUDED.last_error,
+				dead_at = EXCLUDED.dead_at,
+				redelivered_at = NULL`,
+			deadLetter.DeliveryUuid, deadLetter.SubscriptionUuid, deadLetter.EventType, deadLetter.Payload,
+			deadLetter.Attempts, deadLetter.LastError, deadLetter.DeadAt).Error
+	})
+}
+
+func (d *DatabaseAdapter) GetWebhookDeliveries(subscriptionUuid uuid.UUID) ([]WebhookDeliveryOrm, error) {
+	var deliveryOrms []WebhookDeliveryOrm
+
+	err := d.db.Where("subscription_uuid = ?", subscriptionUuid).Order("created_at").Find(&deliveryOrms).Error
+
+	return deliveryOrms, err
+}
+
+func (d *DatabaseAdapter) GetWebhookDeliveryAttempts(deliveryUuid uuid.UUID) ([]WebhookDeliveryAttemptOrm, error) {
+	var attemptOrms []WebhookDeliveryAttemptOrm
+
+	err := d.db.Where("delivery_uuid = ?", deliveryUuid).Order("attempted_at").Find(&attemptOrms).Error
+
+	return attemptOrms, err
+}
+
+func (d *DatabaseAdapter) GetWebhookDeadLetters(subscriptionUuid uuid.UUID) ([]WebhookDeadLetterOrm, error) {
+	var deadLetterOrms []WebhookDeadLetterOrm
+
+	err := d.db.Where("subscription_uuid = ? AND redelivered_at IS NULL", subscriptionUuid).
+		Order("dead_at").Find(&deadLetterOrms).Error
+
+	return deadLetterOrms, err
+}
+
+// RedeliverWebhookDeadLetter puts a dead delivery back in the queue with a fresh set of attempts
+func (d *DatabaseAdapter) RedeliverWebhookDeadLetter(deliveryUuid uuid.UUID, now time.Time) (bool, error) {
+	redelivered := false
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&WebhookDeliveryOrm{}).
+			Where("delivery_uuid = ? AND status = ?", deliveryUuid, bank.WebhookDeliveryDead).
+			Updates(map[string]interface{}{
+				"status":          bank.WebhookDeliveryPending,
+				"attempts":        0,
+				"next_attempt_at": now,
+				"updated_at":      now,
+			})
+
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		redelivered = true
+
+		return tx.Model(&WebhookDeadLetterOrm{}).Where("delivery_uuid = ?", deliveryUuid).
+			Update("redelivered_at", now).Error
+	})
+
+	return redelivered, err
+}
@@ -0,0 +1,95 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+// Broker is the part of a NATS or Kafka client the sink needs. Messages with the same key must keep their order,
+// e.g. by using the key as the Kafka partition key
+type Broker interface {
+	Publish(ctx context.Context, topic string, key string, value []byte, headers map[string]string) error
+}
+
+// BrokerSink publishes every event to the topic of its aggregate type, keyed by the aggregate id
+type BrokerSink struct {
+	broker      Broker
+	topicPrefix string
+}
+
+func NewBrokerSink(broker Broker, topicPrefix string) *BrokerSink {
+	return &BrokerSink{
+		broker:      broker,
+		topicPrefix: topicPrefix,
+	}
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, e bank.Event) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return s.broker.Publish(ctx, s.topicPrefix+e.AggregateType, e.AggregateId, value, map[string]string{
+		"event_id":      e.EventUuid.String(),
+		"event_type":    e.EventType,
+		"event_version": strconv.Itoa(e.EventVersion),
+	})
+}
+
+type BrokerMessage struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+// MemoryBroker keeps the published messages in memory, it stands in for a real broker in tests and local runs
+type MemoryBroker struct {
+	mu       sync.Mutex
+	messages []BrokerMessage
+	err      error
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, key string, value []byte,
+	headers map[string]string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+
+	b.messages = append(b.messages, BrokerMessage{
+		Topic:   topic,
+		Key:     key,
+		Value:   value,
+		Headers: headers,
+	})
+
+	return nil
+}
+
+// FailWith makes every publish fail with err until it is called with nil
+func (b *MemoryBroker) FailWith(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.err = err
+}
+
+// Messages returns the messages published so far, in publish order
+func (b *MemoryBroker) Messages() []BrokerMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]BrokerMessage(nil), b.messages...)
+}
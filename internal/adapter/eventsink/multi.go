@@ -0,0 +1,30 @@
+package eventsink
+
+import (
+	"context"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+)
+
+// MultiSink publishes every event to all its sinks. When one fails the event is retried on all of them, so the
+// sinks must accept duplicates
+type MultiSink struct {
+	sinks []port.EventSinkPort
+}
+
+func NewMultiSink(sinks ...port.EventSinkPort) *MultiSink {
+	return &MultiSink{
+		sinks: sinks,
+	}
+}
+
+func (s *MultiSink) Publish(ctx context.Context, e bank.Event) error {
+	for _, sink := range s.sinks {
+		if err := sink.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+// WriterSink writes one JSON event per line
+type WriterSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+func (s *WriterSink) Publish(ctx context.Context, e bank.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enc.Encode(e); err != nil {
+		return err
+	}
+
+	// a file sink only accepts the event once it is on disk
+	if f, ok := s.w.(*os.File); ok && f != os.Stdout {
+		return f.Sync()
+	}
+
+	return nil
+}
+
+// NewFileSink appends the events to the file, the caller closes the returned file
+func NewFileSink(path string) (*WriterSink, io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return NewWriterSink(f), f, nil
+}
@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/my-grpc-proto/protogen/go/bank"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (g *GrpcAdapter) CreateAccount(ctx context.Context, req *bank.CreateAccountRequest) (*bank.CreateAccountResponse, error) {
+	if err := g.validateCurrencies(requestField{field: "currency", value: req.Currency}); err != nil {
+		return nil, err
+	}
+
+	na := dbank.NewAccount{
+		AccountName:          req.AccountName,
+		Currency:             req.Currency,
+		InitialDepositAmount: req.InitialDepositAmount,
+	}
+
+	account, err := g.accountService.CreateAccount(na)
+
+	entry := dbank.AuditEntry{
+		Action:     dbank.AuditActionAccountCreate,
+		EntityType: dbank.AuditEntityAccount,
+		After:      na,
+		Err:        err,
+	}
+
+	if err == nil {
+		entry.EntityIds = []string{account.AccountUuid.String(), account.AccountNumber}
+		entry.After = account
+	}
+
+	g.audit(ctx, entry)
+
+	if err != nil {
+		return nil, buildAccountErrorStatusGrpc(err)
+	}
+
+	return &bank.CreateAccountResponse{
+		AccountUuid: account.AccountUuid.String(),
+	}, nil
+}
+
+func buildAccountErrorStatusGrpc(err error) error {
+	var field string
+
+	switch {
+	case errors.Is(err, dbank.ErrInvalidAccountName):
+		field = "account_name"
+	case errors.Is(err, dbank.ErrUnknownCurrency), errors.Is(err, dbank.ErrCurrencyDisabled):
+		field = "currency"
+	case errors.Is(err, dbank.ErrInvalidInitialDeposit):
+		field = "initial_deposit_amount"
+	case errors.Is(err, dbank.ErrAccountNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, dbank.ErrScreeningBlocked):
+		return buildScreeningBlockedErrorGrpc(err)
+	case errors.Is(err, dbank.ErrScreeningUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	s := status.New(codes.InvalidArgument, err.Error())
+	s, _ = s.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{
+				Field:       field,
+				Description: err.Error(),
+			},
+		},
+	})
+
+	return s.Err()
+}
+
+func buildAccountStatusErrorGrpc(err error, accountNumber string) error {
+	s := status.New(codes.FailedPrecondition, err.Error())
+	s, _ = s.WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{
+			{
+				Type:        "ACCOUNT_STATUS",
+				Subject:     accountNumber,
+				Description: err.Error(),
+			},
+		},
+	})
+
+	return s.Err()
+}
@@ -0,0 +1,160 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the account service, accounts are opened with BankService.CreateAccount :
+//
+//	GetAccount      {account_number | account_uuid}  -> account
+//	RenameAccount   {account_number, account_name}   -> account
+//	FreezeAccount   {account_number}                 -> account
+//	UnfreezeAccount {account_number}                 -> account
+//	CloseAccount    {account_number}                 -> account
+//
+// only an account with a zero balance can be closed
+const accountServiceName = "bank.AccountService"
+
+type accountServer interface {
+	GetAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	RenameAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	FreezeAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	UnfreezeAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	CloseAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+var accountServiceDesc = grpc.ServiceDesc{
+	ServiceName: accountServiceName,
+	HandlerType: (*accountServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryStructMethod(accountServiceName, "GetAccount", accountServer.GetAccount),
+		unaryStructMethod(accountServiceName, "RenameAccount", accountServer.RenameAccount),
+		unaryStructMethod(accountServiceName, "FreezeAccount", accountServer.FreezeAccount),
+		unaryStructMethod(accountServiceName, "UnfreezeAccount", accountServer.UnfreezeAccount),
+		unaryStructMethod(accountServiceName, "CloseAccount", accountServer.CloseAccount),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func (g *GrpcAdapter) GetAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	var account dbank.Account
+	var err error
+
+	if v := structString(req, "account_uuid"); v != "" {
+		accountUuid, parseErr := uuid.Parse(v)
+		if parseErr != nil {
+			return nil, buildBadRequestGrpc("account_uuid", parseErr.Error())
+		}
+
+		account, err = g.accountService.FindAccountByUuid(accountUuid)
+	} else {
+		accountNumber, validateErr := g.requestAccountNumber(req)
+		if validateErr != nil {
+			return nil, validateErr
+		}
+
+		account, err = g.accountService.FindAccountByNumber(accountNumber)
+	}
+
+	if err != nil {
+		return nil, buildAccountChangeErrorStatusGrpc(err, "")
+	}
+
+	return structpb.NewStruct(accountValue(account))
+}
+
+func (g *GrpcAdapter) RenameAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	return g.changeAccount(ctx, req, dbank.AuditActionAccountRename,
+		func(accountNumber string) (dbank.Account, error) {
+			return g.accountService.RenameAccount(accountNumber, structString(req, "account_name"))
+		})
+}
+
+func (g *GrpcAdapter) FreezeAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	return g.changeAccount(ctx, req, dbank.AuditActionAccountFreeze, g.accountService.FreezeAccount)
+}
+
+func (g *GrpcAdapter) UnfreezeAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	return g.changeAccount(ctx, req, dbank.AuditActionAccountUnfreeze, g.accountService.UnfreezeAccount)
+}
+
+func (g *GrpcAdapter) CloseAccount(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	return g.changeAccount(ctx, req, dbank.AuditActionAccountClose, g.accountService.CloseAccount)
+}
+
+// changeAccount applies the change to the account of the request and records it in the audit log
+func (g *GrpcAdapter) changeAccount(ctx context.Context, req *structpb.Struct, action string,
+	change func(accountNumber string) (dbank.Account, error)) (*structpb.Struct, error) {
+	accountNumber, err := g.requestAccountNumber(req)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := g.accountService.FindAccountByNumber(accountNumber)
+	if err != nil {
+		return nil, buildAccountChangeErrorStatusGrpc(err, accountNumber)
+	}
+
+	account, err := change(accountNumber)
+
+	g.audit(ctx, dbank.AuditEntry{
+		Action:     action,
+		EntityType: dbank.AuditEntityAccount,
+		EntityIds:  []string{before.AccountUuid.String(), before.AccountNumber},
+		Before:     before,
+		After:      account,
+		Err:        err,
+	})
+
+	if err != nil {
+		return nil, buildAccountChangeErrorStatusGrpc(err, accountNumber)
+	}
+
+	return structpb.NewStruct(accountValue(account))
+}
+
+func (g *GrpcAdapter) requestAccountNumber(req *structpb.Struct) (string, error) {
+	accountNumber := structString(req, "account_number")
+
+	if err := g.validateAccountNumbers(requestField{field: "account_number", value: accountNumber}); err != nil {
+		return "", err
+	}
+
+	return accountNumber, nil
+}
+
+func buildAccountChangeErrorStatusGrpc(err error, accountNumber string) error {
+	switch {
+	case errors.Is(err, dbank.ErrAccountFrozen), errors.Is(err, dbank.ErrAccountClosed),
+		errors.Is(err, dbank.ErrAccountBalanceNotZero), errors.Is(err, dbank.ErrInvalidAccountStatusChange):
+		return buildAccountStatusErrorGrpc(err, accountNumber)
+	default:
+		return buildAccountErrorStatusGrpc(err)
+	}
+}
+
+func accountValue(a dbank.Account) map[string]interface{} {
+	v := map[string]interface{}{
+		"account_uuid":    a.AccountUuid.String(),
+		"account_number":  a.AccountNumber,
+		"account_name":    a.AccountName,
+		"currency":        a.Currency,
+		"current_balance": a.CurrentBalance,
+		"overdraft_limit": a.OverdraftLimit,
+		"status":          a.Status,
+		"created_at":      a.CreatedAt.Format(time.RFC3339),
+	}
+
+	if a.ClosedAt != nil {
+		v["closed_at"] = a.ClosedAt.Format(time.RFC3339Nano)
+	}
+
+	return v
+}
@@ -0,0 +1,141 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the account activity service :
+//
+//	WatchAccount {account_number, cursor} -> stream {cursor, event_uuid, event_type, created_at, transaction | balance}
+//
+// event_type is transaction.created or account.balance_changed. The stream stays open until the client leaves, a
+// client reconnecting with the cursor of the last item it got first gets what it missed. The header is sent with
+// stream-open once the request is accepted
+const activityServiceName = "bank.ActivityService"
+
+// the header a feed sends before its first item, the stream fails without it
+const streamOpenHeader = "stream-open"
+
+type activityServer interface {
+	WatchAccount(req *structpb.Struct, stream grpc.ServerStream) error
+}
+
+var activityServiceDesc = grpc.ServiceDesc{
+	ServiceName: activityServiceName,
+	HandlerType: (*activityServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		serverStreamStructMethod("WatchAccount", activityServer.WatchAccount),
+	},
+}
+
+// WithActivityService serves the account activity feed next to the bank service
+func (g *GrpcAdapter) WithActivityService(a port.AccountActivityServicePort) *GrpcAdapter {
+	g.activityService = a
+	return g
+}
+
+func (g *GrpcAdapter) WatchAccount(req *structpb.Struct, stream grpc.ServerStream) error {
+	accountNumber, err := g.requestAccountNumber(req)
+	if err != nil {
+		return err
+	}
+
+	cursor := structString(req, "cursor")
+
+	if cursor != "" {
+		if _, err := dbank.DecodeActivityCursor(cursor); err != nil {
+			return buildActivityErrorStatusGrpc(err)
+		}
+	}
+
+	if _, err := g.accountService.FindAccountByNumber(accountNumber); err != nil {
+		return buildActivityErrorStatusGrpc(err)
+	}
+
+	// the gateway starts its event stream on the header, a quiet account sends nothing else for a while
+	if err := stream.SendHeader(metadata.Pairs(streamOpenHeader, "true")); err != nil {
+		return err
+	}
+
+	err = g.activityService.WatchAccount(stream.Context(), accountNumber, cursor,
+		func(activity dbank.AccountActivity) error {
+			v, err := structpb.NewStruct(activityValue(activity))
+			if err != nil {
+				return err
+			}
+
+			return stream.SendMsg(v)
+		})
+
+	return buildActivityErrorStatusGrpc(err)
+}
+
+func buildActivityErrorStatusGrpc(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case status.Code(err) != codes.Unknown:
+		// sending on the stream failed
+		return err
+	case errors.Is(err, dbank.ErrInvalidActivityCursor):
+		return buildBadRequestGrpc("cursor", err.Error())
+	case errors.Is(err, dbank.ErrActivityFeedLagging):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, dbank.ErrActivityFeedNotRunning):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return buildErrorStatusGrpc(err)
+	}
+}
+
+func activityValue(a dbank.AccountActivity) map[string]interface{} {
+	v := map[string]interface{}{
+		"cursor":     a.Cursor,
+		"event_uuid": a.EventUuid.String(),
+		"event_type": a.EventType,
+		"created_at": a.CreatedAt.Format(time.RFC3339Nano),
+	}
+
+	if t := a.Transaction; t != nil {
+		transaction := map[string]interface{}{
+			"transaction_uuid":      t.TransactionUuid.String(),
+			"account_number":        t.AccountNumber,
+			"currency":              t.Currency,
+			"transaction_type":      t.TransactionType,
+			"amount":                t.Amount,
+			"notes":                 t.Notes,
+			"transaction_timestamp": t.Timestamp.Format(time.RFC3339Nano),
+		}
+
+		if t.ReversalOf != nil {
+			transaction["reversal_of"] = t.ReversalOf.String()
+		}
+
+		v["transaction"] = transaction
+	}
+
+	if b := a.Balance; b != nil {
+		v["balance"] = map[string]interface{}{
+			"account_number": b.AccountNumber,
+			"currency":       b.Currency,
+			"balance":        b.Balance,
+			"journal_uuid":   b.JournalUuid.String(),
+			"timestamp":      b.Timestamp.Format(time.RFC3339Nano),
+		}
+	}
+
+	return v
+}
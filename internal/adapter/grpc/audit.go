@@ -0,0 +1,137 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// the actor is authenticated by a bearer token in the authorization metadata, the server keeps the sha256 of each
+// token with its actor and refuses unknown tokens. Without a token the x-user-id metadata is whoever the caller
+// claims to be : it is recorded as unverified, can't review or reverse and isn't kept as the requester of a transfer.
+// Requests without either are anonymous. A request id sent by the client is kept so its own logs can be matched,
+// otherwise one is generated and returned in the header
+const (
+	authorizationMetadataKey = "authorization"
+	actorMetadataKey         = "x-user-id"
+	requestIdMetadataKey     = "x-request-id"
+)
+
+const bearerPrefix = "Bearer "
+
+// WithAuditService records the state-changing RPCs in the audit log and serves the admin audit service
+func (g *GrpcAdapter) WithAuditService(a port.AuditServicePort) *GrpcAdapter {
+	g.auditService = a
+	return g
+}
+
+// WithActorTokens authenticates the callers sending a bearer token, tokens maps the hex sha256 of each token to its
+// actor. Without it every actor is unverified
+func (g *GrpcAdapter) WithActorTokens(tokens map[string]string) *GrpcAdapter {
+	g.actorTokens = tokens
+	return g
+}
+
+func (g *GrpcAdapter) audit(ctx context.Context, entry dbank.AuditEntry) {
+	if g.auditService != nil {
+		g.auditService.Record(ctx, entry)
+	}
+}
+
+func (g *GrpcAdapter) auditContext(ctx context.Context, method string) (context.Context, error) {
+	ac := dbank.AuditContext{
+		Actor:     dbank.AuditActorAnonymous,
+		Method:    method,
+		RequestId: uuid.NewString(),
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(requestIdMetadataKey); len(v) > 0 && v[0] != "" {
+			ac.RequestId = v[0]
+		}
+
+		if v := md.Get(authorizationMetadataKey); len(v) > 0 && v[0] != "" {
+			actor, err := g.authenticate(v[0])
+			if err != nil {
+				return ctx, err
+			}
+
+			ac.Actor = actor
+		} else if v := md.Get(actorMetadataKey); len(v) > 0 && v[0] != "" {
+			ac.Actor = v[0]
+			ac.Unverified = true
+		}
+	}
+
+	return dbank.WithAuditContext(ctx, ac), nil
+}
+
+// authenticate returns the actor of the bearer token, the tokens are compared by their hash
+func (g *GrpcAdapter) authenticate(authorization string) (string, error) {
+	if !strings.HasPrefix(authorization, bearerPrefix) {
+		return "", status.Errorf(codes.Unauthenticated, "the %v metadata must be a bearer token",
+			authorizationMetadataKey)
+	}
+
+	sum := sha256.Sum256([]byte(strings.TrimPrefix(authorization, bearerPrefix)))
+
+	actor, ok := g.actorTokens[hex.EncodeToString(sum[:])]
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "unknown token")
+	}
+
+	return actor, nil
+}
+
+// verifiedActor returns the actor of the call if a token authenticated it
+func verifiedActor(ctx context.Context) (string, bool) {
+	ac := dbank.AuditContextFrom(ctx)
+	if ac.Actor == dbank.AuditActorAnonymous || ac.Unverified {
+		return "", false
+	}
+
+	return ac.Actor, true
+}
+
+func (g *GrpcAdapter) auditUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := g.auditContext(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs(requestIdMetadataKey, dbank.AuditContextFrom(ctx).RequestId))
+
+	return handler(ctx, req)
+}
+
+func (g *GrpcAdapter) auditStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	ctx, err := g.auditContext(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	ss.SetHeader(metadata.Pairs(requestIdMetadataKey, dbank.AuditContextFrom(ctx).RequestId))
+
+	return handler(srv, &auditServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// auditServerStream replaces the stream context with the one carrying the audit context
+type auditServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *auditServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the audit service for compliance, times are RFC 3339 :
+//
+//	ListRecords {actor, method, request_id, action, entity_id, start, end, page_size, page_token}
+//	            -> {records: [record], next_page_token}
+//	VerifyChain {} -> {records, valid, broken_at, reason}
+//
+// records are in sequence order, next_page_token is empty on the last page. Actors the caller only claimed are
+// recorded as unverified:<actor>
+const auditServiceName = "bank.admin.AuditService"
+
+type auditServer interface {
+	ListRecords(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	VerifyChain(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+var auditServiceDesc = grpc.ServiceDesc{
+	ServiceName: auditServiceName,
+	HandlerType: (*auditServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryStructMethod(auditServiceName, "ListRecords", auditServer.ListRecords),
+		unaryStructMethod(auditServiceName, "VerifyChain", auditServer.VerifyChain),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func (g *GrpcAdapter) ListRecords(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	filter := dbank.AuditFilter{
+		Actor:     structString(req, "actor"),
+		Method:    structString(req, "method"),
+		RequestId: structString(req, "request_id"),
+		Action:    structString(req, "action"),
+		EntityId:  structString(req, "entity_id"),
+		PageToken: structString(req, "page_token"),
+	}
+
+	var err error
+
+	if filter.StartTimestamp, err = structTime(req, "start"); err != nil {
+		return nil, err
+	}
+
+	if filter.EndTimestamp, err = structTime(req, "end"); err != nil {
+		return nil, err
+	}
+
+	if filter.PageSize, err = structInt(req, "page_size"); err != nil {
+		return nil, err
+	}
+
+	page, err := g.auditService.FindAuditRecords(filter)
+	if err != nil {
+		if errors.Is(err, dbank.ErrInvalidAuditFilter) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, buildErrorStatusGrpc(err)
+	}
+
+	records := make([]interface{}, 0, len(page.Records))
+	for _, r := range page.Records {
+		entityIds := make([]interface{}, 0, len(r.EntityIds))
+		for _, id := range r.EntityIds {
+			entityIds = append(entityIds, id)
+		}
+
+		records = append(records, map[string]interface{}{
+			"sequence":    r.Sequence,
+			"audit_uuid":  r.AuditUuid.String(),
+			"actor":       r.Actor,
+			"method":      r.Method,
+			"request_id":  r.RequestId,
+			"action":      r.Action,
+			"entity_type": r.EntityType,
+			"entity_ids":  entityIds,
+			"before":      string(r.Before),
+			"after":       string(r.After),
+			"outcome":     r.Outcome,
+			"error":       r.Error,
+			"created_at":  r.CreatedAt.Format(time.RFC3339Nano),
+			"prev_hash":   r.PrevHash,
+			"hash":        r.Hash,
+		})
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"records":         records,
+		"next_page_token": page.NextPageToken,
+	})
+}
+
+func (g *GrpcAdapter) VerifyChain(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	check, err := g.auditService.VerifyAuditChain()
+	if err != nil {
+		return nil, buildErrorStatusGrpc(err)
+	}
+
+	v := map[string]interface{}{
+		"records": check.Records,
+		"valid":   check.Valid,
+		"reason":  check.Reason,
+	}
+
+	if check.BrokenAt != nil {
+		v["broken_at"] = *check.BrokenAt
+	}
+
+	return structpb.NewStruct(v)
+}
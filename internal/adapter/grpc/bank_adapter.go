@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
@@ -20,6 +23,10 @@ import (
 )
 
 func (g *GrpcAdapter) GetCurrentBalance(ctx context.Context, req *bank.CurrentBalanceRequest) (*bank.CurrentBalanceResponse, error) {
+	if err := g.validateAccountNumbers(requestField{field: "account_number", value: req.AccountNumber}); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	balance, err := g.bankService.FindCurrentBalance(req.AccountNumber)
 
@@ -44,6 +51,13 @@ func (g *GrpcAdapter) FetchExchangeRates(req *bank.ExchangeRateRequest,
 	stream bank.BankService_FetchExchangeRatesServer) error {
 	context := stream.Context()
 
+	if err := g.validateCurrencies(
+		requestField{field: "from_currency", value: req.FromCurrency},
+		requestField{field: "to_currency", value: req.ToCurrency},
+	); err != nil {
+		return err
+	}
+
 	for {
 		select {
 		case <-context.Done():
@@ -52,7 +66,12 @@ func (g *GrpcAdapter) FetchExchangeRates(req *bank.ExchangeRateRequest,
 		default:
 
 			now := time.Now().Truncate(time.Second)
-			rate, err := g.bankService.FindExchangeRate(req.FromCurrency, req.ToCurrency, now)
+			conversion, err := g.bankService.ConvertCurrency(req.FromCurrency, req.ToCurrency, 1, now)
+
+			if err != nil && !errors.Is(err, dbank.ErrExchangeRateNotFound) {
+				return status.Errorf(codes.Internal, "can't convert %v to %v : %v", req.FromCurrency,
+					req.ToCurrency, err)
+			}
 
 			if err != nil {
 				s := status.New(codes.InvalidArgument,
@@ -73,12 +92,13 @@ func (g *GrpcAdapter) FetchExchangeRates(req *bank.ExchangeRateRequest,
 				&bank.ExchangeRateResponse{
 					FromCurrency: req.FromCurrency,
 					ToCurrency:   req.ToCurrency,
-					Rate:         rate,
+					Rate:         conversion.Rate,
 					Timestamp:    now.Format(time.RFC3339),
 				},
 			)
 
-			log.Printf("exchange rate sent to client %v to %v : %v\n", req.FromCurrency, req.ToCurrency, rate)
+			log.Printf("exchange rate sent to client %v to %v : %v (%v)\n", req.FromCurrency, req.ToCurrency,
+				conversion.Rate, conversion.Path)
 
 			time.Sleep(3 * time.Second) // give 3 seconds delay before going to next loop
 		}
@@ -120,6 +140,16 @@ func (g *GrpcAdapter) SummarizeTransactions(stream bank.BankService_SummarizeTra
 		req, err := stream.Recv()
 
 		if err == io.EOF {
+			// the summary comes from the persisted daily summary, so it also includes transactions made outside
+			// this stream on the same day
+			if acct != "" {
+				if persisted, err := g.bankService.FindTransactionSummary(acct, time.Now()); err == nil {
+					tSum = persisted
+				} else {
+					log.Printf("can't find persisted summary for %v, using stream summary : %v\n", acct, err)
+				}
+			}
+
 			return stream.SendAndClose(
 				&bank.TransactionSummary{
 					AccountNumber: acct,
@@ -139,6 +169,10 @@ func (g *GrpcAdapter) SummarizeTransactions(stream bank.BankService_SummarizeTra
 			log.Fatalln("error while reading from client:", err)
 		}
 
+		if err := g.validateAccountNumbers(requestField{field: "account_number", value: req.AccountNumber}); err != nil {
+			return err
+		}
+
 		acct = req.AccountNumber
 		ts, err := toTime(req.Timestamp)
 		if err != nil {
@@ -159,9 +193,31 @@ func (g *GrpcAdapter) SummarizeTransactions(stream bank.BankService_SummarizeTra
 			TransactionType: ttype,
 		}
 
+		before := g.findBalances(req.AccountNumber)
+
 		accountUuid, err := g.bankService.CreateTransaction(req.AccountNumber, tcur)
 
-		if err != nil && accountUuid == uuid.Nil {
+		g.audit(stream.Context(), dbank.AuditEntry{
+			Action:     dbank.AuditActionTransactionCreate,
+			EntityType: dbank.AuditEntityTransaction,
+			EntityIds:  []string{req.AccountNumber},
+			Before:     before,
+			After: auditTransaction{
+				Transaction: tcur,
+				Balances:    g.findBalances(req.AccountNumber),
+			},
+			Err: err,
+		})
+
+		if errors.Is(err, dbank.ErrLimitExceeded) {
+			return buildLimitExceededErrorGrpc(err)
+		} else if errors.Is(err, dbank.ErrRiskDenied) {
+			return buildRiskDeniedErrorGrpc(err)
+		} else if errors.Is(err, dbank.ErrAccountFrozen) || errors.Is(err, dbank.ErrAccountClosed) {
+			return buildAccountStatusErrorGrpc(err, req.AccountNumber)
+		} else if errors.Is(err, dbank.ErrInvalidAmount) || errors.Is(err, dbank.ErrInvalidAmountPrecision) {
+			return buildBadRequestGrpc("amount", err.Error())
+		} else if err != nil && accountUuid == uuid.Nil {
 			s := status.New(codes.InvalidArgument, err.Error())
 			s, _ = s.WithDetails(&errdetails.BadRequest{
 				FieldViolations: []*errdetails.BadRequest_FieldViolation{
@@ -198,6 +254,11 @@ func (g *GrpcAdapter) SummarizeTransactions(stream bank.BankService_SummarizeTra
 	}
 }
 
+const (
+	transferUuidTrailer   = "transfer-uuid"
+	transferStatusTrailer = "transfer-status"
+)
+
 func (g *GrpcAdapter) TransferMultiple(stream bank.BankService_TransferMultipleServer) error {
 	context := stream.Context()
 
@@ -217,33 +278,21 @@ func (g *GrpcAdapter) TransferMultiple(stream bank.BankService_TransferMultipleS
 				log.Fatalln("error while reading from client:", err)
 			}
 
-			tt := dbank.TransferTransaction{
-				FromAccountNumber: req.FromAccountNumber,
-				ToAccountNumber:   req.ToAccountNumber,
-				Currency:          req.Currency,
-				Amount:            req.Amount,
-			}
-
-			_, transferSuccess, err := g.bankService.Transfer(tt)
-			if err != nil {
-				return buildTransferErrorStatusGrpc(err, req)
-			}
+			res, result, err := g.transfer(context, req)
 
-			res := bank.TransferResponse{
-				FromAccountNumber: req.FromAccountNumber,
-				ToAccountNumber:   req.ToAccountNumber,
-				Currency:          req.Currency,
-				Amount:            req.Amount,
-				Timestamp:         currentDatetime(),
+			// TransferResponse has no uuid field, the uuids are sent as trailer values in the order of the requests
+			// so clients can look the transfers up later, with the status of the transfer since the proto status
+			// can't say HELD. TransferService.Transfer returns both with each response
+			if result.TransferUuid != uuid.Nil {
+				stream.SetTrailer(metadata.Pairs(transferUuidTrailer, result.TransferUuid.String(),
+					transferStatusTrailer, transferResultStatus(result)))
 			}
 
-			if transferSuccess {
-				res.Status = bank.TransferStatus_TRANSFER_STATUS_SUCCESS
-			} else {
-				res.Status = bank.TransferStatus_TRANSFER_STATUS_FAILED
+			if err != nil {
+				return err
 			}
 
-			err = stream.Send(&res)
+			err = stream.Send(res)
 			if err != nil {
 				log.Fatalf("error while sending response to client %v", err)
 			}
@@ -252,6 +301,102 @@ func (g *GrpcAdapter) TransferMultiple(stream bank.BankService_TransferMultipleS
 	}
 }
 
+// transfer validates and executes one transfer request of a stream and records it in the audit log. The result has
+// the uuid of the transfer once it is recorded, even when the transfer failed
+func (g *GrpcAdapter) transfer(ctx context.Context, req *bank.TransferRequest) (*bank.TransferResponse,
+	dbank.TransferResult, error) {
+	var result dbank.TransferResult
+
+	if err := g.validateAccountNumbers(
+		requestField{field: "from_account_number", value: req.FromAccountNumber},
+		requestField{field: "to_account_number", value: req.ToAccountNumber},
+	); err != nil {
+		return nil, result, err
+	}
+
+	if err := g.validateCurrencies(requestField{field: "currency", value: req.Currency}); err != nil {
+		return nil, result, err
+	}
+
+	tt := dbank.TransferTransaction{
+		FromAccountNumber: req.FromAccountNumber,
+		ToAccountNumber:   req.ToAccountNumber,
+		Currency:          req.Currency,
+		Amount:            req.Amount,
+	}
+
+	// the requester can't review the transfer if it is held, only an authenticated requester is kept so a caller
+	// can't name someone else
+	if actor, ok := verifiedActor(ctx); ok {
+		tt.RequestedBy = actor
+	}
+
+	before := g.findBalances(tt.FromAccountNumber, tt.ToAccountNumber)
+
+	result, err := g.bankService.Transfer(tt)
+
+	entry := dbank.AuditEntry{
+		Action:     dbank.AuditActionTransferExecute,
+		EntityType: dbank.AuditEntityTransfer,
+		EntityIds:  []string{tt.FromAccountNumber, tt.ToAccountNumber},
+		Before:     before,
+		After: auditTransfer{
+			Transfer: tt,
+			Result:   result,
+			Balances: g.findBalances(tt.FromAccountNumber, tt.ToAccountNumber),
+		},
+		Err: err,
+	}
+
+	if result.TransferUuid != uuid.Nil {
+		entry.EntityIds = append([]string{result.TransferUuid.String()}, entry.EntityIds...)
+	}
+
+	g.audit(ctx, entry)
+
+	if err != nil {
+		return nil, result, buildTransferErrorStatusGrpc(err, req)
+	}
+
+	res := bank.TransferResponse{
+		FromAccountNumber: req.FromAccountNumber,
+		ToAccountNumber:   req.ToAccountNumber,
+		Currency:          req.Currency,
+		Amount:            req.Amount,
+		Timestamp:         currentDatetime(),
+	}
+
+	if result.Quote.Fee > 0 {
+		log.Printf("transfer %v charged fee %.2f %v\n", result.TransferUuid, result.Quote.Fee,
+			result.Quote.DebitCurrency)
+	}
+
+	// the proto TransferStatus only has SUCCESS and FAILED. A held transfer neither succeeded nor failed yet, it is
+	// sent as UNSPECIFIED on purpose and the transfer-status trailer says HELD, its uuid is how it is followed up
+	switch transferResultStatus(result) {
+	case dbank.TransferStatusSettled:
+		res.Status = bank.TransferStatus_TRANSFER_STATUS_SUCCESS
+	case dbank.TransferStatusHeld:
+		res.Status = bank.TransferStatus_TRANSFER_STATUS_UNSPECIFIED
+	default:
+		res.Status = bank.TransferStatus_TRANSFER_STATUS_FAILED
+	}
+
+	return &res, result, nil
+}
+
+// transferResultStatus is the status the transfer ended the request in : SETTLED, HELD or FAILED
+func transferResultStatus(result dbank.TransferResult) string {
+	switch {
+	case result.Success:
+		return dbank.TransferStatusSettled
+	case result.Held:
+		return dbank.TransferStatusHeld
+	default:
+		return dbank.TransferStatusFailed
+	}
+}
+
 func currentDatetime() *datetime.DateTime {
 	now := time.Now()
 
@@ -267,8 +412,71 @@ func currentDatetime() *datetime.DateTime {
 	}
 }
 
+type requestField struct {
+	field string
+	value string
+}
+
+// validateCurrencies checks the currencies against the registry and returns an InvalidArgument status with one
+// field violation per invalid currency
+func (g *GrpcAdapter) validateCurrencies(currencies ...requestField) error {
+	return buildFieldViolationsGrpc("invalid currency", g.bankService.ValidateCurrency, currencies...)
+}
+
+// validateAccountNumbers rejects malformed account numbers (length, prefix, check digit) before any database lookup
+func (g *GrpcAdapter) validateAccountNumbers(accountNumbers ...requestField) error {
+	return buildFieldViolationsGrpc("invalid account number", g.accountService.ValidateAccountNumber,
+		accountNumbers...)
+}
+
+func buildFieldViolationsGrpc(msg string, validate func(string) error, fields ...requestField) error {
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	for _, f := range fields {
+		if err := validate(f.value); err != nil {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.field,
+				Description: err.Error(),
+			})
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	s := status.New(codes.InvalidArgument, msg)
+	s, _ = s.WithDetails(&errdetails.BadRequest{
+		FieldViolations: violations,
+	})
+
+	return s.Err()
+}
+
 func buildTransferErrorStatusGrpc(err error, req *bank.TransferRequest) error {
 	switch {
+	case errors.Is(err, dbank.ErrLimitExceeded):
+		return buildLimitExceededErrorGrpc(err)
+	case errors.Is(err, dbank.ErrRiskDenied):
+		return buildRiskDeniedErrorGrpc(err)
+	case errors.Is(err, dbank.ErrScreeningBlocked):
+		return buildScreeningBlockedErrorGrpc(err)
+	case errors.Is(err, dbank.ErrScreeningUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, dbank.ErrUnknownCurrency), errors.Is(err, dbank.ErrCurrencyDisabled):
+		s := status.New(codes.InvalidArgument, err.Error())
+		s, _ = s.WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{
+					Field:       "currency",
+					Description: err.Error(),
+				},
+			},
+		})
+
+		return s.Err()
+	case errors.Is(err, dbank.ErrInvalidAmount), errors.Is(err, dbank.ErrInvalidAmountPrecision):
+		return buildBadRequestGrpc("amount", err.Error())
 	case errors.Is(err, dbank.ErrTransferSourceAccountNotFound):
 		s := status.New(codes.FailedPrecondition, err.Error())
 		s, _ = s.WithDetails(&errdetails.PreconditionFailure{
@@ -294,6 +502,19 @@ func buildTransferErrorStatusGrpc(err error, req *bank.TransferRequest) error {
 			},
 		})
 
+		return s.Err()
+	case errors.Is(err, dbank.ErrAccountFrozen), errors.Is(err, dbank.ErrAccountClosed):
+		s := status.New(codes.FailedPrecondition, err.Error())
+		s, _ = s.WithDetails(&errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{
+				{
+					Type:        "ACCOUNT_STATUS",
+					Subject:     fmt.Sprintf("%v -> %v", req.FromAccountNumber, req.ToAccountNumber),
+					Description: err.Error(),
+				},
+			},
+		})
+
 		return s.Err()
 	case errors.Is(err, dbank.ErrTransferRecordFailed):
 		s := status.New(codes.Internal, err.Error())
@@ -326,3 +547,103 @@ func buildTransferErrorStatusGrpc(err error, req *bank.TransferRequest) error {
 		return s.Err()
 	}
 }
+
+// buildLimitExceededErrorGrpc has one quota violation per limit hit, the subject is the account and the limit
+func buildLimitExceededErrorGrpc(err error) error {
+	s := status.New(codes.FailedPrecondition, err.Error())
+
+	var limitErr *dbank.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		return s.Err()
+	}
+
+	violations := make([]*errdetails.QuotaFailure_Violation, 0, len(limitErr.Violations))
+	for _, v := range limitErr.Violations {
+		violations = append(violations, &errdetails.QuotaFailure_Violation{
+			Subject:     fmt.Sprintf("account:%v/%v", limitErr.AccountNumber, v.Limit),
+			Description: v.Description,
+		})
+	}
+
+	s, _ = s.WithDetails(&errdetails.QuotaFailure{
+		Violations: violations,
+	})
+
+	return s.Err()
+}
+
+// buildRiskDeniedErrorGrpc tells the score and the rules that fired, not the thresholds
+func buildRiskDeniedErrorGrpc(err error) error {
+	s := status.New(codes.PermissionDenied, err.Error())
+
+	var riskErr *dbank.RiskDeniedError
+	if !errors.As(err, &riskErr) {
+		return s.Err()
+	}
+
+	s, _ = s.WithDetails(&errdetails.ErrorInfo{
+		Domain: "my-bank-website.com",
+		Reason: "RISK_DENIED",
+		Metadata: map[string]string{
+			"decision": riskErr.Assessment.Decision,
+			"score":    strconv.Itoa(riskErr.Assessment.Score),
+			"rules":    strings.Join(riskErr.Assessment.Rules(), ","),
+		},
+	})
+
+	return s.Err()
+}
+
+// buildScreeningBlockedErrorGrpc only tells which parties were blocked, not what they matched on the list
+func buildScreeningBlockedErrorGrpc(err error) error {
+	s := status.New(codes.PermissionDenied, err.Error())
+
+	var screeningErr *dbank.ScreeningBlockedError
+	if !errors.As(err, &screeningErr) {
+		return s.Err()
+	}
+
+	var roles []string
+	for _, h := range screeningErr.Hits {
+		if h.Entry.Action == dbank.ScreeningActionBlock {
+			roles = append(roles, strings.ToLower(h.Party.Role))
+		}
+	}
+
+	s, _ = s.WithDetails(&errdetails.ErrorInfo{
+		Domain: "my-bank-website.com",
+		Reason: "SCREENING_BLOCKED",
+		Metadata: map[string]string{
+			"parties": strings.Join(roles, ","),
+		},
+	})
+
+	return s.Err()
+}
+
+// audit values of transactions and transfers, the balances are read right before and after the operation
+type auditBalances map[string]float64
+
+type auditTransaction struct {
+	Transaction dbank.Transaction
+	Balances    auditBalances
+}
+
+type auditTransfer struct {
+	Transfer dbank.TransferTransaction
+	Result   dbank.TransferResult
+	Balances auditBalances
+}
+
+// findBalances skips the accounts it can't read, the audit record is written either way
+func (g *GrpcAdapter) findBalances(accountNumbers ...string) auditBalances {
+	balances := make(auditBalances)
+
+	for _, accountNumber := range accountNumbers {
+		if balance, err := g.bankService.FindCurrentBalance(accountNumber); err == nil {
+			balances[accountNumber] = balance
+		}
+	}
+
+	return balances
+}
@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the exchange rate service, times are RFC 3339 :
+//
+//	GetExchangeRateHistory {from_currency, to_currency, start, end, page_size, page_token}
+//	                       -> {rates: [rate], next_page_token}
+//	GetExchangeRateCandles {from_currency, to_currency, interval, start, end, page_size, after}
+//	                       -> {candles: [candle], next_after}
+//
+// next_page_token and next_after are empty on the last page
+const exchangeRateServiceName = "bank.ExchangeRateService"
+
+type exchangeRateServer interface {
+	GetExchangeRateHistory(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	GetExchangeRateCandles(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+var exchangeRateServiceDesc = grpc.ServiceDesc{
+	ServiceName: exchangeRateServiceName,
+	HandlerType: (*exchangeRateServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryStructMethod(exchangeRateServiceName, "GetExchangeRateHistory",
+			exchangeRateServer.GetExchangeRateHistory),
+		unaryStructMethod(exchangeRateServiceName, "GetExchangeRateCandles",
+			exchangeRateServer.GetExchangeRateCandles),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func (g *GrpcAdapter) GetExchangeRateHistory(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	fromCur, toCur, start, end, err := exchangeRateRange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize, err := structInt(req, "page_size")
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := g.bankService.FindExchangeRateHistory(fromCur, toCur, start, end, structString(req, "page_token"),
+		pageSize)
+	if err != nil {
+		return nil, buildErrorStatusGrpc(err)
+	}
+
+	rates := make([]interface{}, 0, len(page.Rates))
+	for _, r := range page.Rates {
+		rates = append(rates, map[string]interface{}{
+			"from_currency": r.FromCurrency,
+			"to_currency":   r.ToCurrency,
+			"rate":          r.Rate,
+			"valid_from":    r.ValidFromTimestamp.Format(time.RFC3339Nano),
+			"valid_to":      r.ValidToTimestamp.Format(time.RFC3339Nano),
+		})
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"rates":           rates,
+		"next_page_token": page.NextPageToken,
+	})
+}
+
+func (g *GrpcAdapter) GetExchangeRateCandles(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	fromCur, toCur, start, end, err := exchangeRateRange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := structTime(req, "after")
+	if err != nil {
+		return nil, err
+	}
+
+	interval := structString(req, "interval")
+	if interval == "" {
+		interval = dbank.CandleIntervalHour
+	}
+
+	pageSize, err := structInt(req, "page_size")
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := g.bankService.FindExchangeRateCandles(fromCur, toCur, interval, start, end, after, pageSize)
+	if err != nil {
+		return nil, buildErrorStatusGrpc(err)
+	}
+
+	candles := make([]interface{}, 0, len(page.Candles))
+	for _, c := range page.Candles {
+		candles = append(candles, map[string]interface{}{
+			"bucket_start": c.BucketStart.Format(time.RFC3339),
+			"open":         c.Open,
+			"high":         c.High,
+			"low":          c.Low,
+			"close":        c.Close,
+			"count":        c.Count,
+		})
+	}
+
+	nextAfter := ""
+	if !page.NextPageTimestamp.IsZero() {
+		nextAfter = page.NextPageTimestamp.Format(time.RFC3339Nano)
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"from_currency": fromCur,
+		"to_currency":   toCur,
+		"interval":      interval,
+		"candles":       candles,
+		"next_after":    nextAfter,
+	})
+}
+
+// exchangeRateRange reads the pair and the range, the range is the last day when missing
+func exchangeRateRange(req *structpb.Struct) (string, string, time.Time, time.Time, error) {
+	start, err := structTime(req, "start")
+	if err != nil {
+		return "", "", start, start, err
+	}
+
+	end, err := structTime(req, "end")
+	if err != nil {
+		return "", "", start, end, err
+	}
+
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	if start.IsZero() {
+		start = end.Add(-24 * time.Hour)
+	}
+
+	return structString(req, "from_currency"), structString(req, "to_currency"), start, end, nil
+}
@@ -0,0 +1,151 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the reversal service, the original rows are kept and compensating entries are posted :
+//
+//	ReverseTransaction {transaction_uuid, reason} -> {transaction_uuid, reversal_transaction_uuid}
+//	ReverseTransfer    {transfer_uuid, reason}    -> transfer
+//
+// the reason is required, the operator is the actor of the bearer token of the call and unauthenticated calls are
+// refused
+const reversalServiceName = "bank.admin.ReversalService"
+
+type reversalServer interface {
+	ReverseTransaction(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	ReverseTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+var reversalServiceDesc = grpc.ServiceDesc{
+	ServiceName: reversalServiceName,
+	HandlerType: (*reversalServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryStructMethod(reversalServiceName, "ReverseTransaction", reversalServer.ReverseTransaction),
+		unaryStructMethod(reversalServiceName, "ReverseTransfer", reversalServer.ReverseTransfer),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// WithReversalService serves the admin reversal service next to the bank service
+func (g *GrpcAdapter) WithReversalService(r port.ReversalServicePort) *GrpcAdapter {
+	g.reversalService = r
+	return g
+}
+
+func (g *GrpcAdapter) ReverseTransaction(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	transactionUuid, reason, err := reversalRequest(ctx, req, "transaction_uuid")
+	if err != nil {
+		return nil, err
+	}
+
+	reversalUuid, err := g.reversalService.ReverseTransaction(transactionUuid, reason)
+
+	entry := dbank.AuditEntry{
+		Action:     dbank.AuditActionTransactionReverse,
+		EntityType: dbank.AuditEntityTransaction,
+		EntityIds:  []string{transactionUuid.String()},
+		After: map[string]interface{}{
+			"reason": reason,
+		},
+		Err: err,
+	}
+
+	if err == nil {
+		entry.EntityIds = append(entry.EntityIds, reversalUuid.String())
+		entry.After = map[string]interface{}{
+			"reason":                    reason,
+			"reversal_transaction_uuid": reversalUuid.String(),
+		}
+	}
+
+	g.audit(ctx, entry)
+
+	if err != nil {
+		return nil, buildReversalErrorStatusGrpc(err)
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"transaction_uuid":          transactionUuid.String(),
+		"reversal_transaction_uuid": reversalUuid.String(),
+	})
+}
+
+func (g *GrpcAdapter) ReverseTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	transferUuid, reason, err := reversalRequest(ctx, req, "transfer_uuid")
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := g.bankService.GetTransfer(transferUuid)
+	if err != nil {
+		return nil, buildReversalErrorStatusGrpc(err)
+	}
+
+	err = g.reversalService.ReverseTransfer(transferUuid, reason)
+
+	after, findErr := g.bankService.GetTransfer(transferUuid)
+
+	g.audit(ctx, dbank.AuditEntry{
+		Action:     dbank.AuditActionTransferReverse,
+		EntityType: dbank.AuditEntityTransfer,
+		EntityIds:  []string{transferUuid.String()},
+		Before:     before,
+		After:      after,
+		Err:        err,
+	})
+
+	if err != nil {
+		return nil, buildReversalErrorStatusGrpc(err)
+	}
+
+	if findErr != nil {
+		return nil, status.Errorf(codes.Internal, "can't find transfer %v after reversal : %v", transferUuid, findErr)
+	}
+
+	return structpb.NewStruct(heldTransferValue(after))
+}
+
+// reversalRequest reads the uuid to reverse and the reason, the operator must be known
+func reversalRequest(ctx context.Context, req *structpb.Struct, field string) (uuid.UUID, string, error) {
+	if _, ok := verifiedActor(ctx); !ok {
+		return uuid.Nil, "", status.Errorf(codes.PermissionDenied,
+			"reversals need an operator : send a bearer token in the %v metadata", authorizationMetadataKey)
+	}
+
+	id, err := uuid.Parse(structString(req, field))
+	if err != nil {
+		return uuid.Nil, "", buildBadRequestGrpc(field, err.Error())
+	}
+
+	reason := structString(req, "reason")
+	if reason == "" {
+		return uuid.Nil, "", buildBadRequestGrpc("reason", dbank.ErrReversalReasonRequired.Error())
+	}
+
+	return id, reason, nil
+}
+
+func buildReversalErrorStatusGrpc(err error) error {
+	switch {
+	case errors.Is(err, dbank.ErrReversalReasonRequired):
+		return buildBadRequestGrpc("reason", err.Error())
+	case errors.Is(err, dbank.ErrAlreadyReversed):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, dbank.ErrReverseReversal), errors.Is(err, dbank.ErrReverseTransferLeg),
+		errors.Is(err, dbank.ErrTransferNotSettled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return buildErrorStatusGrpc(err)
+	}
+}
@@ -0,0 +1,251 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the scheduled transfer service, times are RFC 3339 :
+//
+//	ScheduleTransfer        {from_account_number, to_account_number, currency, amount, start_at, recurrence, end_at}
+//	                        -> schedule
+//	CancelScheduledTransfer {schedule_uuid} -> schedule
+//	ListExecutions          {schedule_uuid} -> {schedule, executions: [execution]}
+//
+// recurrence is NONE (default), DAILY, WEEKLY or MONTHLY, start_at defaults to now and end_at is optional
+const scheduledTransferServiceName = "bank.ScheduledTransferService"
+
+type scheduledTransferServer interface {
+	ScheduleTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	CancelScheduledTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	ListExecutions(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+var scheduledTransferServiceDesc = grpc.ServiceDesc{
+	ServiceName: scheduledTransferServiceName,
+	HandlerType: (*scheduledTransferServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryStructMethod(scheduledTransferServiceName, "ScheduleTransfer",
+			scheduledTransferServer.ScheduleTransfer),
+		unaryStructMethod(scheduledTransferServiceName, "CancelScheduledTransfer",
+			scheduledTransferServer.CancelScheduledTransfer),
+		unaryStructMethod(scheduledTransferServiceName, "ListExecutions", scheduledTransferServer.ListExecutions),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// WithScheduledTransferService serves the scheduled transfer service next to the bank service
+func (g *GrpcAdapter) WithScheduledTransferService(s port.ScheduledTransferServicePort) *GrpcAdapter {
+	g.scheduledTransferService = s
+	return g
+}
+
+func (g *GrpcAdapter) ScheduleTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	tt := dbank.TransferTransaction{
+		FromAccountNumber: structString(req, "from_account_number"),
+		ToAccountNumber:   structString(req, "to_account_number"),
+		Currency:          structString(req, "currency"),
+	}
+
+	if actor, ok := verifiedActor(ctx); ok {
+		tt.RequestedBy = actor
+	}
+
+	if err := g.validateAccountNumbers(
+		requestField{field: "from_account_number", value: tt.FromAccountNumber},
+		requestField{field: "to_account_number", value: tt.ToAccountNumber},
+	); err != nil {
+		return nil, err
+	}
+
+	amount, err := structNumber(req, "amount")
+	if err != nil {
+		return nil, err
+	}
+
+	if amount == nil {
+		return nil, buildBadRequestGrpc("amount", "required")
+	}
+
+	tt.Amount = *amount
+
+	startAt, err := structTime(req, "start_at")
+	if err != nil {
+		return nil, err
+	}
+
+	if startAt.IsZero() {
+		startAt = time.Now()
+	}
+
+	var endAt *time.Time
+
+	if t, err := structTime(req, "end_at"); err != nil {
+		return nil, err
+	} else if !t.IsZero() {
+		endAt = &t
+	}
+
+	recurrence := strings.ToUpper(structString(req, "recurrence"))
+	if recurrence == "" {
+		recurrence = dbank.RecurrenceNone
+	}
+
+	schedule, err := g.scheduledTransferService.ScheduleTransfer(tt, startAt, recurrence, endAt)
+
+	entry := dbank.AuditEntry{
+		Action:     dbank.AuditActionScheduleCreate,
+		EntityType: dbank.AuditEntitySchedule,
+		EntityIds:  []string{tt.FromAccountNumber, tt.ToAccountNumber},
+		After:      tt,
+		Err:        err,
+	}
+
+	if err == nil {
+		entry.EntityIds = append([]string{schedule.ScheduleUuid.String()}, entry.EntityIds...)
+		entry.After = schedule
+	}
+
+	g.audit(ctx, entry)
+
+	if err != nil {
+		return nil, buildScheduleErrorStatusGrpc(err)
+	}
+
+	return structpb.NewStruct(scheduleValue(schedule))
+}
+
+func (g *GrpcAdapter) CancelScheduledTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	scheduleUuid, err := requestScheduleUuid(req)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := g.scheduledTransferService.FindScheduledTransfer(scheduleUuid)
+	if err != nil {
+		return nil, buildScheduleErrorStatusGrpc(err)
+	}
+
+	err = g.scheduledTransferService.CancelScheduledTransfer(scheduleUuid)
+
+	after, findErr := g.scheduledTransferService.FindScheduledTransfer(scheduleUuid)
+
+	g.audit(ctx, dbank.AuditEntry{
+		Action:     dbank.AuditActionScheduleCancel,
+		EntityType: dbank.AuditEntitySchedule,
+		EntityIds:  []string{scheduleUuid.String()},
+		Before:     before,
+		After:      after,
+		Err:        err,
+	})
+
+	if err != nil {
+		return nil, buildScheduleErrorStatusGrpc(err)
+	}
+
+	if findErr != nil {
+		return nil, buildScheduleErrorStatusGrpc(findErr)
+	}
+
+	return structpb.NewStruct(scheduleValue(after))
+}
+
+func (g *GrpcAdapter) ListExecutions(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	scheduleUuid, err := requestScheduleUuid(req)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := g.scheduledTransferService.FindScheduledTransfer(scheduleUuid)
+	if err != nil {
+		return nil, buildScheduleErrorStatusGrpc(err)
+	}
+
+	executions, err := g.scheduledTransferService.FindScheduledTransferExecutions(scheduleUuid)
+	if err != nil {
+		return nil, buildScheduleErrorStatusGrpc(err)
+	}
+
+	values := make([]interface{}, 0, len(executions))
+	for _, e := range executions {
+		v := map[string]interface{}{
+			"execution_uuid": e.ExecutionUuid.String(),
+			"scheduled_for":  e.ScheduledFor.Format(time.RFC3339),
+			"executed_at":    e.ExecutedAt.Format(time.RFC3339),
+			"attempt":        e.Attempt,
+			"outcome":        e.Outcome,
+			"reason":         e.Reason,
+		}
+
+		if e.TransferUuid != nil {
+			v["transfer_uuid"] = e.TransferUuid.String()
+		}
+
+		values = append(values, v)
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"schedule":   scheduleValue(schedule),
+		"executions": values,
+	})
+}
+
+func requestScheduleUuid(req *structpb.Struct) (uuid.UUID, error) {
+	scheduleUuid, err := uuid.Parse(structString(req, "schedule_uuid"))
+	if err != nil {
+		return uuid.Nil, buildBadRequestGrpc("schedule_uuid", err.Error())
+	}
+
+	return scheduleUuid, nil
+}
+
+func buildScheduleErrorStatusGrpc(err error) error {
+	switch {
+	case errors.Is(err, dbank.ErrInvalidAmount):
+		return buildBadRequestGrpc("amount", err.Error())
+	case errors.Is(err, dbank.ErrInvalidRecurrence):
+		return buildBadRequestGrpc("recurrence", err.Error())
+	case errors.Is(err, dbank.ErrInvalidTimeRange):
+		return buildBadRequestGrpc("end_at", err.Error())
+	case errors.Is(err, dbank.ErrUnknownCurrency), errors.Is(err, dbank.ErrCurrencyDisabled):
+		return buildBadRequestGrpc("currency", err.Error())
+	case errors.Is(err, dbank.ErrTransferSourceAccountNotFound),
+		errors.Is(err, dbank.ErrTransferDestinationAccountNotFound),
+		errors.Is(err, dbank.ErrScheduledTransferNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, dbank.ErrScheduledTransferNotActive):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return buildErrorStatusGrpc(err)
+	}
+}
+
+func scheduleValue(s dbank.ScheduledTransfer) map[string]interface{} {
+	v := map[string]interface{}{
+		"schedule_uuid":       s.ScheduleUuid.String(),
+		"from_account_number": s.Transfer.FromAccountNumber,
+		"to_account_number":   s.Transfer.ToAccountNumber,
+		"currency":            s.Transfer.Currency,
+		"amount":              s.Transfer.Amount,
+		"recurrence":          s.Recurrence,
+		"start_at":            s.StartAt.Format(time.RFC3339),
+		"next_run_at":         s.NextRunAt.Format(time.RFC3339),
+		"status":              s.Status,
+	}
+
+	if s.EndAt != nil {
+		v["end_at"] = s.EndAt.Format(time.RFC3339)
+	}
+
+	return v
+}
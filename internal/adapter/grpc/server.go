@@ -12,22 +12,41 @@ import (
 )
 
 type GrpcAdapter struct {
-	helloService port.HelloServicePort
-	bankService  port.BankServicePort
-	grpcPort     int
-	server       *grpc.Server
+	helloService             port.HelloServicePort
+	bankService              port.BankServicePort
+	accountService           port.AccountServicePort
+	auditService             port.AuditServicePort
+	scheduledTransferService port.ScheduledTransferServicePort
+	reviewService            port.TransferReviewServicePort
+	reversalService          port.ReversalServicePort
+	webhookService           port.WebhookServicePort
+	activityService          port.AccountActivityServicePort
+	actorTokens              map[string]string
+	grpcPort                 int
+	adminAddress             string
+	server                   *grpc.Server
+	adminServer              *grpc.Server
 	hello.HelloServiceServer
 	bank.BankServiceServer
 }
 
-func NewGrpcAdapter(helloService port.HelloServicePort, bankService port.BankServicePort, grpcPort int) *GrpcAdapter {
+func NewGrpcAdapter(helloService port.HelloServicePort, bankService port.BankServicePort,
+	accountService port.AccountServicePort, grpcPort int) *GrpcAdapter {
 	return &GrpcAdapter{
-		helloService: helloService,
-		bankService:  bankService,
-		grpcPort:     grpcPort,
+		helloService:   helloService,
+		bankService:    bankService,
+		accountService: accountService,
+		grpcPort:       grpcPort,
 	}
 }
 
+// WithAdminAddress serves the bank.admin services (transfer review, reversal, audit and webhooks) on their own
+// address, e.g. 127.0.0.1:9091 so they are kept off the network the clients reach. Without it they are not served
+func (g *GrpcAdapter) WithAdminAddress(adminAddress string) *GrpcAdapter {
+	g.adminAddress = adminAddress
+	return g
+}
+
 func (g *GrpcAdapter) Run() {
 	var err error
 
@@ -38,17 +57,80 @@ func (g *GrpcAdapter) Run() {
 
 	log.Printf("server listening on port %d\n", g.grpcPort)
 
-	grpcServer := grpc.NewServer()
+	grpcServer := g.newGrpcServer()
 	g.server = grpcServer
 
 	hello.RegisterHelloServiceServer(grpcServer, g) // register the hello service server
 	bank.RegisterBankServiceServer(grpcServer, g) // register the bank service server
+	grpcServer.RegisterService(&exchangeRateServiceDesc, g)
+	grpcServer.RegisterService(&accountServiceDesc, g)
+	grpcServer.RegisterService(&transactionServiceDesc, g)
+	grpcServer.RegisterService(&statementServiceDesc, g)
+	grpcServer.RegisterService(&transferServiceDesc, g)
+
+	if g.activityService != nil {
+		grpcServer.RegisterService(&activityServiceDesc, g)
+	}
+
+	if g.scheduledTransferService != nil {
+		grpcServer.RegisterService(&scheduledTransferServiceDesc, g)
+	}
+
+	if g.adminAddress != "" {
+		g.runAdmin()
+	}
 
 	if err = grpcServer.Serve(listen); err != nil {
 		log.Fatalf("failed to serve on port %d : %v\n", g.grpcPort, err)
 	}
 }
 
+// runAdmin serves the bank.admin services in the background, the calls go through the same audit interceptors
+func (g *GrpcAdapter) runAdmin() {
+	listen, err := net.Listen("tcp", g.adminAddress)
+	if err != nil {
+		log.Fatalf("failed to listen on admin address %v : %v\n", g.adminAddress, err)
+	}
+
+	log.Printf("admin server listening on %v\n", g.adminAddress)
+
+	adminServer := g.newGrpcServer()
+	g.adminServer = adminServer
+
+	if g.reviewService != nil {
+		adminServer.RegisterService(&transferReviewServiceDesc, g)
+	}
+
+	if g.auditService != nil {
+		adminServer.RegisterService(&auditServiceDesc, g)
+	}
+
+	if g.reversalService != nil {
+		adminServer.RegisterService(&reversalServiceDesc, g)
+	}
+
+	if g.webhookService != nil {
+		adminServer.RegisterService(&webhookServiceDesc, g)
+	}
+
+	go func() {
+		if err := adminServer.Serve(listen); err != nil {
+			log.Fatalf("failed to serve on admin address %v : %v\n", g.adminAddress, err)
+		}
+	}()
+}
+
+func (g *GrpcAdapter) newGrpcServer() *grpc.Server {
+	return grpc.NewServer(
+		grpc.UnaryInterceptor(g.auditUnaryInterceptor),
+		grpc.StreamInterceptor(g.auditStreamInterceptor),
+	)
+}
+
 func (g *GrpcAdapter) Stop() {
 	g.server.Stop()
+
+	if g.adminServer != nil {
+		g.adminServer.Stop()
+	}
 }
@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/statement"
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// the statement service :
+//
+//	GetStatement {account_number, month, format} -> stream of google.protobuf.BytesValue
+//
+// month is YYYY-MM (default the current month) and format csv or pdf (default pdf). The file comes in chunks of at
+// most 32 KiB, the header metadata has its statement-content-type and statement-file-name
+const statementServiceName = "bank.StatementService"
+
+const (
+	statementContentTypeHeader = "statement-content-type"
+	statementFileNameHeader    = "statement-file-name"
+)
+
+type statementServer interface {
+	GetStatement(req *structpb.Struct, stream grpc.ServerStream) error
+}
+
+var statementServiceDesc = grpc.ServiceDesc{
+	ServiceName: statementServiceName,
+	HandlerType: (*statementServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		serverStreamStructMethod("GetStatement", statementServer.GetStatement),
+	},
+}
+
+func (g *GrpcAdapter) GetStatement(req *structpb.Struct, stream grpc.ServerStream) error {
+	accountNumber, err := g.requestAccountNumber(req)
+	if err != nil {
+		return err
+	}
+
+	month := structString(req, "month")
+	if month == "" {
+		month = time.Now().UTC().Format("2006-01")
+	}
+
+	m, err := time.Parse("2006-01", month)
+	if err != nil {
+		return buildBadRequestGrpc("month", "must be YYYY-MM")
+	}
+
+	format := structString(req, "format")
+	if format == "" {
+		format = dbank.StatementFormatPDF
+	}
+
+	if format != dbank.StatementFormatCSV && format != dbank.StatementFormatPDF {
+		return buildBadRequestGrpc("format", dbank.ErrUnknownStatementFormat.Error())
+	}
+
+	start, end := dbank.MonthPeriod(m.Year(), m.Month())
+
+	stmt, err := g.bankService.GenerateStatement(accountNumber, start, end)
+	if err != nil {
+		return buildErrorStatusGrpc(err)
+	}
+
+	if err := stream.SendHeader(metadata.Pairs(
+		statementContentTypeHeader, statement.ContentType(format),
+		statementFileNameHeader, fmt.Sprintf("statement-%v-%v.%v", accountNumber, month, format),
+	)); err != nil {
+		return err
+	}
+
+	w := statement.NewChunkWriter(statement.DefaultChunkSize, func(chunk []byte) error {
+		return stream.SendMsg(wrapperspb.Bytes(chunk))
+	})
+
+	if err := statement.Render(w, stmt, format); err != nil {
+		return status.Errorf(codes.Internal, "can't render statement : %v", err)
+	}
+
+	return w.Flush()
+}
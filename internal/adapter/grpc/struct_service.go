@@ -0,0 +1,195 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the proto module only declares the bank and hello services, the services added since are declared in this package
+// with hand-written service descriptors. Their requests and responses are google.protobuf.Struct, the fields are
+// listed on each service
+
+// unaryStructMethod declares a unary method of a hand-written service, S is the interface of the service
+func unaryStructMethod[S any](service string, method string,
+	call func(S, context.Context, *structpb.Struct) (*structpb.Struct, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+			interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new(structpb.Struct)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+
+			if interceptor == nil {
+				return call(srv.(S), ctx, req)
+			}
+
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + service + "/" + method,
+			}
+
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(S), ctx, req.(*structpb.Struct))
+			})
+		},
+	}
+}
+
+// serverStreamStructMethod declares a server streaming method of a hand-written service, the request is a Struct and
+// call sends the responses on the stream
+func serverStreamStructMethod[S any](method string,
+	call func(S, *structpb.Struct, grpc.ServerStream) error) grpc.StreamDesc {
+	return grpc.StreamDesc{
+		StreamName: method,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			req := new(structpb.Struct)
+			if err := stream.RecvMsg(req); err != nil {
+				return err
+			}
+
+			return call(srv.(S), req, stream)
+		},
+		ServerStreams: true,
+	}
+}
+
+// bidiStreamStructMethod declares a bidirectional streaming method of a hand-written service, call receives the
+// requests and sends the responses on the stream
+func bidiStreamStructMethod[S any](method string, call func(S, grpc.ServerStream) error) grpc.StreamDesc {
+	return grpc.StreamDesc{
+		StreamName: method,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			return call(srv.(S), stream)
+		},
+		ServerStreams: true,
+		ClientStreams: true,
+	}
+}
+
+func structString(req *structpb.Struct, field string) string {
+	return req.GetFields()[field].GetStringValue()
+}
+
+// structNumber reads a number sent as a JSON number or as a string (e.g. a query parameter of the gateway), a
+// missing field is nil
+func structNumber(req *structpb.Struct, field string) (*float64, error) {
+	v, ok := req.GetFields()[field]
+	if !ok {
+		return nil, nil
+	}
+
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_NumberValue:
+		return &k.NumberValue, nil
+	case *structpb.Value_StringValue:
+		if k.StringValue == "" {
+			return nil, nil
+		}
+
+		n, err := strconv.ParseFloat(k.StringValue, 64)
+		if err != nil {
+			return nil, buildBadRequestGrpc(field, "must be a number")
+		}
+
+		return &n, nil
+	case *structpb.Value_NullValue:
+		return nil, nil
+	default:
+		return nil, buildBadRequestGrpc(field, "must be a number")
+	}
+}
+
+// structInt reads an integer like structNumber, a missing field is 0
+func structInt(req *structpb.Struct, field string) (int, error) {
+	n, err := structNumber(req, field)
+	if err != nil || n == nil {
+		return 0, err
+	}
+
+	if *n != math.Trunc(*n) {
+		return 0, buildBadRequestGrpc(field, "must be an integer")
+	}
+
+	return int(*n), nil
+}
+
+// structTime parses an RFC 3339 field, a missing field is the zero time
+func structTime(req *structpb.Struct, field string) (time.Time, error) {
+	v := structString(req, field)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return t, buildBadRequestGrpc(field, "must be an RFC 3339 time : "+err.Error())
+	}
+
+	return t, nil
+}
+
+func buildBadRequestGrpc(field string, description string) error {
+	s := status.New(codes.InvalidArgument, "invalid "+field)
+	s, _ = s.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{
+				Field:       field,
+				Description: description,
+			},
+		},
+	})
+
+	return s.Err()
+}
+
+var invalidArgumentErrors = []error{
+	dbank.ErrInvalidTimeRange,
+	dbank.ErrInvalidCandleInterval,
+	dbank.ErrInvalidPageToken,
+	dbank.ErrInvalidAmountRange,
+	dbank.ErrInvalidTransactionType,
+	dbank.ErrUnknownCurrency,
+	dbank.ErrCurrencyDisabled,
+	dbank.ErrInvalidAmount,
+	dbank.ErrInvalidAmountPrecision,
+	dbank.ErrInvalidAccountNumber,
+	dbank.ErrInvalidAccountName,
+	dbank.ErrInvalidTransferDirection,
+	dbank.ErrInvalidTransferStatus,
+}
+
+var notFoundErrors = []error{
+	dbank.ErrAccountNotFound,
+	dbank.ErrTransferNotFound,
+	dbank.ErrTransactionNotFound,
+	dbank.ErrExchangeRateNotFound,
+}
+
+// buildErrorStatusGrpc maps the domain errors of the hand-written services to a status, the message is the error
+func buildErrorStatusGrpc(err error) error {
+	for _, e := range invalidArgumentErrors {
+		if errors.Is(err, e) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	for _, e := range notFoundErrors {
+		if errors.Is(err, e) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
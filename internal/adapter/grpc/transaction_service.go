@@ -0,0 +1,95 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the transaction service, times are RFC 3339 :
+//
+//	ListTransactions {account_number, start, end, transaction_type, min_amount, max_amount, notes_contains, sort,
+//	                  page_size, page_token} -> {transactions: [transaction], next_page_token}
+//
+// sort is asc (default) or desc on the transaction time, next_page_token is empty on the last page
+const transactionServiceName = "bank.TransactionService"
+
+type transactionServer interface {
+	ListTransactions(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+var transactionServiceDesc = grpc.ServiceDesc{
+	ServiceName: transactionServiceName,
+	HandlerType: (*transactionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryStructMethod(transactionServiceName, "ListTransactions", transactionServer.ListTransactions),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func (g *GrpcAdapter) ListTransactions(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	accountNumber, err := g.requestAccountNumber(req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := dbank.TransactionFilter{
+		AccountNumber:   accountNumber,
+		TransactionType: strings.ToUpper(structString(req, "transaction_type")),
+		NotesContains:   structString(req, "notes_contains"),
+		PageToken:       structString(req, "page_token"),
+	}
+
+	if filter.StartTimestamp, err = structTime(req, "start"); err != nil {
+		return nil, err
+	}
+
+	if filter.EndTimestamp, err = structTime(req, "end"); err != nil {
+		return nil, err
+	}
+
+	if filter.MinAmount, err = structNumber(req, "min_amount"); err != nil {
+		return nil, err
+	}
+
+	if filter.MaxAmount, err = structNumber(req, "max_amount"); err != nil {
+		return nil, err
+	}
+
+	if filter.PageSize, err = structInt(req, "page_size"); err != nil {
+		return nil, err
+	}
+
+	switch structString(req, "sort") {
+	case "", "asc":
+	case "desc":
+		filter.SortDescending = true
+	default:
+		return nil, buildBadRequestGrpc("sort", "use asc or desc")
+	}
+
+	page, err := g.bankService.ListTransactions(filter)
+	if err != nil {
+		return nil, buildErrorStatusGrpc(err)
+	}
+
+	transactions := make([]interface{}, 0, len(page.Transactions))
+	for _, t := range page.Transactions {
+		transactions = append(transactions, map[string]interface{}{
+			"transaction_uuid":      t.TransactionUuid.String(),
+			"amount":                t.Amount,
+			"transaction_type":      t.TransactionType,
+			"transaction_timestamp": t.Timestamp.Format(time.RFC3339Nano),
+			"notes":                 t.Notes,
+		})
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"transactions":    transactions,
+		"next_page_token": page.NextPageToken,
+	})
+}
@@ -0,0 +1,216 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the transfer review service :
+//
+//	ListHeldTransfers {page_size}               -> {transfers: [transfer]}
+//	ApproveTransfer   {transfer_uuid, note}     -> transfer
+//	RejectTransfer    {transfer_uuid, reason}   -> transfer
+//
+// the reviewer is the actor of the bearer token of the call, unauthenticated calls are refused. Only the configured
+// reviewers can approve or reject and never the transfers they requested. It is served on the admin address
+const transferReviewServiceName = "bank.admin.TransferReviewService"
+
+type transferReviewServer interface {
+	ListHeldTransfers(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	ApproveTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	RejectTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+var transferReviewServiceDesc = grpc.ServiceDesc{
+	ServiceName: transferReviewServiceName,
+	HandlerType: (*transferReviewServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryStructMethod(transferReviewServiceName, "ListHeldTransfers", transferReviewServer.ListHeldTransfers),
+		unaryStructMethod(transferReviewServiceName, "ApproveTransfer", transferReviewServer.ApproveTransfer),
+		unaryStructMethod(transferReviewServiceName, "RejectTransfer", transferReviewServer.RejectTransfer),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// WithTransferReviewService serves the admin transfer review service next to the bank service
+func (g *GrpcAdapter) WithTransferReviewService(r port.TransferReviewServicePort) *GrpcAdapter {
+	g.reviewService = r
+	return g
+}
+
+func (g *GrpcAdapter) ListHeldTransfers(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	if _, err := reviewer(ctx); err != nil {
+		return nil, err
+	}
+
+	held, err := g.reviewService.ListHeldTransfers(int(req.GetFields()["page_size"].GetNumberValue()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	transfers := make([]interface{}, 0, len(held))
+	for _, h := range held {
+		t := heldTransferValue(h.Transfer)
+		t["risk"] = riskAssessmentValue(h.Assessment)
+		transfers = append(transfers, t)
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"transfers": transfers,
+	})
+}
+
+func (g *GrpcAdapter) ApproveTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	actor, err := reviewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transferUuid, err := reviewTransferUuid(req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = g.reviewService.ApproveHeldTransfer(transferUuid, actor, req.GetFields()["note"].GetStringValue())
+
+	return g.reviewResult(ctx, dbank.AuditActionTransferApprove, transferUuid, err)
+}
+
+func (g *GrpcAdapter) RejectTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	actor, err := reviewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transferUuid, err := reviewTransferUuid(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.reviewService.RejectHeldTransfer(transferUuid, actor, req.GetFields()["reason"].GetStringValue())
+
+	return g.reviewResult(ctx, dbank.AuditActionTransferReject, transferUuid, err)
+}
+
+// reviewResult returns the transfer after the review. An approved transfer that then failed (e.g. the balance is no
+// longer enough) was still reviewed, the response is the failed transfer with its reason
+func (g *GrpcAdapter) reviewResult(ctx context.Context, action string, transferUuid uuid.UUID,
+	err error) (*structpb.Struct, error) {
+	reviewed := err == nil || !(errors.Is(err, dbank.ErrTransferNotFound) ||
+		errors.Is(err, dbank.ErrTransferNotHeld) || isReviewerDenied(err))
+
+	if reviewed {
+		transfer, findErr := g.bankService.GetTransfer(transferUuid)
+
+		g.audit(ctx, dbank.AuditEntry{
+			Action:     action,
+			EntityType: dbank.AuditEntityTransfer,
+			EntityIds:  []string{transferUuid.String()},
+			After:      transfer,
+			Err:        err,
+		})
+
+		if findErr == nil && (err == nil || transfer.Status == dbank.TransferStatusFailed) {
+			return structpb.NewStruct(heldTransferValue(transfer))
+		}
+	}
+
+	switch {
+	case errors.Is(err, dbank.ErrTransferNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, dbank.ErrTransferNotHeld):
+		s := status.New(codes.FailedPrecondition, err.Error())
+		s, _ = s.WithDetails(&errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{
+				{
+					Type:        "TRANSFER_STATUS",
+					Subject:     transferUuid.String(),
+					Description: err.Error(),
+				},
+			},
+		})
+
+		return nil, s.Err()
+	case isReviewerDenied(err):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	default:
+		return nil, status.Errorf(codes.Internal, "can't find transfer %v after review", transferUuid)
+	}
+}
+
+func reviewer(ctx context.Context) (string, error) {
+	actor, ok := verifiedActor(ctx)
+	if !ok {
+		return "", status.Errorf(codes.PermissionDenied, "%v : send a bearer token in the %v metadata",
+			dbank.ErrReviewerRequired, authorizationMetadataKey)
+	}
+
+	return actor, nil
+}
+
+func isReviewerDenied(err error) bool {
+	return errors.Is(err, dbank.ErrReviewerRequired) || errors.Is(err, dbank.ErrReviewerNotAllowed) ||
+		errors.Is(err, dbank.ErrReviewerIsRequester)
+}
+
+func reviewTransferUuid(req *structpb.Struct) (uuid.UUID, error) {
+	transferUuid, err := uuid.Parse(req.GetFields()["transfer_uuid"].GetStringValue())
+	if err != nil {
+		s := status.New(codes.InvalidArgument, "invalid transfer uuid")
+		s, _ = s.WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{
+					Field:       "transfer_uuid",
+					Description: err.Error(),
+				},
+			},
+		})
+
+		return uuid.Nil, s.Err()
+	}
+
+	return transferUuid, nil
+}
+
+func heldTransferValue(t dbank.Transfer) map[string]interface{} {
+	return map[string]interface{}{
+		"transfer_uuid":       t.TransferUuid.String(),
+		"from_account_number": t.FromAccountNumber,
+		"to_account_number":   t.ToAccountNumber,
+		"currency":            t.Currency,
+		"amount":              t.Amount,
+		"fee_amount":          t.FeeAmount,
+		"status":              t.Status,
+		"failure_reason":      t.FailureReason,
+		"transfer_timestamp":  t.TransferTimestamp.Format(time.RFC3339),
+	}
+}
+
+func riskAssessmentValue(a dbank.RiskAssessment) map[string]interface{} {
+	signals := make([]interface{}, 0, len(a.Signals))
+	for _, s := range a.Signals {
+		signals = append(signals, map[string]interface{}{
+			"rule":        s.Rule,
+			"score":       s.Score,
+			"description": s.Description,
+		})
+	}
+
+	return map[string]interface{}{
+		"decision": a.Decision,
+		"score":    a.Score,
+		"signals":  signals,
+	}
+}
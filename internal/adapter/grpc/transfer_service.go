@@ -0,0 +1,263 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/my-grpc-proto/protogen/go/bank"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the transfer service, times are RFC 3339 :
+//
+//	GetTransfer   {transfer_uuid} -> transfer
+//	ListTransfers {account_number, direction, status, start, end, sort, page_size, page_token}
+//	              -> {transfers: [transfer], next_page_token}
+//	QuoteTransfer {from_account_number, to_account_number, currency, amount}
+//	              -> {debit_currency, debit_amount, credit_currency, credit_amount, fee_amount, fee_components}
+//	Transfer      stream {from_account_number, to_account_number, currency, amount} -> stream transfer
+//
+// direction is SENT or RECEIVED (default both), status is a comma separated list of transfer statuses and sort is
+// asc (default) or desc on the transfer time. Transfer works like BankService.TransferMultiple but each response
+// carries the transfer_uuid and the status of its transfer, SETTLED or HELD. QuoteTransfer moves no money, it
+// returns what the transfer would debit, credit and charge
+const transferServiceName = "bank.TransferService"
+
+type transferServer interface {
+	GetTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	ListTransfers(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	QuoteTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	Transfer(stream grpc.ServerStream) error
+}
+
+var transferServiceDesc = grpc.ServiceDesc{
+	ServiceName: transferServiceName,
+	HandlerType: (*transferServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryStructMethod(transferServiceName, "GetTransfer", transferServer.GetTransfer),
+		unaryStructMethod(transferServiceName, "ListTransfers", transferServer.ListTransfers),
+		unaryStructMethod(transferServiceName, "QuoteTransfer", transferServer.QuoteTransfer),
+	},
+	Streams: []grpc.StreamDesc{
+		bidiStreamStructMethod("Transfer", transferServer.Transfer),
+	},
+}
+
+func (g *GrpcAdapter) GetTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	transferUuid, err := uuid.Parse(structString(req, "transfer_uuid"))
+	if err != nil {
+		return nil, buildBadRequestGrpc("transfer_uuid", err.Error())
+	}
+
+	transfer, err := g.bankService.GetTransfer(transferUuid)
+	if err != nil {
+		return nil, buildErrorStatusGrpc(err)
+	}
+
+	return structpb.NewStruct(transferValue(transfer))
+}
+
+func (g *GrpcAdapter) ListTransfers(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	accountNumber, err := g.requestAccountNumber(req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := dbank.TransferFilter{
+		AccountNumber: accountNumber,
+		Direction:     strings.ToUpper(structString(req, "direction")),
+		PageToken:     structString(req, "page_token"),
+	}
+
+	if v := structString(req, "status"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			filter.Statuses = append(filter.Statuses, strings.ToUpper(strings.TrimSpace(s)))
+		}
+	}
+
+	if filter.StartTimestamp, err = structTime(req, "start"); err != nil {
+		return nil, err
+	}
+
+	if filter.EndTimestamp, err = structTime(req, "end"); err != nil {
+		return nil, err
+	}
+
+	if filter.PageSize, err = structInt(req, "page_size"); err != nil {
+		return nil, err
+	}
+
+	switch structString(req, "sort") {
+	case "", "asc":
+	case "desc":
+		filter.SortDescending = true
+	default:
+		return nil, buildBadRequestGrpc("sort", "use asc or desc")
+	}
+
+	page, err := g.bankService.ListTransfers(filter)
+	if err != nil {
+		return nil, buildErrorStatusGrpc(err)
+	}
+
+	transfers := make([]interface{}, 0, len(page.Transfers))
+	for _, t := range page.Transfers {
+		transfers = append(transfers, transferValue(t))
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"transfers":       transfers,
+		"next_page_token": page.NextPageToken,
+	})
+}
+
+func (g *GrpcAdapter) QuoteTransfer(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	amount, err := structNumber(req, "amount")
+	if err != nil {
+		return nil, err
+	}
+
+	if amount == nil {
+		return nil, buildBadRequestGrpc("amount", "required")
+	}
+
+	tr := &bank.TransferRequest{
+		FromAccountNumber: structString(req, "from_account_number"),
+		ToAccountNumber:   structString(req, "to_account_number"),
+		Currency:          structString(req, "currency"),
+		Amount:            *amount,
+	}
+
+	if err := g.validateAccountNumbers(
+		requestField{field: "from_account_number", value: tr.FromAccountNumber},
+		requestField{field: "to_account_number", value: tr.ToAccountNumber},
+	); err != nil {
+		return nil, err
+	}
+
+	if err := g.validateCurrencies(requestField{field: "currency", value: tr.Currency}); err != nil {
+		return nil, err
+	}
+
+	quote, err := g.bankService.QuoteTransfer(dbank.TransferTransaction{
+		FromAccountNumber: tr.FromAccountNumber,
+		ToAccountNumber:   tr.ToAccountNumber,
+		Currency:          tr.Currency,
+		Amount:            tr.Amount,
+	})
+	if err != nil {
+		return nil, buildTransferErrorStatusGrpc(err, tr)
+	}
+
+	components := make([]interface{}, 0, len(quote.FeeComponents))
+	for _, c := range quote.FeeComponents {
+		components = append(components, map[string]interface{}{
+			"fee_type": c.FeeType,
+			"amount":   c.Amount,
+		})
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"from_account_number": quote.FromAccountNumber,
+		"to_account_number":   quote.ToAccountNumber,
+		"currency":            quote.Currency,
+		"amount":              quote.Amount,
+		"debit_currency":      quote.DebitCurrency,
+		"debit_amount":        quote.DebitAmount,
+		"credit_currency":     quote.CreditCurrency,
+		"credit_amount":       quote.CreditAmount,
+		"fee_amount":          quote.Fee,
+		"fee_currency":        quote.DebitCurrency,
+		"fee_components":      components,
+	})
+}
+
+func (g *GrpcAdapter) Transfer(stream grpc.ServerStream) error {
+	for {
+		in := new(structpb.Struct)
+
+		err := stream.RecvMsg(in)
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		amount, err := structNumber(in, "amount")
+		if err != nil {
+			return err
+		}
+
+		if amount == nil {
+			return buildBadRequestGrpc("amount", "required")
+		}
+
+		res, result, err := g.transfer(stream.Context(), &bank.TransferRequest{
+			FromAccountNumber: structString(in, "from_account_number"),
+			ToAccountNumber:   structString(in, "to_account_number"),
+			Currency:          structString(in, "currency"),
+			Amount:            *amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		out, err := structpb.NewStruct(map[string]interface{}{
+			"transfer_uuid":       result.TransferUuid.String(),
+			"from_account_number": res.FromAccountNumber,
+			"to_account_number":   res.ToAccountNumber,
+			"currency":            res.Currency,
+			"amount":              res.Amount,
+			"fee_amount":          result.Quote.Fee,
+			"fee_currency":        result.Quote.DebitCurrency,
+			"status":              transferResultStatus(result),
+			"transfer_timestamp":  time.Now().Format(time.RFC3339Nano),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := stream.SendMsg(out); err != nil {
+			return err
+		}
+	}
+}
+
+func transferValue(t dbank.Transfer) map[string]interface{} {
+	v := map[string]interface{}{
+		"transfer_uuid":       t.TransferUuid.String(),
+		"from_account_number": t.FromAccountNumber,
+		"to_account_number":   t.ToAccountNumber,
+		"currency":            t.Currency,
+		"amount":              t.Amount,
+		"fee_amount":          t.FeeAmount,
+		"fee_currency":        t.FeeCurrency,
+		"status":              t.Status,
+		"failure_reason":      t.FailureReason,
+		"transfer_timestamp":  t.TransferTimestamp.Format(time.RFC3339Nano),
+	}
+
+	for field, at := range map[string]*time.Time{
+		"authorized_at": t.AuthorizedAt,
+		"settled_at":    t.SettledAt,
+		"failed_at":     t.FailedAt,
+		"reversed_at":   t.ReversedAt,
+	} {
+		if at != nil {
+			v[field] = at.Format(time.RFC3339Nano)
+		}
+	}
+
+	if t.ReversalReason != "" {
+		v["reversal_reason"] = t.ReversalReason
+	}
+
+	return v
+}
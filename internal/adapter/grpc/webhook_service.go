@@ -0,0 +1,325 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	dbank "github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// the webhook service, times are RFC 3339 :
+//
+//	CreateSubscription {account_number, url, event_types, secret, large_withdrawal_amount, low_balance_amount}
+//	                   -> subscription
+//	DisableSubscription {subscription_uuid}  -> {subscription_uuid, status}
+//	ListDeliveries      {subscription_uuid}  -> {deliveries: [delivery]}
+//	ListAttempts        {delivery_uuid}      -> {attempts: [attempt]}
+//	ListDeadLetters     {subscription_uuid}  -> {dead_letters: [dead_letter]}
+//	Redeliver           {delivery_uuid}      -> {delivery_uuid, status}
+//
+// event_types is a list or a comma separated string of transfer.received, withdrawal.large and balance.low. The
+// secret is generated when missing and only returned by CreateSubscription
+const webhookServiceName = "bank.admin.WebhookService"
+
+type webhookServer interface {
+	CreateSubscription(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	DisableSubscription(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	ListDeliveries(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	ListAttempts(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	ListDeadLetters(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	Redeliver(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+var webhookServiceDesc = grpc.ServiceDesc{
+	ServiceName: webhookServiceName,
+	HandlerType: (*webhookServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryStructMethod(webhookServiceName, "CreateSubscription", webhookServer.CreateSubscription),
+		unaryStructMethod(webhookServiceName, "DisableSubscription", webhookServer.DisableSubscription),
+		unaryStructMethod(webhookServiceName, "ListDeliveries", webhookServer.ListDeliveries),
+		unaryStructMethod(webhookServiceName, "ListAttempts", webhookServer.ListAttempts),
+		unaryStructMethod(webhookServiceName, "ListDeadLetters", webhookServer.ListDeadLetters),
+		unaryStructMethod(webhookServiceName, "Redeliver", webhookServer.Redeliver),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// WithWebhookService serves the admin webhook service next to the bank service
+func (g *GrpcAdapter) WithWebhookService(w port.WebhookServicePort) *GrpcAdapter {
+	g.webhookService = w
+	return g
+}
+
+func (g *GrpcAdapter) CreateSubscription(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	ns := dbank.NewWebhookSubscription{
+		AccountNumber: structString(req, "account_number"),
+		Url:           structString(req, "url"),
+		Secret:        structString(req, "secret"),
+	}
+
+	if ns.AccountNumber != "" {
+		if _, err := g.requestAccountNumber(req); err != nil {
+			return nil, err
+		}
+	}
+
+	switch v := req.GetFields()["event_types"].GetKind().(type) {
+	case *structpb.Value_StringValue:
+		for _, eventType := range strings.Split(v.StringValue, ",") {
+			ns.EventTypes = append(ns.EventTypes, strings.TrimSpace(eventType))
+		}
+	case *structpb.Value_ListValue:
+		for _, eventType := range v.ListValue.GetValues() {
+			ns.EventTypes = append(ns.EventTypes, eventType.GetStringValue())
+		}
+	}
+
+	var err error
+
+	if ns.LargeWithdrawalAmount, err = structNumber(req, "large_withdrawal_amount"); err != nil {
+		return nil, err
+	}
+
+	if ns.LowBalanceAmount, err = structNumber(req, "low_balance_amount"); err != nil {
+		return nil, err
+	}
+
+	subscription, err := g.webhookService.CreateWebhookSubscription(ns)
+
+	entry := dbank.AuditEntry{
+		Action:     dbank.AuditActionWebhookCreate,
+		EntityType: dbank.AuditEntityWebhook,
+		Err:        err,
+	}
+
+	if err == nil {
+		// the secret stays out of the audit log
+		recorded := subscription
+		recorded.Secret = ""
+
+		entry.EntityIds = []string{subscription.SubscriptionUuid.String()}
+		entry.After = recorded
+	}
+
+	g.audit(ctx, entry)
+
+	if err != nil {
+		return nil, buildWebhookErrorStatusGrpc(err)
+	}
+
+	v := webhookSubscriptionValue(subscription)
+	v["secret"] = subscription.Secret
+
+	return structpb.NewStruct(v)
+}
+
+func (g *GrpcAdapter) DisableSubscription(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	subscriptionUuid, err := requestUuid(req, "subscription_uuid")
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.webhookService.DisableWebhookSubscription(subscriptionUuid)
+
+	g.audit(ctx, dbank.AuditEntry{
+		Action:     dbank.AuditActionWebhookDisable,
+		EntityType: dbank.AuditEntityWebhook,
+		EntityIds:  []string{subscriptionUuid.String()},
+		Err:        err,
+	})
+
+	if err != nil {
+		return nil, buildWebhookErrorStatusGrpc(err)
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"subscription_uuid": subscriptionUuid.String(),
+		"status":            dbank.WebhookStatusDisabled,
+	})
+}
+
+func (g *GrpcAdapter) ListDeliveries(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	subscriptionUuid, err := requestUuid(req, "subscription_uuid")
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := g.webhookService.FindWebhookDeliveries(subscriptionUuid)
+	if err != nil {
+		return nil, buildWebhookErrorStatusGrpc(err)
+	}
+
+	values := make([]interface{}, 0, len(deliveries))
+	for _, d := range deliveries {
+		v := map[string]interface{}{
+			"delivery_uuid":     d.DeliveryUuid.String(),
+			"subscription_uuid": d.SubscriptionUuid.String(),
+			"event_uuid":        d.EventUuid.String(),
+			"event_type":        d.EventType,
+			"status":            d.Status,
+			"attempts":          d.Attempts,
+			"next_attempt_at":   d.NextAttemptAt.Format(time.RFC3339),
+			"last_error":        d.LastError,
+			"created_at":        d.CreatedAt.Format(time.RFC3339),
+		}
+
+		if d.LastStatusCode != nil {
+			v["last_status_code"] = *d.LastStatusCode
+		}
+
+		if d.DeliveredAt != nil {
+			v["delivered_at"] = d.DeliveredAt.Format(time.RFC3339)
+		}
+
+		values = append(values, v)
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"deliveries": values,
+	})
+}
+
+func (g *GrpcAdapter) ListAttempts(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	deliveryUuid, err := requestUuid(req, "delivery_uuid")
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := g.webhookService.FindWebhookDeliveryAttempts(deliveryUuid)
+	if err != nil {
+		return nil, buildWebhookErrorStatusGrpc(err)
+	}
+
+	values := make([]interface{}, 0, len(attempts))
+	for _, a := range attempts {
+		v := map[string]interface{}{
+			"attempt":      a.Attempt,
+			"attempted_at": a.AttemptedAt.Format(time.RFC3339Nano),
+			"error":        a.Error,
+			"duration":     a.Duration.String(),
+		}
+
+		if a.StatusCode != nil {
+			v["status_code"] = *a.StatusCode
+		}
+
+		values = append(values, v)
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"attempts": values,
+	})
+}
+
+func (g *GrpcAdapter) ListDeadLetters(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	subscriptionUuid, err := requestUuid(req, "subscription_uuid")
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters, err := g.webhookService.FindWebhookDeadLetters(subscriptionUuid)
+	if err != nil {
+		return nil, buildWebhookErrorStatusGrpc(err)
+	}
+
+	values := make([]interface{}, 0, len(deadLetters))
+	for _, d := range deadLetters {
+		values = append(values, map[string]interface{}{
+			"delivery_uuid":     d.DeliveryUuid.String(),
+			"subscription_uuid": d.SubscriptionUuid.String(),
+			"event_type":        d.EventType,
+			"attempts":          d.Attempts,
+			"last_error":        d.LastError,
+			"dead_at":           d.DeadAt.Format(time.RFC3339),
+		})
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"dead_letters": values,
+	})
+}
+
+func (g *GrpcAdapter) Redeliver(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	deliveryUuid, err := requestUuid(req, "delivery_uuid")
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.webhookService.RedeliverWebhook(deliveryUuid)
+
+	g.audit(ctx, dbank.AuditEntry{
+		Action:     dbank.AuditActionWebhookRedeliver,
+		EntityType: dbank.AuditEntityDelivery,
+		EntityIds:  []string{deliveryUuid.String()},
+		Err:        err,
+	})
+
+	if err != nil {
+		return nil, buildWebhookErrorStatusGrpc(err)
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"delivery_uuid": deliveryUuid.String(),
+		"status":        dbank.WebhookDeliveryPending,
+	})
+}
+
+func requestUuid(req *structpb.Struct, field string) (uuid.UUID, error) {
+	id, err := uuid.Parse(structString(req, field))
+	if err != nil {
+		return uuid.Nil, buildBadRequestGrpc(field, err.Error())
+	}
+
+	return id, nil
+}
+
+func buildWebhookErrorStatusGrpc(err error) error {
+	switch {
+	case errors.Is(err, dbank.ErrInvalidWebhookUrl):
+		return buildBadRequestGrpc("url", err.Error())
+	case errors.Is(err, dbank.ErrInvalidWebhookEventType):
+		return buildBadRequestGrpc("event_types", err.Error())
+	case errors.Is(err, dbank.ErrInvalidWebhookThreshold):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, dbank.ErrWebhookSubscriptionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, dbank.ErrWebhookDeliveryNotDead):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return buildErrorStatusGrpc(err)
+	}
+}
+
+func webhookSubscriptionValue(s dbank.WebhookSubscription) map[string]interface{} {
+	eventTypes := make([]interface{}, 0, len(s.EventTypes))
+	for _, eventType := range s.EventTypes {
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	v := map[string]interface{}{
+		"subscription_uuid": s.SubscriptionUuid.String(),
+		"account_number":    s.AccountNumber,
+		"url":               s.Url,
+		"event_types":       eventTypes,
+		"status":            s.Status,
+		"created_at":        s.CreatedAt.Format(time.RFC3339),
+	}
+
+	if s.LargeWithdrawalAmount != nil {
+		v["large_withdrawal_amount"] = *s.LargeWithdrawalAmount
+	}
+
+	if s.LowBalanceAmount != nil {
+		v["low_balance_amount"] = *s.LowBalanceAmount
+	}
+
+	return v
+}
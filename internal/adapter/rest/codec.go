@@ -0,0 +1,195 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const maxBodySize = 1 << 20
+
+// the headers passed on to the gRPC server as metadata, see the grpc adapter for what they mean
+var forwardedHeaders = map[string]string{
+	"Authorization": "authorization",
+	"X-User-Id":     "x-user-id",
+	"X-Request-Id":  "x-request-id",
+}
+
+var marshalOptions = protojson.MarshalOptions{EmitUnpopulated: true}
+var unmarshalOptions = protojson.UnmarshalOptions{}
+
+func outgoingContext(req *http.Request) context.Context {
+	md := metadata.MD{}
+
+	for header, key := range forwardedHeaders {
+		if v := req.Header.Get(header); v != "" {
+			md.Set(key, v)
+		}
+	}
+
+	return metadata.NewOutgoingContext(req.Context(), md)
+}
+
+// setRequestId returns the request id the gRPC server used, generated when the client didn't send one
+func setRequestId(w http.ResponseWriter, header metadata.MD) {
+	if v := header.Get(forwardedHeaders["X-Request-Id"]); len(v) > 0 {
+		w.Header().Set("X-Request-Id", v[0])
+	}
+}
+
+// decodeQuery sets the fields of msg from the query string, a parameter is named like the JSON field
+func decodeQuery(req *http.Request, msg proto.Message) error {
+	fields := msg.ProtoReflect().Descriptor().Fields()
+	values := make(map[string]json.RawMessage)
+
+	for name, v := range req.URL.Query() {
+		fd := fields.ByJSONName(name)
+		if fd == nil {
+			return badRequest(name, "unknown query parameter")
+		}
+
+		if len(v) > 1 || fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind {
+			return badRequest(name, "must be a single value")
+		}
+
+		// protojson takes numbers as strings but not booleans
+		var raw json.RawMessage
+		if fd.Kind() == protoreflect.BoolKind && (v[0] == "true" || v[0] == "false") {
+			raw = json.RawMessage(v[0])
+		} else {
+			raw, _ = json.Marshal(v[0])
+		}
+
+		values[fd.JSONName()] = raw
+	}
+
+	data, _ := json.Marshal(values)
+
+	if err := unmarshalOptions.Unmarshal(data, msg); err != nil {
+		return badRequest("query", err.Error())
+	}
+
+	return nil
+}
+
+func readBody(w http.ResponseWriter, req *http.Request) ([]byte, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize))
+	if err != nil {
+		return nil, badRequest("body", err.Error())
+	}
+
+	return bytes.TrimSpace(data), nil
+}
+
+// decodeBody reads a JSON object into msg, an empty body is an empty object
+func decodeBody(w http.ResponseWriter, req *http.Request, msg proto.Message) error {
+	data, err := readBody(w, req)
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := unmarshalOptions.Unmarshal(data, msg); err != nil {
+		return badRequest("body", err.Error())
+	}
+
+	return nil
+}
+
+// decodeBodyList reads a JSON array and calls each with every element
+func decodeBodyList(w http.ResponseWriter, req *http.Request, each func(data []byte) error) error {
+	data, err := readBody(w, req)
+	if err != nil {
+		return err
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return badRequest("body", "must be a JSON array : "+err.Error())
+	}
+
+	for i, e := range elements {
+		if err := each(e); err != nil {
+			return badRequest(fmt.Sprintf("body[%d]", i), err.Error())
+		}
+	}
+
+	return nil
+}
+
+func writeResponse(w http.ResponseWriter, header metadata.MD, status int, msg proto.Message, err error) {
+	setRequestId(w, header)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeMessage(w, status, msg, nil)
+}
+
+// writeMessage writes msg as JSON with the extra fields added to it
+func writeMessage(w http.ResponseWriter, status int, msg proto.Message, extra map[string]string) {
+	data, err := marshalMessage(msg, extra)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJson(w, status, data)
+}
+
+func writeList(w http.ResponseWriter, header metadata.MD, msgs []proto.Message) {
+	setRequestId(w, header)
+
+	elements := make([]json.RawMessage, 0, len(msgs))
+
+	for _, msg := range msgs {
+		data, err := marshalMessage(msg, nil)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		elements = append(elements, data)
+	}
+
+	data, _ := json.Marshal(elements)
+
+	writeJson(w, http.StatusOK, data)
+}
+
+func marshalMessage(msg proto.Message, extra map[string]string) ([]byte, error) {
+	data, err := marshalOptions.Marshal(msg)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for k, v := range extra {
+		fields[k] = v
+	}
+
+	return json.Marshal(fields)
+}
+
+func writeJson(w http.ResponseWriter, status int, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// errorBody is the JSON body of every error. Code is the gRPC code name and details are the errdetails of the
+// status, each with its @type
+type errorBody struct {
+	Error errorStatus `json:"error"`
+}
+
+type errorStatus struct {
+	Status  int               `json:"status"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details"`
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	s := status.Convert(err)
+	writeStatus(w, httpStatusFromCode(s.Code()), s)
+}
+
+func writeStatus(w http.ResponseWriter, httpStatus int, s *status.Status) {
+	writeJson(w, httpStatus, errorJson(httpStatus, s))
+}
+
+func errorJson(httpStatus int, s *status.Status) []byte {
+	body := errorBody{
+		Error: errorStatus{
+			Status:  httpStatus,
+			Code:    code.Code(s.Code()).String(),
+			Message: s.Message(),
+			Details: []json.RawMessage{},
+		},
+	}
+
+	for _, d := range s.Proto().GetDetails() {
+		// details of a type this binary doesn't know can't be written as JSON
+		data, err := protojson.Marshal(d)
+		if err != nil {
+			continue
+		}
+
+		body.Error.Details = append(body.Error.Details, data)
+	}
+
+	data, _ := json.Marshal(body)
+
+	return data
+}
+
+func notFound(w http.ResponseWriter, req *http.Request) {
+	writeStatus(w, http.StatusNotFound, status.Newf(codes.NotFound, "no route for %v", req.URL.Path))
+}
+
+func badRequest(field string, description string) error {
+	s := status.New(codes.InvalidArgument, "invalid request")
+	s, _ = s.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{
+				Field:       field,
+				Description: description,
+			},
+		},
+	})
+
+	return s.Err()
+}
+
+// httpStatusFromCode follows the mapping of google.rpc.Code, 499 is the status nginx uses for a closed request
+func httpStatusFromCode(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	eventMessage = "message"
+	eventError   = "error"
+	eventEnd     = "end"
+)
+
+// serveEvents sends every response as a message event. The stream finishes with an end event, or an error event
+// with the error body. The first response is waited for, so an invalid request still gets an error status
+func serveEvents(w http.ResponseWriter, req *http.Request, stream grpc.ClientStream,
+	recv func() (proto.Message, error)) {
+	msg, err := recv()
+
+	header, _ := stream.Header()
+
+	streamEvents(w, req, header, msg, err, recv)
+}
+
+// the header the gRPC server sends when it accepted a feed
+const streamOpenHeader = "stream-open"
+
+// serveFeed is serveEvents for a stream that can stay quiet for a long time, such as an account feed. The events
+// start once the gRPC server sent the stream-open header, which it does after checking the request
+func serveFeed(w http.ResponseWriter, req *http.Request, stream grpc.ClientStream,
+	recv func() (proto.Message, error)) {
+	header, _ := stream.Header()
+
+	if len(header.Get(streamOpenHeader)) == 0 {
+		// the request was refused, recv has the status
+		msg, err := recv()
+		streamEvents(w, req, header, msg, err, recv)
+
+		return
+	}
+
+	streamEvents(w, req, header, nil, nil, recv)
+}
+
+// streamEvents writes msg, or the error status when err is set before anything was written, then the rest of the
+// stream. A nil msg without error opens the event stream without a first event
+func streamEvents(w http.ResponseWriter, req *http.Request, header metadata.MD, msg proto.Message, err error,
+	recv func() (proto.Message, error)) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, status.Error(codes.Internal, "streaming is not supported"))
+		return
+	}
+
+	setRequestId(w, header)
+
+	if err != nil && err != io.EOF {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	if err == nil && msg == nil {
+		msg, err = recv()
+	}
+
+	for err == nil {
+		var data []byte
+		if data, err = marshalOptions.Marshal(msg); err != nil {
+			break
+		}
+
+		writeEvent(w, eventMessage, data)
+		flusher.Flush()
+
+		msg, err = recv()
+	}
+
+	switch {
+	case err == io.EOF:
+		writeEvent(w, eventEnd, []byte("{}"))
+	case req.Context().Err() != nil:
+		// the client went away
+		return
+	default:
+		s := status.Convert(err)
+		writeEvent(w, eventError, errorJson(httpStatusFromCode(s.Code()), s))
+	}
+
+	flusher.Flush()
+}
+
+// writeEvent expects single line data, which is what protojson and encoding/json write
+func writeEvent(w io.Writer, event string, data []byte) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
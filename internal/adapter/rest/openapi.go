@@ -0,0 +1,333 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const openApiPath = "/openapi.json"
+
+func (r *RestAdapter) serveOpenApi(w http.ResponseWriter, req *http.Request) {
+	writeJson(w, http.StatusOK, r.openApi)
+}
+
+// buildOpenApi describes the routes, the schemas come from the descriptors of the messages so the document follows
+// the proto module
+func buildOpenApi(routes []route) ([]byte, error) {
+	schemas := map[string]interface{}{
+		"Error": errorSchema(),
+	}
+
+	paths := make(map[string]interface{})
+
+	for _, rt := range routes {
+		rpc := strings.TrimPrefix(rt.rpc, "/")
+		service, method, _ := strings.Cut(rpc, "/")
+		service = service[strings.LastIndex(service, ".")+1:]
+
+		op := map[string]interface{}{
+			"operationId": service + "_" + method,
+			"summary":     rt.summary,
+			"description": "Calls the gRPC method " + rpc + ".",
+			"tags":        []string{service},
+			"parameters": []interface{}{
+				map[string]interface{}{"$ref": "#/components/parameters/UserId"},
+				map[string]interface{}{"$ref": "#/components/parameters/RequestId"},
+			},
+			"responses": map[string]interface{}{
+				strconv.Itoa(rt.successStatus()): successResponse(rt, schemas),
+				"default": map[string]interface{}{
+					"description": "the gRPC status of the failure, with its error details",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": schemaRef("Error"),
+						},
+					},
+				},
+			},
+		}
+
+		md := rt.request.ProtoReflect().Descriptor()
+
+		switch {
+		case rt.input == inputQuery && rt.parameters != nil:
+			parameters := op["parameters"].([]interface{})
+
+			names := make([]string, 0, len(rt.parameters))
+			for name := range rt.parameters {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			for _, name := range names {
+				parameters = append(parameters, map[string]interface{}{
+					"name":        name,
+					"in":          "query",
+					"description": rt.parameters[name],
+					"schema":      map[string]interface{}{"type": "string"},
+				})
+			}
+
+			op["parameters"] = parameters
+		case rt.input == inputQuery:
+			parameters := op["parameters"].([]interface{})
+
+			fields := md.Fields()
+			for i := 0; i < fields.Len(); i++ {
+				parameters = append(parameters, map[string]interface{}{
+					"name":   fields.Get(i).JSONName(),
+					"in":     "query",
+					"schema": fieldSchema(fields.Get(i), schemas),
+				})
+			}
+
+			op["parameters"] = parameters
+		case rt.input == inputBody, rt.input == inputBodyList:
+			schema := messageSchema(md, schemas)
+			if rt.input == inputBodyList {
+				schema = map[string]interface{}{"type": "array", "items": schema}
+			}
+
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": schema,
+					},
+				},
+			}
+		}
+
+		paths[rt.path] = map[string]interface{}{
+			strings.ToLower(rt.method): op,
+		}
+	}
+
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Bank gateway",
+			"version": "v1",
+			"description": "HTTP/JSON gateway to the gRPC services of the bank. Streams are Server-Sent " +
+				"Events.",
+		},
+		"paths": paths,
+		"security": []interface{}{
+			map[string]interface{}{},
+			map[string]interface{}{"BearerToken": []string{}},
+		},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"BearerToken": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "authenticates the actor, unknown tokens are refused",
+				},
+			},
+			"parameters": map[string]interface{}{
+				"UserId": map[string]interface{}{
+					"name":        "X-User-Id",
+					"in":          "header",
+					"description": "who the caller claims to be without a token, recorded in the audit log as unverified",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+				"RequestId": map[string]interface{}{
+					"name":        "X-Request-Id",
+					"in":          "header",
+					"description": "id of the request, generated and returned when missing",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func successResponse(rt route, schemas map[string]interface{}) map[string]interface{} {
+	schema := messageSchema(rt.response.ProtoReflect().Descriptor(), schemas)
+
+	if len(rt.extraFields) > 0 {
+		properties := make(map[string]interface{})
+		for name, description := range rt.extraFields {
+			properties[name] = map[string]interface{}{"type": "string", "description": description}
+		}
+
+		schema = map[string]interface{}{
+			"allOf": []interface{}{schema, map[string]interface{}{"type": "object", "properties": properties}},
+		}
+	}
+
+	if rt.list {
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+
+	response := map[string]interface{}{
+		"description": "the response",
+		"headers": map[string]interface{}{
+			"X-Request-Id": map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string"},
+			},
+		},
+	}
+
+	if rt.events {
+		response["description"] = "a " + eventMessage + " event with each response, then an " + eventEnd +
+			" event, or an " + eventError + " event with an Error when the stream fails"
+		response["content"] = map[string]interface{}{
+			"text/event-stream": map[string]interface{}{
+				"schema": schema,
+			},
+		}
+
+		return response
+	}
+
+	response["content"] = map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema": schema,
+		},
+	}
+
+	return response
+}
+
+// messageSchema adds the schema of the message and the ones it refers to, and returns a reference to it
+func messageSchema(md protoreflect.MessageDescriptor, schemas map[string]interface{}) map[string]interface{} {
+	name := string(md.FullName())
+
+	switch name {
+	case "google.protobuf.Duration":
+		return map[string]interface{}{"type": "string", "example": "3.5s"}
+	case "google.protobuf.Timestamp":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "google.protobuf.Struct":
+		return map[string]interface{}{"type": "object"}
+	case "google.protobuf.Value":
+		return map[string]interface{}{}
+	}
+
+	if _, ok := schemas[name]; ok {
+		return schemaRef(name)
+	}
+
+	properties := make(map[string]interface{})
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	// registered before the fields so a message referring to itself ends
+	schemas[name] = schema
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		properties[fields.Get(i).JSONName()] = fieldSchema(fields.Get(i), schemas)
+	}
+
+	return schemaRef(name)
+}
+
+func fieldSchema(fd protoreflect.FieldDescriptor, schemas map[string]interface{}) map[string]interface{} {
+	if fd.IsMap() {
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": kindSchema(fd.MapValue(), schemas),
+		}
+	}
+
+	schema := kindSchema(fd, schemas)
+
+	if fd.IsList() {
+		return map[string]interface{}{"type": "array", "items": schema}
+	}
+
+	return schema
+}
+
+// kindSchema follows protojson, 64 bit integers are strings and enums are their value names
+func kindSchema(fd protoreflect.FieldDescriptor, schemas map[string]interface{}) map[string]interface{} {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]interface{}{"type": "integer", "format": "int64", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]interface{}{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case protoreflect.BytesKind:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+
+		return map[string]interface{}{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageSchema(fd.Message(), schemas)
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func errorSchema() map[string]interface{} {
+	codeNames := make([]string, 0, len(code.Code_name))
+	for _, name := range code.Code_name {
+		codeNames = append(codeNames, name)
+	}
+
+	sort.Strings(codeNames)
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"error": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"status": map[string]interface{}{
+						"type":        "integer",
+						"description": "the HTTP status",
+					},
+					"code": map[string]interface{}{
+						"type":        "string",
+						"description": "the gRPC status code",
+						"enum":        codeNames,
+					},
+					"message": map[string]interface{}{"type": "string"},
+					"details": map[string]interface{}{
+						"type":        "array",
+						"description": "the google.rpc error details, e.g. ErrorInfo or BadRequest",
+						"items": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"@type": map[string]interface{}{"type": "string"},
+							},
+							"additionalProperties": true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
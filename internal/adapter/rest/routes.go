@@ -0,0 +1,451 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/Just-Goo/my-grpc-proto/protogen/go/bank"
+	"github.com/Just-Goo/my-grpc-proto/protogen/go/hello"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// the trailer the gRPC server sends the transfer uuid in
+const (
+	transferUuidTrailer   = "transfer-uuid"
+	transferStatusTrailer = "transfer-status"
+)
+
+const (
+	inputQuery = iota
+	inputBody
+	inputBodyList
+)
+
+// route maps an HTTP method and path to a gRPC method. The request and response messages are only read to build the
+// OpenAPI document
+type route struct {
+	method   string
+	path     string
+	rpc      string
+	summary  string
+	input    int
+	request  proto.Message
+	response proto.Message
+
+	// events streams the responses as Server-Sent Events, list returns them as a JSON array
+	events bool
+	list   bool
+
+	// status is the success status, 200 when zero
+	status int
+
+	// extraFields are added to the response object next to the message fields, name -> description
+	extraFields map[string]string
+
+	// parameters are the query parameters of a request without a message of its own (a Struct), name -> description
+	parameters map[string]string
+
+	handle http.HandlerFunc
+}
+
+func (rt route) successStatus() int {
+	if rt.status == 0 {
+		return http.StatusOK
+	}
+
+	return rt.status
+}
+
+func (rt route) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != rt.method {
+		w.Header().Set("Allow", rt.method)
+		writeStatus(w, http.StatusMethodNotAllowed,
+			status.Newf(codes.Unimplemented, "%v %v is not supported, use %v", req.Method, rt.path, rt.method))
+		return
+	}
+
+	rt.handle(w, req)
+}
+
+func (r *RestAdapter) routes() []route {
+	return []route{
+		{
+			method:   http.MethodGet,
+			path:     "/v1/hello",
+			rpc:      hello.HelloService_SayHello_FullMethodName,
+			summary:  "Greet someone",
+			input:    inputQuery,
+			request:  &hello.HelloRequest{},
+			response: &hello.HelloResponse{},
+			handle:   r.sayHello,
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/v1/hello/stream",
+			rpc:      hello.HelloService_SayManyHellos_FullMethodName,
+			summary:  "Greet someone ten times",
+			input:    inputQuery,
+			request:  &hello.HelloRequest{},
+			response: &hello.HelloResponse{},
+			events:   true,
+			handle:   r.sayManyHellos,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/hello/everyone",
+			rpc:      hello.HelloService_SayHelloToEveryone_FullMethodName,
+			summary:  "Greet everyone in a single greeting",
+			input:    inputBodyList,
+			request:  &hello.HelloRequest{},
+			response: &hello.HelloResponse{},
+			handle:   r.sayHelloToEveryone,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/hello/continuous",
+			rpc:      hello.HelloService_SayHelloContinuous_FullMethodName,
+			summary:  "Greet everyone one by one",
+			input:    inputBodyList,
+			request:  &hello.HelloRequest{},
+			response: &hello.HelloResponse{},
+			list:     true,
+			handle:   r.sayHelloContinuous,
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/v1/accounts/balance",
+			rpc:      bank.BankService_GetCurrentBalance_FullMethodName,
+			summary:  "Get the current balance of an account",
+			input:    inputQuery,
+			request:  &bank.CurrentBalanceRequest{},
+			response: &bank.CurrentBalanceResponse{},
+			handle:   r.getCurrentBalance,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/accounts",
+			rpc:      bank.BankService_CreateAccount_FullMethodName,
+			summary:  "Open an account",
+			input:    inputBody,
+			request:  &bank.CreateAccountRequest{},
+			response: &bank.CreateAccountResponse{},
+			status:   http.StatusCreated,
+			handle:   r.createAccount,
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/v1/exchange-rates/stream",
+			rpc:      bank.BankService_FetchExchangeRates_FullMethodName,
+			summary:  "Follow the exchange rate of a currency pair",
+			input:    inputQuery,
+			request:  &bank.ExchangeRateRequest{},
+			response: &bank.ExchangeRateResponse{},
+			events:   true,
+			handle:   r.fetchExchangeRates,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/transactions/summary",
+			rpc:      bank.BankService_SummarizeTransactions_FullMethodName,
+			summary:  "Record transactions and summarize them",
+			input:    inputBodyList,
+			request:  &bank.Transaction{},
+			response: &bank.TransactionSummary{},
+			handle:   r.summarizeTransactions,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/transfers",
+			rpc:      bank.BankService_TransferMultiple_FullMethodName,
+			summary:  "Transfer money between two accounts",
+			input:    inputBody,
+			request:  &bank.TransferRequest{},
+			response: &bank.TransferResponse{},
+			extraFields: map[string]string{
+				"transfer_uuid":   "uuid of the transfer, a held transfer is followed up with it",
+				"transfer_status": "SETTLED, HELD or FAILED, status is TRANSFER_STATUS_UNSPECIFIED for a held transfer",
+			},
+			handle: r.transfer,
+		},
+		r.structQueryRoute("/v1/accounts/transactions", "/bank.TransactionService/ListTransactions",
+			"List the transactions of an account", map[string]string{
+				"account_number":   "the account",
+				"start":            "RFC 3339 time, the first transactions included",
+				"end":              "RFC 3339 time, the transactions from then are excluded",
+				"transaction_type": "IN or OUT",
+				"min_amount":       "smallest amount",
+				"max_amount":       "largest amount",
+				"notes_contains":   "text the notes contain",
+				"sort":             "asc (default) or desc on the transaction time",
+				"page_size":        "transactions per page",
+				"page_token":       "next_page_token of the previous page",
+			}),
+		r.structFeedRoute("/v1/accounts/activity/stream", "/bank.ActivityService/WatchAccount",
+			"Follow the transactions and balance changes of an account", map[string]string{
+				"account_number": "the account",
+				"cursor":         "cursor of the last item received, to get what was missed",
+			}),
+		r.structQueryRoute("/v1/transfers/lookup", "/bank.TransferService/GetTransfer", "Get a transfer",
+			map[string]string{
+				"transfer_uuid": "uuid of the transfer",
+			}),
+		r.structQueryRoute("/v1/transfers/quote", "/bank.TransferService/QuoteTransfer",
+			"Preview the amounts and the fee of a transfer without moving money", map[string]string{
+				"from_account_number": "the account debited",
+				"to_account_number":   "the account credited",
+				"currency":            "currency of the amount",
+				"amount":              "amount to transfer",
+			}),
+		r.structQueryRoute("/v1/accounts/transfers", "/bank.TransferService/ListTransfers",
+			"List the transfers of an account", map[string]string{
+				"account_number": "the account",
+				"direction":      "SENT or RECEIVED, both when missing",
+				"status":         "comma separated transfer statuses",
+				"start":          "RFC 3339 time, the first transfers included",
+				"end":            "RFC 3339 time, the transfers from then are excluded",
+				"sort":           "asc (default) or desc on the transfer time",
+				"page_size":      "transfers per page",
+				"page_token":     "next_page_token of the previous page",
+			}),
+	}
+}
+
+func (r *RestAdapter) sayHello(w http.ResponseWriter, req *http.Request) {
+	in := &hello.HelloRequest{}
+	if err := decodeQuery(req, in); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var header metadata.MD
+	res, err := r.helloClient.SayHello(outgoingContext(req), in, grpc.Header(&header))
+
+	writeResponse(w, header, http.StatusOK, res, err)
+}
+
+func (r *RestAdapter) sayManyHellos(w http.ResponseWriter, req *http.Request) {
+	in := &hello.HelloRequest{}
+	if err := decodeQuery(req, in); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	stream, err := r.helloClient.SayManyHellos(outgoingContext(req), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	serveEvents(w, req, stream, func() (proto.Message, error) {
+		return stream.Recv()
+	})
+}
+
+func (r *RestAdapter) sayHelloToEveryone(w http.ResponseWriter, req *http.Request) {
+	var requests []*hello.HelloRequest
+
+	if err := decodeBodyList(w, req, func(data []byte) error {
+		in := &hello.HelloRequest{}
+		requests = append(requests, in)
+
+		return unmarshalOptions.Unmarshal(data, in)
+	}); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	stream, err := r.helloClient.SayHelloToEveryone(outgoingContext(req))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// a failed send ends the stream, the reason comes back from CloseAndRecv
+	for _, in := range requests {
+		if stream.Send(in) != nil {
+			break
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	header, _ := stream.Header()
+
+	writeResponse(w, header, http.StatusOK, res, err)
+}
+
+func (r *RestAdapter) sayHelloContinuous(w http.ResponseWriter, req *http.Request) {
+	var requests []*hello.HelloRequest
+
+	if err := decodeBodyList(w, req, func(data []byte) error {
+		in := &hello.HelloRequest{}
+		requests = append(requests, in)
+
+		return unmarshalOptions.Unmarshal(data, in)
+	}); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	stream, err := r.helloClient.SayHelloContinuous(outgoingContext(req))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for _, in := range requests {
+		if stream.Send(in) != nil {
+			break
+		}
+	}
+
+	stream.CloseSend()
+
+	responses := make([]proto.Message, 0, len(requests))
+
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			header, _ := stream.Header()
+			setRequestId(w, header)
+			writeError(w, err)
+
+			return
+		}
+
+		responses = append(responses, res)
+	}
+
+	header, _ := stream.Header()
+
+	writeList(w, header, responses)
+}
+
+func (r *RestAdapter) getCurrentBalance(w http.ResponseWriter, req *http.Request) {
+	in := &bank.CurrentBalanceRequest{}
+	if err := decodeQuery(req, in); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var header metadata.MD
+	res, err := r.bankClient.GetCurrentBalance(outgoingContext(req), in, grpc.Header(&header))
+
+	writeResponse(w, header, http.StatusOK, res, err)
+}
+
+func (r *RestAdapter) createAccount(w http.ResponseWriter, req *http.Request) {
+	in := &bank.CreateAccountRequest{}
+	if err := decodeBody(w, req, in); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var header metadata.MD
+	res, err := r.bankClient.CreateAccount(outgoingContext(req), in, grpc.Header(&header))
+
+	writeResponse(w, header, http.StatusCreated, res, err)
+}
+
+func (r *RestAdapter) fetchExchangeRates(w http.ResponseWriter, req *http.Request) {
+	in := &bank.ExchangeRateRequest{}
+	if err := decodeQuery(req, in); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	stream, err := r.bankClient.FetchExchangeRates(outgoingContext(req), in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	serveEvents(w, req, stream, func() (proto.Message, error) {
+		return stream.Recv()
+	})
+}
+
+func (r *RestAdapter) summarizeTransactions(w http.ResponseWriter, req *http.Request) {
+	var transactions []*bank.Transaction
+
+	if err := decodeBodyList(w, req, func(data []byte) error {
+		t := &bank.Transaction{}
+		transactions = append(transactions, t)
+
+		return unmarshalOptions.Unmarshal(data, t)
+	}); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	stream, err := r.bankClient.SummarizeTransactions(outgoingContext(req))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for _, t := range transactions {
+		if stream.Send(t) != nil {
+			break
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	header, _ := stream.Header()
+
+	writeResponse(w, header, http.StatusOK, res, err)
+}
+
+// transfer sends a single request on the TransferMultiple stream, the uuid the server sends in the trailer is added
+// to the response
+func (r *RestAdapter) transfer(w http.ResponseWriter, req *http.Request) {
+	in := &bank.TransferRequest{}
+	if err := decodeBody(w, req, in); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	stream, err := r.bankClient.TransferMultiple(outgoingContext(req))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := stream.Send(in); err == nil {
+		stream.CloseSend()
+	}
+
+	res, err := stream.Recv()
+	if err == nil {
+		// read to the end of the stream so the trailer is there
+		if _, eofErr := stream.Recv(); eofErr != io.EOF {
+			err = eofErr
+		}
+	}
+
+	header, _ := stream.Header()
+
+	if err != nil {
+		writeResponse(w, header, http.StatusOK, nil, err)
+		return
+	}
+
+	extra := map[string]string{}
+	if v := stream.Trailer().Get(transferUuidTrailer); len(v) > 0 {
+		extra["transfer_uuid"] = v[0]
+	}
+
+	if v := stream.Trailer().Get(transferStatusTrailer); len(v) > 0 {
+		extra["transfer_status"] = v[0]
+	}
+
+	setRequestId(w, header)
+	writeMessage(w, http.StatusOK, res, extra)
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Just-Goo/my-grpc-proto/protogen/go/bank"
+	"github.com/Just-Goo/my-grpc-proto/protogen/go/hello"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// RestAdapter is an HTTP/JSON gateway in front of the gRPC server. It calls the gRPC services like any other client,
+// so the requests go through the same validation, audit and error details
+type RestAdapter struct {
+	grpcAddress string
+	httpPort    int
+	conn        *grpc.ClientConn
+	server      *http.Server
+	bankClient  bank.BankServiceClient
+	helloClient hello.HelloServiceClient
+	openApi     []byte
+}
+
+func NewRestAdapter(grpcAddress string, httpPort int) *RestAdapter {
+	return &RestAdapter{
+		grpcAddress: grpcAddress,
+		httpPort:    httpPort,
+	}
+}
+
+func (r *RestAdapter) Run() {
+	conn, err := grpc.NewClient(r.grpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect the gateway to %v : %v\n", r.grpcAddress, err)
+	}
+
+	r.conn = conn
+	r.bankClient = bank.NewBankServiceClient(conn)
+	r.helloClient = hello.NewHelloServiceClient(conn)
+
+	routes := r.routes()
+
+	r.openApi, err = buildOpenApi(routes)
+	if err != nil {
+		log.Fatalln("can't build the OpenAPI document", err)
+	}
+
+	mux := http.NewServeMux()
+	for _, rt := range routes {
+		mux.Handle(rt.path, rt)
+	}
+
+	mux.HandleFunc(openApiPath, r.serveOpenApi)
+	mux.HandleFunc("/", notFound)
+
+	// no write timeout, the event streams stay open as long as the client listens
+	r.server = &http.Server{
+		Addr:              fmt.Sprintf(":%d", r.httpPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("gateway listening on port %d\n", r.httpPort)
+
+	if err := r.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("failed to serve gateway on port %d : %v\n", r.httpPort, err)
+	}
+}
+
+func (r *RestAdapter) Stop() {
+	r.server.Shutdown(context.Background())
+	r.conn.Close()
+}
@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// structQueryRoute maps a GET to a unary method of a hand-written service of the gRPC server, those take and return
+// a google.protobuf.Struct. The query parameters are the string fields of the request, the gRPC server reads the
+// numbers and times from them
+func (r *RestAdapter) structQueryRoute(path string, rpc string, summary string, parameters map[string]string) route {
+	return route{
+		method:     http.MethodGet,
+		path:       path,
+		rpc:        rpc,
+		summary:    summary,
+		input:      inputQuery,
+		request:    &structpb.Struct{},
+		response:   &structpb.Struct{},
+		parameters: parameters,
+		handle: func(w http.ResponseWriter, req *http.Request) {
+			in, err := decodeStructQuery(req, parameters)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			out := &structpb.Struct{}
+
+			var header metadata.MD
+			err = r.conn.Invoke(outgoingContext(req), rpc, in, out, grpc.Header(&header))
+
+			writeResponse(w, header, http.StatusOK, out, err)
+		},
+	}
+}
+
+// structFeedRoute maps a GET to a server streaming method of a hand-written service that follows something, the
+// responses are Server-Sent Events that start as soon as the gRPC server accepted the request
+func (r *RestAdapter) structFeedRoute(path string, rpc string, summary string, parameters map[string]string) route {
+	return route{
+		method:     http.MethodGet,
+		path:       path,
+		rpc:        rpc,
+		summary:    summary,
+		input:      inputQuery,
+		request:    &structpb.Struct{},
+		response:   &structpb.Struct{},
+		parameters: parameters,
+		events:     true,
+		handle: func(w http.ResponseWriter, req *http.Request) {
+			in, err := decodeStructQuery(req, parameters)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			stream, err := r.conn.NewStream(outgoingContext(req), &grpc.StreamDesc{ServerStreams: true}, rpc)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			if err := stream.SendMsg(in); err == nil {
+				stream.CloseSend()
+			}
+
+			serveFeed(w, req, stream, func() (proto.Message, error) {
+				out := &structpb.Struct{}
+				return out, stream.RecvMsg(out)
+			})
+		},
+	}
+}
+
+func decodeStructQuery(req *http.Request, parameters map[string]string) (*structpb.Struct, error) {
+	in := &structpb.Struct{Fields: make(map[string]*structpb.Value)}
+
+	for name, v := range req.URL.Query() {
+		if _, ok := parameters[name]; !ok {
+			return nil, badRequest(name, "unknown query parameter")
+		}
+
+		if len(v) > 1 {
+			return nil, badRequest(name, "must be a single value")
+		}
+
+		in.Fields[name] = structpb.NewStringValue(v[0])
+	}
+
+	return in, nil
+}
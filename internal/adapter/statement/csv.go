@@ -0,0 +1,48 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+func RenderCSV(w io.Writer, s bank.Statement) error {
+	cw := csv.NewWriter(w)
+
+	records := [][]string{
+		{"account_number", "account_name", "currency", "period_start", "period_end"},
+		{s.AccountNumber, s.AccountName, s.Currency, s.PeriodStart.Format(time.DateOnly),
+			s.PeriodEnd.Add(-time.Nanosecond).Format(time.DateOnly)},
+		{},
+		{"timestamp", "transaction_uuid", "description", "amount_in", "amount_out", "balance"},
+		{s.PeriodStart.Format(time.RFC3339), "", "Opening balance", "", "", formatAmount(s.OpeningBalance)},
+	}
+
+	for _, l := range s.Lines {
+		in, out := "", ""
+
+		if l.TransactionType == bank.TransactionTypeIN {
+			in = formatAmount(l.Amount)
+		} else {
+			out = formatAmount(l.Amount)
+		}
+
+		records = append(records, []string{
+			l.Timestamp.Format(time.RFC3339), l.TransactionUuid.String(), l.Notes, in, out,
+			formatAmount(l.RunningBalance),
+		})
+	}
+
+	records = append(records,
+		[]string{"", "", "Total", formatAmount(s.TotalIn), formatAmount(s.TotalOut), ""},
+		[]string{s.PeriodEnd.Format(time.RFC3339), "", "Closing balance", "", "", formatAmount(s.ClosingBalance)},
+	)
+
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
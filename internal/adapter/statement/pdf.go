@@ -0,0 +1,156 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+// A4 in points, text is set in the built-in Courier font so the columns line up without font metrics
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 8
+	pdfLineHeight   = 11
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+)
+
+func RenderPDF(w io.Writer, s bank.Statement) error {
+	return writePDF(w, paginate(statementLines(s)))
+}
+
+func statementLines(s bank.Statement) []string {
+	lines := []string{
+		"ACCOUNT STATEMENT",
+		"",
+		fmt.Sprintf("Account   : %s - %s", s.AccountNumber, s.AccountName),
+		fmt.Sprintf("Currency  : %s", s.Currency),
+		fmt.Sprintf("Period    : %s to %s", s.PeriodStart.Format(time.DateOnly),
+			s.PeriodEnd.Add(-time.Nanosecond).Format(time.DateOnly)),
+		fmt.Sprintf("Generated : %s", s.GeneratedAt.Format(time.RFC3339)),
+		"",
+		fmt.Sprintf("%-19s  %-30s %14s %14s %14s", "Date", "Description", "In", "Out", "Balance"),
+		strings.Repeat("-", 95),
+		fmt.Sprintf("%-19s  %-30s %14s %14s %14s", s.PeriodStart.Format(time.DateTime), "Opening balance", "", "",
+			formatAmount(s.OpeningBalance)),
+	}
+
+	for _, l := range s.Lines {
+		in, out := "", ""
+
+		if l.TransactionType == bank.TransactionTypeIN {
+			in = formatAmount(l.Amount)
+		} else {
+			out = formatAmount(l.Amount)
+		}
+
+		lines = append(lines, fmt.Sprintf("%-19s  %-30s %14s %14s %14s", l.Timestamp.Format(time.DateTime),
+			truncate(l.Notes, 30), in, out, formatAmount(l.RunningBalance)))
+	}
+
+	lines = append(lines,
+		strings.Repeat("-", 95),
+		fmt.Sprintf("%-19s  %-30s %14s %14s %14s", "", "Total", formatAmount(s.TotalIn), formatAmount(s.TotalOut), ""),
+		fmt.Sprintf("%-19s  %-30s %14s %14s %14s", s.PeriodEnd.Format(time.DateTime), "Closing balance", "", "",
+			formatAmount(s.ClosingBalance)),
+	)
+
+	return lines
+}
+
+func paginate(lines []string) [][]string {
+	var pages [][]string
+
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+
+	return append(pages, lines)
+}
+
+// writePDF writes a minimal PDF 1.4 document : catalog, page tree, one font and one content stream per page
+func writePDF(w io.Writer, pages [][]string) error {
+	var buf bytes.Buffer
+	var offsets []int
+
+	newObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// object 1 : catalog, object 2 : page tree, object 3 : font, then a page and a content object per page
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+
+	newObject("<< /Type /Catalog /Pages 2 0 R >>")
+	newObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	newObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		var content bytes.Buffer
+
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin,
+			pdfPageHeight-pdfMargin)
+
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", escapePDFText(line))
+		}
+
+		fmt.Fprintf(&content, "ET\nBT\n/F1 %d Tf\n%d %d Td\n(Page %d of %d) Tj\nET", pdfFontSize,
+			pdfPageWidth-pdfMargin-80, pdfMargin/2, i+1, len(pages))
+
+		newObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, 5+2*i))
+		newObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", o)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// escapePDFText escapes the string delimiters and replaces characters outside printable ASCII
+func escapePDFText(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n-3]) + "..."
+}
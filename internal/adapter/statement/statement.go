@@ -0,0 +1,91 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+const DefaultChunkSize = 32 * 1024
+
+// Render writes the statement in the given format (csv or pdf)
+func Render(w io.Writer, s bank.Statement, format string) error {
+	switch format {
+	case bank.StatementFormatCSV:
+		return RenderCSV(w, s)
+	case bank.StatementFormatPDF:
+		return RenderPDF(w, s)
+	default:
+		return fmt.Errorf("%w : %q", bank.ErrUnknownStatementFormat, format)
+	}
+}
+
+// ContentType is the media type of a rendered format
+func ContentType(format string) string {
+	switch format {
+	case bank.StatementFormatCSV:
+		return "text/csv"
+	case bank.StatementFormatPDF:
+		return "application/pdf"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// ChunkWriter buffers the rendered file and hands it to send in chunks of at most size bytes, e.g. one chunk per
+// message on a server streaming RPC. Flush must be called to send the last partial chunk
+type ChunkWriter struct {
+	size int
+	buf  []byte
+	send func(chunk []byte) error
+}
+
+func NewChunkWriter(size int, send func(chunk []byte) error) *ChunkWriter {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+
+	return &ChunkWriter{
+		size: size,
+		buf:  make([]byte, 0, size),
+		send: send,
+	}
+}
+
+func (c *ChunkWriter) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 {
+		n := min(c.size-len(c.buf), len(p))
+		c.buf = append(c.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		if len(c.buf) == c.size {
+			if err := c.Flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+func (c *ChunkWriter) Flush() error {
+	if len(c.buf) == 0 {
+		return nil
+	}
+
+	// send a copy, the buffer is reused for the next chunk
+	chunk := make([]byte, len(c.buf))
+	copy(chunk, c.buf)
+	c.buf = c.buf[:0]
+
+	return c.send(chunk)
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+const sendTimeout = 10 * time.Second
+
+// HttpSender posts webhook bodies with a plain http.Client, tests can point it at an httptest server
+type HttpSender struct {
+	client *http.Client
+}
+
+// NewHttpSender uses client, or a client with a 10 second timeout when client is nil
+func NewHttpSender(client *http.Client) *HttpSender {
+	if client == nil {
+		client = &http.Client{Timeout: sendTimeout}
+	}
+
+	return &HttpSender{
+		client: client,
+	}
+}
+
+func (s *HttpSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,330 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxAccountNameLength = 100
+
+type AccountService struct {
+	db            port.AccountDatabasePort
+	currencies    *bank.CurrencyRegistry
+	accountNumber bank.AccountNumberScheme
+	screening     *ScreeningService
+}
+
+func NewAccountService(dbPort port.AccountDatabasePort, currencies *bank.CurrencyRegistry,
+	accountNumber bank.AccountNumberScheme) *AccountService {
+	return &AccountService{
+		db:            dbPort,
+		currencies:    currencies,
+		accountNumber: accountNumber,
+	}
+}
+
+// WithScreeningService screens the account name against the sanctions list on creation and rename
+func (a *AccountService) WithScreeningService(s *ScreeningService) *AccountService {
+	a.screening = s
+	return a
+}
+
+func (a *AccountService) ValidateAccountNumber(accountNumber string) error {
+	return a.accountNumber.Validate(accountNumber)
+}
+
+func (a *AccountService) CreateAccount(na bank.NewAccount) (bank.Account, error) {
+	name := strings.TrimSpace(na.AccountName)
+	if name == "" || len(name) > maxAccountNameLength {
+		return bank.Account{}, bank.ErrInvalidAccountName
+	}
+
+	if err := a.currencies.Validate(na.Currency); err != nil {
+		return bank.Account{}, err
+	}
+
+	if na.InitialDepositAmount < 0 {
+		return bank.Account{}, bank.ErrInvalidInitialDeposit
+	}
+
+	product := na.Product
+	if product == "" {
+		product = bank.AccountProductCurrent
+	}
+
+	if product != bank.AccountProductCurrent && product != bank.AccountProductSavings {
+		return bank.Account{}, fmt.Errorf("%w : %q", bank.ErrUnknownAccountProduct, product)
+	}
+
+	accountUuid := uuid.New()
+
+	if a.screening != nil {
+		if err := a.screening.screen(bank.ScreeningOperationAccountCreate, accountUuid, bank.ScreeningParty{
+			Role: bank.ScreeningRoleAccountHolder,
+			Name: name,
+		}); err != nil {
+			return bank.Account{}, err
+		}
+	}
+
+	seq, err := a.db.NextAccountNumberSequence()
+	if err != nil {
+		return bank.Account{}, fmt.Errorf("can't generate account number : %v", err)
+	}
+
+	accountNumber, err := a.accountNumber.Generate(seq)
+	if err != nil {
+		return bank.Account{}, fmt.Errorf("can't generate account number : %v", err)
+	}
+
+	now := time.Now()
+
+	// the balance starts at zero, the initial deposit posting brings it to the deposit amount
+	accountOrm := database.BankAccountOrm{
+		AccountUuid:    accountUuid,
+		AccountNumber:  accountNumber,
+		AccountName:    name,
+		Currency:       na.Currency,
+		CurrentBalance: 0,
+		AccountStatus:  bank.AccountStatusActive,
+		AccountType:    bank.AccountTypeCustomer,
+		AccountTier:    bank.AccountTierStandard,
+		AccountProduct: product,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	var initialDeposit *database.LedgerEntry
+
+	if na.InitialDepositAmount > 0 {
+		cashAccountOrm, err := a.db.GetSystemAccount(bank.SystemAccountCash, na.Currency)
+		if err != nil {
+			return bank.Account{}, err
+		}
+
+		entry := newLedgerEntryBuilder(bank.JournalTypeDeposit, "Initial deposit to "+accountNumber, now)
+		entry.post(accountOrm, na.InitialDepositAmount, "Initial deposit")
+		entry.post(cashAccountOrm, -na.InitialDepositAmount, "Initial deposit")
+
+		ledgerEntry, err := entry.build()
+		if err != nil {
+			return bank.Account{}, err
+		}
+
+		initialDeposit = &ledgerEntry
+	}
+
+	event, err := newAccountEvent(bank.EventAccountCreated, accountOrm)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	if _, err := a.db.CreateBankAccount(accountOrm, initialDeposit, event); err != nil {
+		log.Printf("can't create account %v : %v\n", accountOrm.AccountNumber, err)
+		return bank.Account{}, err
+	}
+
+	accountOrm.CurrentBalance = na.InitialDepositAmount
+
+	return toAccount(accountOrm), nil
+}
+
+func (a *AccountService) FindAccountByNumber(accountNumber string) (bank.Account, error) {
+	accountOrm, err := a.findAccountOrm(accountNumber)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	return toAccount(accountOrm), nil
+}
+
+func (a *AccountService) FindAccountByUuid(accountUuid uuid.UUID) (bank.Account, error) {
+	accountOrm, err := a.db.GetBankAccountByUuid(accountUuid)
+	if err != nil {
+		return bank.Account{}, wrapAccountNotFound(accountUuid.String(), err)
+	}
+
+	return toAccount(accountOrm), nil
+}
+
+func (a *AccountService) RenameAccount(accountNumber string, name string) (bank.Account, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAccountNameLength {
+		return bank.Account{}, bank.ErrInvalidAccountName
+	}
+
+	accountOrm, err := a.findAccountOrm(accountNumber)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	if accountOrm.AccountStatus == bank.AccountStatusClosed {
+		return bank.Account{}, bank.ErrAccountClosed
+	}
+
+	if a.screening != nil {
+		if err := a.screening.screen(bank.ScreeningOperationAccountRename, accountOrm.AccountUuid,
+			bank.ScreeningParty{
+				Role:          bank.ScreeningRoleAccountHolder,
+				AccountNumber: accountOrm.AccountNumber,
+				Name:          name,
+			}); err != nil {
+			return bank.Account{}, err
+		}
+	}
+
+	accountOrm.AccountName = name
+
+	event, err := newAccountEvent(bank.EventAccountRenamed, accountOrm)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	if err := a.db.UpdateBankAccountName(accountOrm, name, event); err != nil {
+		return bank.Account{}, err
+	}
+
+	return toAccount(accountOrm), nil
+}
+
+func (a *AccountService) FreezeAccount(accountNumber string) (bank.Account, error) {
+	return a.changeStatus(accountNumber, bank.AccountStatusActive, bank.AccountStatusFrozen)
+}
+
+func (a *AccountService) UnfreezeAccount(accountNumber string) (bank.Account, error) {
+	return a.changeStatus(accountNumber, bank.AccountStatusFrozen, bank.AccountStatusActive)
+}
+
+func (a *AccountService) CloseAccount(accountNumber string) (bank.Account, error) {
+	accountOrm, err := a.findAccountOrm(accountNumber)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	if accountOrm.AccountStatus == bank.AccountStatusClosed {
+		return bank.Account{}, bank.ErrAccountClosed
+	}
+
+	if accountOrm.CurrentBalance != 0 {
+		return bank.Account{}, bank.ErrAccountBalanceNotZero
+	}
+
+	// postgres keeps microseconds, the event has the closing time as it is stored
+	now := time.Now().Truncate(time.Microsecond)
+	accountOrm.AccountStatus = bank.AccountStatusClosed
+	accountOrm.ClosedAt = &now
+
+	event, err := newAccountEvent(bank.EventAccountClosed, accountOrm)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	closedOrm, err := a.db.CloseBankAccount(accountOrm, event)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	return toAccount(closedOrm), nil
+}
+
+// SetOverdraftLimit sets how far the account may go below zero, it can't be lowered below the current overdrawn
+// balance
+func (a *AccountService) SetOverdraftLimit(accountNumber string, limit float64) (bank.Account, error) {
+	if limit < 0 {
+		return bank.Account{}, bank.ErrInvalidOverdraftLimit
+	}
+
+	accountOrm, err := a.findAccountOrm(accountNumber)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	if accountOrm.AccountStatus == bank.AccountStatusClosed {
+		return bank.Account{}, bank.ErrAccountClosed
+	}
+
+	accountOrm.OverdraftLimit = limit
+
+	event, err := newAccountEvent(bank.EventAccountOverdraftChanged, accountOrm)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	if err := a.db.UpdateBankAccountOverdraftLimit(accountOrm, limit, event); err != nil {
+		return bank.Account{}, err
+	}
+
+	return toAccount(accountOrm), nil
+}
+
+func (a *AccountService) changeStatus(accountNumber string, from string, to string) (bank.Account, error) {
+	accountOrm, err := a.findAccountOrm(accountNumber)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	if accountOrm.AccountStatus != from {
+		return bank.Account{}, fmt.Errorf("%w : account %v is %v, expected %v", bank.ErrInvalidAccountStatusChange,
+			accountNumber, accountOrm.AccountStatus, from)
+	}
+
+	accountOrm.AccountStatus = to
+
+	eventType := bank.EventAccountFrozen
+	if to == bank.AccountStatusActive {
+		eventType = bank.EventAccountUnfrozen
+	}
+
+	event, err := newAccountEvent(eventType, accountOrm)
+	if err != nil {
+		return bank.Account{}, err
+	}
+
+	if err := a.db.UpdateBankAccountStatus(accountOrm, to, event); err != nil {
+		return bank.Account{}, err
+	}
+
+	return toAccount(accountOrm), nil
+}
+
+func (a *AccountService) findAccountOrm(accountNumber string) (database.BankAccountOrm, error) {
+	accountOrm, err := a.db.GetBankAccountByAccountNumber(accountNumber)
+	if err != nil {
+		return accountOrm, wrapAccountNotFound(accountNumber, err)
+	}
+
+	return accountOrm, nil
+}
+
+func wrapAccountNotFound(key string, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w : %v", bank.ErrAccountNotFound, key)
+	}
+
+	return err
+}
+
+func toAccount(a database.BankAccountOrm) bank.Account {
+	return bank.Account{
+		AccountUuid:    a.AccountUuid,
+		AccountNumber:  a.AccountNumber,
+		AccountName:    a.AccountName,
+		Currency:       a.Currency,
+		CurrentBalance: a.CurrentBalance,
+		OverdraftLimit: a.OverdraftLimit,
+		Status:         a.AccountStatus,
+		Product:        a.AccountProduct,
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+		ClosedAt:       a.ClosedAt,
+	}
+}
@@ -0,0 +1,264 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+)
+
+const (
+	activityFeedBatchSize    = 500
+	activityWatcherQueueSize = 256
+)
+
+// ActivityFeed tails the outbox for the whole process and fans the account events out to the watchers, so a
+// watcher costs no database queries once it caught up. Polling is woken up early by Notify
+type ActivityFeed struct {
+	db   port.ActivityDatabasePort
+	wake chan struct{}
+
+	mu       sync.Mutex
+	position bank.ActivityCursor
+	started  bool
+	watchers map[string]map[*activityWatcher]struct{}
+}
+
+type activityWatcher struct {
+	events  chan database.OutboxFeedEventOrm
+	lagging bool
+}
+
+func NewActivityFeed(dbPort port.ActivityDatabasePort) *ActivityFeed {
+	return &ActivityFeed{
+		db:       dbPort,
+		wake:     make(chan struct{}, 1),
+		watchers: make(map[string]map[*activityWatcher]struct{}),
+	}
+}
+
+// Run polls the outbox every interval until ctx is done
+func (f *ActivityFeed) Run(ctx context.Context, interval time.Duration) error {
+	start, err := f.db.GetActivityFeedStart()
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.position = start
+	f.started = true
+	f.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-f.wake:
+		}
+
+		if err := f.poll(); err != nil {
+			log.Println("can't poll account activity", err)
+		}
+	}
+}
+
+// Notify wakes up the poller, e.g. on a postgres notification
+func (f *ActivityFeed) Notify() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (f *ActivityFeed) poll() error {
+	for {
+		f.mu.Lock()
+		position := f.position
+		f.mu.Unlock()
+
+		eventOrms, err := f.db.GetActivityFeedEvents(position, nil, "", "", activityFeedBatchSize)
+		if err != nil {
+			return err
+		}
+
+		f.mu.Lock()
+
+		for _, e := range eventOrms {
+			f.position = bank.ActivityCursor{TransactionId: e.TransactionId, EventId: e.EventId}
+
+			if e.AggregateType != bank.AggregateAccount || !isAccountActivity(e.EventType) {
+				continue
+			}
+
+			for w := range f.watchers[e.AggregateId] {
+				f.dispatch(e.AggregateId, w, e)
+			}
+		}
+
+		f.mu.Unlock()
+
+		if len(eventOrms) < activityFeedBatchSize {
+			return nil
+		}
+	}
+}
+
+// dispatch never blocks the poller, a watcher whose queue is full is dropped and has to resume from its cursor
+func (f *ActivityFeed) dispatch(accountNumber string, w *activityWatcher, e database.OutboxFeedEventOrm) {
+	select {
+	case w.events <- e:
+	default:
+		w.lagging = true
+		close(w.events)
+		delete(f.watchers[accountNumber], w)
+	}
+}
+
+// WatchAccount calls send for every new transaction and balance change of the account until ctx is done or send
+// fails. With a cursor it first replays what happened after the cursor, so a client reconnecting with the cursor of
+// the last item it got misses nothing
+func (f *ActivityFeed) WatchAccount(ctx context.Context, accountNumber string, cursor string,
+	send func(bank.AccountActivity) error) error {
+	if _, err := f.db.GetBankAccountByAccountNumber(accountNumber); err != nil {
+		return wrapAccountNotFound(accountNumber, err)
+	}
+
+	var after *bank.ActivityCursor
+
+	if cursor != "" {
+		c, err := bank.DecodeActivityCursor(cursor)
+		if err != nil {
+			return err
+		}
+
+		after = &c
+	}
+
+	w, position, err := f.register(accountNumber)
+	if err != nil {
+		return err
+	}
+	defer f.unregister(accountNumber, w)
+
+	// events up to position are replayed from the database, the later ones come from the poller
+	for after != nil && position.After(*after) {
+		eventOrms, err := f.db.GetActivityFeedEvents(*after, &position, bank.AggregateAccount, accountNumber,
+			activityFeedBatchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(eventOrms) == 0 {
+			break
+		}
+
+		for _, e := range eventOrms {
+			if err := sendActivity(e, send); err != nil {
+				return err
+			}
+		}
+
+		last := eventOrms[len(eventOrms)-1]
+		after = &bank.ActivityCursor{TransactionId: last.TransactionId, EventId: last.EventId}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-w.events:
+			if !ok {
+				return bank.ErrActivityFeedLagging
+			}
+
+			// a cursor from another replica can be ahead of this one
+			c := bank.ActivityCursor{TransactionId: e.TransactionId, EventId: e.EventId}
+			if after != nil && !c.After(*after) {
+				continue
+			}
+
+			if err := sendActivity(e, send); err != nil {
+				return err
+			}
+
+			after = &c
+		}
+	}
+}
+
+func (f *ActivityFeed) register(accountNumber string) (*activityWatcher, bank.ActivityCursor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.started {
+		return nil, bank.ActivityCursor{}, bank.ErrActivityFeedNotRunning
+	}
+
+	w := &activityWatcher{
+		events: make(chan database.OutboxFeedEventOrm, activityWatcherQueueSize),
+	}
+
+	if f.watchers[accountNumber] == nil {
+		f.watchers[accountNumber] = make(map[*activityWatcher]struct{})
+	}
+
+	f.watchers[accountNumber][w] = struct{}{}
+
+	return w, f.position, nil
+}
+
+func (f *ActivityFeed) unregister(accountNumber string, w *activityWatcher) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if w.lagging {
+		return
+	}
+
+	delete(f.watchers[accountNumber], w)
+
+	if len(f.watchers[accountNumber]) == 0 {
+		delete(f.watchers, accountNumber)
+	}
+}
+
+func isAccountActivity(eventType string) bool {
+	return eventType == bank.EventTransactionCreated || eventType == bank.EventAccountBalanceChanged
+}
+
+func sendActivity(e database.OutboxFeedEventOrm, send func(bank.AccountActivity) error) error {
+	if !isAccountActivity(e.EventType) {
+		return nil
+	}
+
+	activity := bank.AccountActivity{
+		Cursor:    bank.ActivityCursor{TransactionId: e.TransactionId, EventId: e.EventId}.Encode(),
+		EventUuid: e.EventUuid,
+		EventType: e.EventType,
+		CreatedAt: e.CreatedAt,
+	}
+
+	switch e.EventType {
+	case bank.EventTransactionCreated:
+		activity.Transaction = &bank.TransactionEventPayload{}
+		if err := json.Unmarshal([]byte(e.Payload), activity.Transaction); err != nil {
+			return err
+		}
+	case bank.EventAccountBalanceChanged:
+		activity.Balance = &bank.BalanceEventPayload{}
+		if err := json.Unmarshal([]byte(e.Payload), activity.Balance); err != nil {
+			return err
+		}
+	}
+
+	return send(activity)
+}
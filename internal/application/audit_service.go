@@ -0,0 +1,228 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+)
+
+const auditVerifyBatchSize = 1000
+
+// AuditService appends the audit log and verifies its hash chain
+type AuditService struct {
+	db port.AuditDatabasePort
+}
+
+func NewAuditService(dbPort port.AuditDatabasePort) *AuditService {
+	return &AuditService{
+		db: dbPort,
+	}
+}
+
+// Record appends the entry with the actor, method and request id of ctx. The operation already happened, so a
+// record that can't be written is logged instead of failing the caller
+func (a *AuditService) Record(ctx context.Context, entry bank.AuditEntry) {
+	ac := bank.AuditContextFrom(ctx)
+
+	record := bank.AuditRecord{
+		AuditUuid:  uuid.New(),
+		Actor:      ac.RecordedActor(),
+		Method:     ac.Method,
+		RequestId:  ac.RequestId,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityIds:  entry.EntityIds,
+		Outcome:    bank.AuditOutcomeSuccess,
+		CreatedAt:  time.Now().UTC().Truncate(time.Microsecond), // postgres keeps microseconds, the hash must match
+	}
+
+	if record.EntityIds == nil {
+		record.EntityIds = []string{}
+	}
+
+	if entry.Err != nil {
+		record.Outcome = bank.AuditOutcomeFailure
+		record.Error = entry.Err.Error()
+	}
+
+	var err error
+
+	if record.Before, err = auditValue(entry.Before); err == nil {
+		record.After, err = auditValue(entry.After)
+	}
+
+	if err == nil {
+		_, err = a.db.AppendAuditRecord(func(sequence int64, prevHash string) (database.AuditLogOrm, error) {
+			record.Sequence = sequence
+			record.PrevHash = prevHash
+
+			hash, err := record.ComputeHash()
+			if err != nil {
+				return database.AuditLogOrm{}, err
+			}
+
+			record.Hash = hash
+
+			return toAuditLogOrm(record), nil
+		})
+	}
+
+	if err != nil {
+		log.Printf("can't write audit record %v %v by %v (request %v) : %v\n", record.Action,
+			strings.Join(record.EntityIds, ","), record.Actor, record.RequestId, err)
+	}
+}
+
+func (a *AuditService) FindAuditRecords(filter bank.AuditFilter) (bank.AuditPage, error) {
+	if !filter.StartTimestamp.IsZero() && !filter.EndTimestamp.IsZero() &&
+		filter.EndTimestamp.Before(filter.StartTimestamp) {
+		return bank.AuditPage{}, fmt.Errorf("%w : %v", bank.ErrInvalidAuditFilter, bank.ErrInvalidTimeRange)
+	}
+
+	var after int64
+
+	if filter.PageToken != "" {
+		sequence, err := bank.DecodeAuditPageToken(filter.PageToken)
+		if err != nil {
+			return bank.AuditPage{}, err
+		}
+
+		after = sequence
+	}
+
+	pageSize := normalizePageSize(filter.PageSize)
+
+	// one extra row tells whether there is a next page
+	recordOrms, err := a.db.GetAuditRecords(filter, after, pageSize+1)
+	if err != nil {
+		return bank.AuditPage{}, err
+	}
+
+	var page bank.AuditPage
+
+	if len(recordOrms) > pageSize {
+		recordOrms = recordOrms[:pageSize]
+		page.NextPageToken = bank.EncodeAuditPageToken(recordOrms[pageSize-1].Sequence)
+	}
+
+	for _, r := range recordOrms {
+		page.Records = append(page.Records, toAuditRecord(r))
+	}
+
+	return page, nil
+}
+
+// VerifyAuditChain recomputes every hash from the first record on and stops at the first mismatch or gap
+func (a *AuditService) VerifyAuditChain() (bank.AuditChainCheck, error) {
+	var check bank.AuditChainCheck
+	var prevHash string
+	var after int64
+
+	for {
+		recordOrms, err := a.db.GetAuditRecords(bank.AuditFilter{}, after, auditVerifyBatchSize)
+		if err != nil {
+			return check, err
+		}
+
+		for _, r := range recordOrms {
+			record := toAuditRecord(r)
+			reason := ""
+
+			switch hash, err := record.ComputeHash(); {
+			case err != nil:
+				return check, err
+			case record.Sequence != after+1:
+				reason = fmt.Sprintf("records %d to %d are missing", after+1, record.Sequence-1)
+			case record.PrevHash != prevHash:
+				reason = "previous hash doesn't match"
+			case hash != record.Hash:
+				reason = "hash doesn't match the record"
+			}
+
+			if reason != "" {
+				brokenAt := after + 1
+				check.BrokenAt = &brokenAt
+				check.Reason = reason
+
+				return check, nil
+			}
+
+			check.Records++
+			prevHash = record.Hash
+			after = record.Sequence
+		}
+
+		if len(recordOrms) < auditVerifyBatchSize {
+			check.Valid = true
+			return check, nil
+		}
+	}
+}
+
+// auditValue marshals a before or after value, nil is stored as JSON null. A value JSON can't hold (e.g. the NaN
+// amount of a rejected request) is stored as its Go representation so the attempt is still recorded
+func auditValue(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return json.RawMessage("null"), nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return json.Marshal(fmt.Sprintf("%+v", v))
+	}
+
+	return data, nil
+}
+
+func toAuditLogOrm(r bank.AuditRecord) database.AuditLogOrm {
+	return database.AuditLogOrm{
+		Sequence:    r.Sequence,
+		AuditUuid:   r.AuditUuid,
+		Actor:       r.Actor,
+		Method:      r.Method,
+		RequestId:   r.RequestId,
+		Action:      r.Action,
+		EntityType:  r.EntityType,
+		EntityIds:   strings.Join(r.EntityIds, ","),
+		BeforeValue: string(r.Before),
+		AfterValue:  string(r.After),
+		Outcome:     r.Outcome,
+		Error:       r.Error,
+		CreatedAt:   r.CreatedAt,
+		PrevHash:    r.PrevHash,
+		Hash:        r.Hash,
+	}
+}
+
+func toAuditRecord(r database.AuditLogOrm) bank.AuditRecord {
+	entityIds := []string{}
+	if r.EntityIds != "" {
+		entityIds = strings.Split(r.EntityIds, ",")
+	}
+
+	return bank.AuditRecord{
+		Sequence:   r.Sequence,
+		AuditUuid:  r.AuditUuid,
+		Actor:      r.Actor,
+		Method:     r.Method,
+		RequestId:  r.RequestId,
+		Action:     r.Action,
+		EntityType: r.EntityType,
+		EntityIds:  entityIds,
+		Before:     json.RawMessage(r.BeforeValue),
+		After:      json.RawMessage(r.AfterValue),
+		Outcome:    r.Outcome,
+		Error:      r.Error,
+		CreatedAt:  r.CreatedAt,
+		PrevHash:   r.PrevHash,
+		Hash:       r.Hash,
+	}
+}
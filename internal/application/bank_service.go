@@ -1,8 +1,10 @@
 package application
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
@@ -12,15 +14,83 @@ import (
 )
 
 type BankService struct {
-	db port.BankDatabasePort
+	db         port.BankDatabasePort
+	converter  *CurrencyConverter
+	currencies *bank.CurrencyRegistry
+	limits     *LimitService
+	fees       *FeeService
+	risk       port.RiskEvaluatorPort
+	screening  *ScreeningService
+	reviewers  map[string]bool
 }
 
 func NewBankService(dbPort port.BankDatabasePort) *BankService {
 	return &BankService{
-		db: dbPort,
+		db:         dbPort,
+		converter:  NewCurrencyConverter(dbPort, defaultConversionPrecision),
+		currencies: bank.DefaultCurrencyRegistry(),
 	}
 }
 
+// WithCurrencyConverter replaces the default converter, e.g. to change the precision or pivot currencies
+func (b *BankService) WithCurrencyConverter(c *CurrencyConverter) *BankService {
+	b.converter = c
+	return b
+}
+
+// WithCurrencyRegistry replaces the default ISO 4217 registry, e.g. to disable some currencies
+func (b *BankService) WithCurrencyRegistry(r *bank.CurrencyRegistry) *BankService {
+	b.currencies = r
+	return b
+}
+
+// WithLimitService enables the account limits on withdrawals and transfers, without it only the balance is checked
+func (b *BankService) WithLimitService(l *LimitService) *BankService {
+	b.limits = l
+	return b
+}
+
+// WithFeeService charges transfer fees from the fee schedule, without it transfers are free
+func (b *BankService) WithFeeService(f *FeeService) *BankService {
+	b.fees = f
+	return b
+}
+
+// WithRiskEvaluator scores transfers and withdrawals before anything is written, without it everything is allowed
+func (b *BankService) WithRiskEvaluator(r port.RiskEvaluatorPort) *BankService {
+	b.risk = r
+	return b
+}
+
+// WithScreeningService screens both parties of every transfer against the sanctions list
+func (b *BankService) WithScreeningService(s *ScreeningService) *BankService {
+	b.screening = s
+	return b
+}
+
+// WithTransferReviewers sets the actors allowed to approve or reject held transfers, without it no one can
+func (b *BankService) WithTransferReviewers(reviewers ...string) *BankService {
+	b.reviewers = make(map[string]bool, len(reviewers))
+
+	for _, r := range reviewers {
+		b.reviewers[r] = true
+	}
+
+	return b
+}
+
+func (b *BankService) ValidateCurrency(code string) error {
+	return b.currencies.Validate(code)
+}
+
+func (b *BankService) validateCurrencyPair(fromCur string, toCur string) error {
+	if err := b.currencies.Validate(fromCur); err != nil {
+		return err
+	}
+
+	return b.currencies.Validate(toCur)
+}
+
 func (b *BankService) FindCurrentBalance(account string) (float64, error) {
 	bankAccount, err := b.db.GetBankAccountByAccountNumber(account)
 	if err != nil {
@@ -32,6 +102,10 @@ func (b *BankService) FindCurrentBalance(account string) (float64, error) {
 }
 
 func (b *BankService) CreateExchangeRate(r bank.ExchangeRate) (uuid.UUID, error) {
+	if err := b.validateCurrencyPair(r.FromCurrency, r.ToCurrency); err != nil {
+		return uuid.Nil, err
+	}
+
 	newUuid := uuid.New()
 	now := time.Now()
 
@@ -44,48 +118,126 @@ func (b *BankService) CreateExchangeRate(r bank.ExchangeRate) (uuid.UUID, error)
 		ValidToTimestamp:   r.ValidToTimestamp,
 		CreatedAt:          now,
 	}
-	return b.db.CreateExchangeRate(&exchangeRateOrm)
+
+	event, err := newOutboxEvent(bank.EventExchangeRateCreated, bank.AggregateExchangeRate,
+		r.FromCurrency+"/"+r.ToCurrency, bank.ExchangeRateEventPayload{
+			FromCurrency:       r.FromCurrency,
+			ToCurrency:         r.ToCurrency,
+			Rate:               r.Rate,
+			ValidFromTimestamp: r.ValidFromTimestamp,
+			ValidToTimestamp:   r.ValidToTimestamp,
+		})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return b.db.CreateExchangeRate(&exchangeRateOrm, event)
 }
 
 func (b *BankService) FindExchangeRate(fromCur string, toCur string, ts time.Time) (float64, error) {
-	exchangeRate, err := b.db.GetExchangeRateAtTimestamp(fromCur, toCur, ts)
+	conversion, err := b.ConvertCurrency(fromCur, toCur, 1, ts)
 	if err != nil {
 		return 0, err
 	}
 
-	return float64(exchangeRate.Rate), nil
+	return conversion.Rate, nil
+}
+
+func (b *BankService) ConvertCurrency(fromCur string, toCur string, amount float64, ts time.Time) (bank.Conversion, error) {
+	if err := b.validateCurrencyPair(fromCur, toCur); err != nil {
+		return bank.Conversion{}, err
+	}
+
+	return b.converter.Convert(fromCur, toCur, amount, ts)
 }
 
 func (b *BankService) CreateTransaction(acct string, t bank.Transaction) (uuid.UUID, error) {
-	newUUID := uuid.New()
 	now := time.Now()
 
+	if err := bank.CheckAmount(t.Amount); err != nil {
+		return uuid.Nil, err
+	}
+
 	bankAccountOrm, err := b.db.GetBankAccountByAccountNumber(acct)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("can't find account number %v : %v", acct, err.Error())
 	}
 
-	if t.TransactionType == bank.TransactionTypeOUT && bankAccountOrm.CurrentBalance < t.Amount {
+	if err := bank.CheckAccountStatus(bankAccountOrm.AccountStatus); err != nil {
+		return bankAccountOrm.AccountUuid, fmt.Errorf("can't create transaction on account %v : %w", acct, err)
+	}
+
+	if err := b.currencies.ValidateAmount(bankAccountOrm.Currency, t.Amount); err != nil {
+		return bankAccountOrm.AccountUuid, err
+	}
+
+	// the overdraft limit is what the account may go below zero
+	if t.TransactionType == bank.TransactionTypeOUT &&
+		bankAccountOrm.CurrentBalance+bankAccountOrm.OverdraftLimit < t.Amount {
 		return bankAccountOrm.AccountUuid, fmt.Errorf(
-			"insufficient account balance %v for [out] transaction amount %v",
-			bankAccountOrm.CurrentBalance, t.Amount,
+			"insufficient account balance %v (overdraft limit %v) for [out] transaction amount %v",
+			bankAccountOrm.CurrentBalance, bankAccountOrm.OverdraftLimit, t.Amount,
 		)
 	}
 
-	transactionOrm := database.BankTransactionOrm{
-		TransactionUuid:      newUUID,
-		AccountUuid:          bankAccountOrm.AccountUuid,
-		TransactionTimestamp: now,
-		Amount:               t.Amount,
-		TransactionType:      t.TransactionType,
-		Notes:                t.Notes,
-		CreatedAt:            now,
-		UpdatedAt:            now,
+	riskReq := bank.RiskRequest{
+		Operation:     bank.RiskOperationDeposit,
+		AccountNumber: acct,
+		Currency:      bankAccountOrm.Currency,
+		Amount:        t.Amount,
+		Timestamp:     now,
 	}
 
-	savedUUID, err := b.db.CreateTransaction(bankAccountOrm, transactionOrm)
+	if t.TransactionType == bank.TransactionTypeOUT {
+		riskReq.Operation = bank.RiskOperationWithdrawal
+	}
 
-	return savedUUID, err
+	// transactions can't be held, a REVIEW is recorded against the transaction once it is posted
+	assessment, err := b.evaluateRisk(riskReq)
+	if err != nil {
+		return bankAccountOrm.AccountUuid, err
+	}
+
+	// deposits and withdrawals are balanced against the system cash account of the account currency
+	cashAccountOrm, err := b.db.GetSystemAccount(bank.SystemAccountCash, bankAccountOrm.Currency)
+	if err != nil {
+		return bankAccountOrm.AccountUuid, err
+	}
+
+	var entry *ledgerEntryBuilder
+	amount := t.Amount
+
+	switch t.TransactionType {
+	case bank.TransactionTypeIN:
+		entry = newLedgerEntryBuilder(bank.JournalTypeDeposit, "Deposit to "+acct, now)
+	case bank.TransactionTypeOUT:
+		entry = newLedgerEntryBuilder(bank.JournalTypeWithdrawal, "Withdrawal from "+acct, now)
+		amount = -t.Amount
+	default:
+		return bankAccountOrm.AccountUuid, fmt.Errorf("unknown transaction type %v", t.TransactionType)
+	}
+
+	transactionUuid := entry.post(bankAccountOrm, amount, t.Notes)
+	entry.post(cashAccountOrm, -amount, t.Notes)
+
+	ledgerEntry, err := entry.build()
+	if err != nil {
+		return bankAccountOrm.AccountUuid, err
+	}
+
+	if t.TransactionType == bank.TransactionTypeOUT && b.limits != nil {
+		ledgerEntry.Limit = b.limits.outgoingLimit(bankAccountOrm, t.Amount, uuid.Nil, now)
+	}
+
+	if err := b.db.PostLedgerEntry(ledgerEntry); err != nil {
+		return bankAccountOrm.AccountUuid, err
+	}
+
+	if assessment.Decision == bank.RiskDecisionReview {
+		b.recordRiskAssessment(riskReq, assessment, &transactionUuid)
+	}
+
+	return transactionUuid, nil
 }
 
 func (b *BankService) CalculateTransactionSummary(tcur *bank.TransactionSummary, trans bank.Transaction) error {
@@ -104,75 +256,737 @@ func (b *BankService) CalculateTransactionSummary(tcur *bank.TransactionSummary,
 	return nil
 }
 
-func (b *BankService) Transfer(tt bank.TransferTransaction) (uuid.UUID, bool, error) {
+// QuoteTransfer previews a transfer without moving money : the converted amounts and the fee it would be charged
+func (b *BankService) QuoteTransfer(tt bank.TransferTransaction) (bank.TransferQuote, error) {
+	_, _, quote, err := b.prepareTransfer(tt, time.Now())
+	return quote, err
+}
+
+// Transfer records the transfer as PENDING, authorizes it once the balance and limits allow it and settles it by
+// posting its journal entry. A transfer that can't be settled ends FAILED with the reason. With a risk evaluator, a
+// denied transfer is not recorded and a transfer to review is recorded as HELD until it is approved or rejected
+func (b *BankService) Transfer(tt bank.TransferTransaction) (bank.TransferResult, error) {
 	now := time.Now()
 
-	fromAccountOrm, err := b.db.GetBankAccountByAccountNumber(tt.FromAccountNumber)
+	fromAccountOrm, toAccountOrm, quote, err := b.prepareTransfer(tt, now)
 	if err != nil {
-		log.Printf("can't find account for this account number %v : %v", tt.FromAccountNumber, err)
-		return uuid.Nil, false, bank.ErrTransferSourceAccountNotFound
+		return bank.TransferResult{}, err
 	}
 
-	if fromAccountOrm.CurrentBalance < tt.Amount {
-		return uuid.Nil, false, bank.ErrTransferTransactionPair
+	result := bank.TransferResult{
+		Quote: quote,
 	}
 
-	toAccountOrm, err := b.db.GetBankAccountByAccountNumber(tt.ToAccountNumber)
-	if err != nil {
-		log.Printf("can't find account for this account number %v : %v", tt.ToAccountNumber, err)
-		return uuid.Nil, false, bank.ErrTransferDestinationAccountNotFound
+	riskReq := bank.RiskRequest{
+		Operation:                 bank.RiskOperationTransfer,
+		AccountNumber:             tt.FromAccountNumber,
+		CounterpartyAccountNumber: tt.ToAccountNumber,
+		Currency:                  fromAccountOrm.Currency,
+		Amount:                    quote.DebitAmount,
+		Timestamp:                 now,
 	}
 
-	fromTransactionOrm := database.BankTransactionOrm{
-		TransactionUuid:      uuid.New(),
-		TransactionTimestamp: now,
-		TransactionType:      bank.TransactionTypeOUT,
-		AccountUuid:          fromAccountOrm.AccountUuid,
-		Amount:               tt.Amount,
-		Notes:                "Transfer out to " + tt.ToAccountNumber,
-		CreatedAt:            now,
-		UpdatedAt:            now,
+	transferUuid := uuid.New()
+
+	if b.screening != nil {
+		if err := b.screening.screen(bank.ScreeningOperationTransfer, transferUuid,
+			bank.ScreeningParty{
+				Role:          bank.ScreeningRoleSender,
+				AccountNumber: fromAccountOrm.AccountNumber,
+				Name:          fromAccountOrm.AccountName,
+			},
+			bank.ScreeningParty{
+				Role:          bank.ScreeningRoleRecipient,
+				AccountNumber: toAccountOrm.AccountNumber,
+				Name:          toAccountOrm.AccountName,
+			},
+		); err != nil {
+			return result, err
+		}
 	}
 
-	toTransactionOrm := database.BankTransactionOrm{
-		TransactionUuid:      uuid.New(),
-		TransactionTimestamp: now,
-		TransactionType:      bank.TransactionTypeIN,
-		AccountUuid:          toAccountOrm.AccountUuid,
-		Amount:               tt.Amount,
-		Notes:                "Transfer in from " + tt.FromAccountNumber,
-		CreatedAt:            now,
-		UpdatedAt:            now,
+	assessment, err := b.evaluateRisk(riskReq)
+	if err != nil {
+		return result, err
 	}
 
-	// create transfer request
-	newTransferUuid := uuid.New()
+	var feeCurrency *string
+	if quote.Fee > 0 {
+		feeCurrency = &fromAccountOrm.Currency
+	}
 
-	// 'TransferSuccess - false' => assume transfer failed when creating record for the first time
 	transferOrm := database.BankTransferOrm{
-		TransferUuid:      newTransferUuid,
+		TransferUuid:      transferUuid,
 		FromAccountUuid:   fromAccountOrm.AccountUuid,
 		ToAccountUuid:     toAccountOrm.AccountUuid,
 		Currency:          tt.Currency,
 		Amount:            tt.Amount,
 		TransferTimestamp: now,
 		TransferSuccess:   false,
+		TransferStatus:    bank.TransferStatusPending,
+		FeeAmount:         quote.Fee,
+		FeeCurrency:       feeCurrency,
 		CreatedAt:         now,
 		UpdatedAt:         now,
 	}
 
+	if tt.RequestedBy != "" {
+		transferOrm.RequestedBy = &tt.RequestedBy
+	}
+
+	if assessment.Decision == bank.RiskDecisionReview {
+		transferOrm.TransferStatus = bank.TransferStatusHeld
+
+		if err := b.db.CreateHeldTransfer(transferOrm, toRiskAssessmentOrm(riskReq, assessment,
+			&transferOrm.TransferUuid)); err != nil {
+			log.Printf("can't hold transfer from %v to %v : %v", tt.FromAccountNumber, tt.ToAccountNumber, err)
+			return result, bank.ErrTransferRecordFailed
+		}
+
+		log.Printf("transfer %v held for review : score %d (%v)\n", transferOrm.TransferUuid, assessment.Score,
+			strings.Join(assessment.Rules(), ", "))
+
+		result.TransferUuid = transferOrm.TransferUuid
+		result.Held = true
+
+		return result, nil
+	}
+
 	if _, err := b.db.CreateTransfer(transferOrm); err != nil {
 		log.Printf("can't create transfer from %v to %v : %v", tt.FromAccountNumber, tt.ToAccountNumber, err)
-		return uuid.Nil, false, bank.ErrTransferRecordFailed
+		return result, bank.ErrTransferRecordFailed
+	}
+
+	result.TransferUuid = transferOrm.TransferUuid
+
+	if err := b.settleTransfer(transferOrm, tt, fromAccountOrm, toAccountOrm, quote, bank.TransferStatusPending,
+		now); err != nil {
+		return result, err
+	}
+
+	result.Success = true
+
+	return result, nil
+}
+
+// settleTransfer checks the balance and limits of a PENDING or HELD transfer, authorizes it and settles it
+func (b *BankService) settleTransfer(transferOrm database.BankTransferOrm, tt bank.TransferTransaction,
+	fromAccountOrm database.BankAccountOrm, toAccountOrm database.BankAccountOrm, quote bank.TransferQuote,
+	from string, now time.Time) error {
+	if fromAccountOrm.CurrentBalance+fromAccountOrm.OverdraftLimit < quote.DebitAmount+quote.Fee {
+		return b.failTransfer(transferOrm, tt, from,
+			fmt.Errorf("%w : %w", bank.ErrTransferTransactionPair, bank.ErrInsufficientFunds))
+	}
+
+	ledgerEntry, err := b.buildTransferEntry(transferOrm.TransferUuid, tt, fromAccountOrm, toAccountOrm, quote, now)
+	if err != nil {
+		return b.failTransfer(transferOrm, tt, from, fmt.Errorf("%w : %w", bank.ErrTransferTransactionPair, err))
+	}
+
+	// the limits are checked in the transaction that settles the transfer, with the accounts locked
+	if b.limits != nil {
+		ledgerEntry.Limit = b.limits.outgoingLimit(fromAccountOrm, quote.DebitAmount+quote.Fee,
+			transferOrm.TransferUuid, now)
 	}
 
-	// create transaction pair. If transaction pair created successfully, update 'TransferSuccess - true' otherwise update it to false
-	if transferPairSuccess, _ := b.db.CreateTransferTransactionPair(fromAccountOrm, toAccountOrm, fromTransactionOrm,
-		toTransactionOrm); transferPairSuccess {
-		b.db.UpdateTransferStatus(transferOrm, true) // handle error
-		return newTransferUuid, true, nil
-	} else {
-		return uuid.Nil, false, bank.ErrTransferTransactionPair
+	if err := b.db.UpdateTransferState(transferOrm, from, bank.TransferStatusAuthorized, ""); err != nil {
+		return b.failTransfer(transferOrm, tt, from, err)
+	}
+
+	// the journal entry is posted and the transfer settled in one database transaction
+	if err := b.db.SettleTransfer(transferOrm, ledgerEntry); err != nil {
+		if errors.Is(err, bank.ErrLimitExceeded) {
+			return b.failTransfer(transferOrm, tt, bank.TransferStatusAuthorized, err)
+		}
+
+		log.Printf("can't settle transfer %v : %v", transferOrm.TransferUuid, err)
+		return b.failTransfer(transferOrm, tt, bank.TransferStatusAuthorized,
+			fmt.Errorf("%w : %w", bank.ErrTransferTransactionPair, err))
+	}
+
+	return nil
+}
+
+// failTransfer records why the transfer failed and returns the reason
+func (b *BankService) failTransfer(transferOrm database.BankTransferOrm, tt bank.TransferTransaction, from string,
+	reason error) error {
+	event, err := newTransferEvent(bank.EventTransferFailed, database.BankTransferDetailOrm{
+		BankTransferOrm:   transferOrm,
+		FromAccountNumber: tt.FromAccountNumber,
+		ToAccountNumber:   tt.ToAccountNumber,
+	}, bank.TransferStatusFailed, reason.Error())
+	if err != nil {
+		return reason
+	}
+
+	if err := b.db.UpdateTransferState(transferOrm, from, bank.TransferStatusFailed, reason.Error(),
+		event); err != nil {
+		log.Printf("transfer %v failed (%v) but status not updated : %v", transferOrm.TransferUuid, reason, err)
+	}
+
+	return reason
+}
+
+func (b *BankService) buildTransferEntry(transferUuid uuid.UUID, tt bank.TransferTransaction,
+	fromAccountOrm database.BankAccountOrm, toAccountOrm database.BankAccountOrm, quote bank.TransferQuote,
+	now time.Time) (database.LedgerEntry, error) {
+	entry := newLedgerEntryBuilder(bank.JournalTypeTransfer,
+		fmt.Sprintf("Transfer %v from %v to %v", transferUuid, tt.FromAccountNumber, tt.ToAccountNumber), now).
+		reference(transferUuid)
+
+	entry.post(fromAccountOrm, -quote.DebitAmount, "Transfer out to "+tt.ToAccountNumber)
+	entry.post(toAccountOrm, quote.CreditAmount, "Transfer in from "+tt.FromAccountNumber)
+
+	// cross currency transfers go through the FX system accounts so each currency balances on its own
+	if fromAccountOrm.Currency != toAccountOrm.Currency {
+		fromFxOrm, err := b.db.GetSystemAccount(bank.SystemAccountFX, fromAccountOrm.Currency)
+		if err != nil {
+			return database.LedgerEntry{}, err
+		}
+
+		toFxOrm, err := b.db.GetSystemAccount(bank.SystemAccountFX, toAccountOrm.Currency)
+		if err != nil {
+			return database.LedgerEntry{}, err
+		}
+
+		entry.post(fromFxOrm, quote.DebitAmount, "")
+		entry.post(toFxOrm, -quote.CreditAmount, "")
+	}
+
+	// the fee is a separate [out] transaction on the source account, credited to the fees system account
+	if quote.Fee > 0 {
+		feesOrm, err := b.db.GetSystemAccount(bank.SystemAccountFees, fromAccountOrm.Currency)
+		if err != nil {
+			return database.LedgerEntry{}, err
+		}
+
+		entry.post(fromAccountOrm, -quote.Fee, fmt.Sprintf("Transfer fee for transfer to %v", tt.ToAccountNumber))
+		entry.post(feesOrm, quote.Fee, "")
+	}
+
+	entry.emit(bank.EventTransferSettled, bank.AggregateAccount, tt.FromAccountNumber, bank.TransferEventPayload{
+		TransferUuid:      transferUuid,
+		FromAccountNumber: tt.FromAccountNumber,
+		ToAccountNumber:   tt.ToAccountNumber,
+		Currency:          tt.Currency,
+		Amount:            tt.Amount,
+		DebitAmount:       quote.DebitAmount,
+		CreditAmount:      quote.CreditAmount,
+		Fee:               quote.Fee,
+		Status:            bank.TransferStatusSettled,
+		Timestamp:         now,
+	})
+
+	return entry.build()
+}
+
+// prepareTransfer validates the transfer and its accounts and computes the amounts and the fee
+func (b *BankService) prepareTransfer(tt bank.TransferTransaction, now time.Time) (database.BankAccountOrm,
+	database.BankAccountOrm, bank.TransferQuote, error) {
+	var fromAccountOrm, toAccountOrm database.BankAccountOrm
+
+	if err := bank.CheckAmount(tt.Amount); err != nil {
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{}, err
 	}
 
+	if err := b.currencies.Validate(tt.Currency); err != nil {
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{}, err
+	}
+
+	if err := b.currencies.ValidateAmount(tt.Currency, tt.Amount); err != nil {
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{}, err
+	}
+
+	if tt.FromAccountNumber == tt.ToAccountNumber {
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{},
+			fmt.Errorf("%w : %w", bank.ErrTransferTransactionPair, bank.ErrSameAccountTransfer)
+	}
+
+	fromAccountOrm, err := b.db.GetBankAccountByAccountNumber(tt.FromAccountNumber)
+	if err != nil {
+		log.Printf("can't find account for this account number %v : %v", tt.FromAccountNumber, err)
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{}, bank.ErrTransferSourceAccountNotFound
+	}
+
+	if err := bank.CheckAccountStatus(fromAccountOrm.AccountStatus); err != nil {
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{},
+			fmt.Errorf("source account %v : %w", tt.FromAccountNumber, err)
+	}
+
+	toAccountOrm, err = b.db.GetBankAccountByAccountNumber(tt.ToAccountNumber)
+	if err != nil {
+		log.Printf("can't find account for this account number %v : %v", tt.ToAccountNumber, err)
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{}, bank.ErrTransferDestinationAccountNotFound
+	}
+
+	if err := bank.CheckAccountStatus(toAccountOrm.AccountStatus); err != nil {
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{},
+			fmt.Errorf("destination account %v : %w", tt.ToAccountNumber, err)
+	}
+
+	// the transfer amount is in tt.Currency, each side is debited / credited in its own account currency
+	debitAmount, err := b.convertAmount(tt.Currency, fromAccountOrm.Currency, tt.Amount, now)
+	if err != nil {
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{},
+			fmt.Errorf("%w : %w", bank.ErrTransferTransactionPair, err)
+	}
+
+	creditAmount, err := b.convertAmount(tt.Currency, toAccountOrm.Currency, tt.Amount, now)
+	if err != nil {
+		return fromAccountOrm, toAccountOrm, bank.TransferQuote{},
+			fmt.Errorf("%w : %w", bank.ErrTransferTransactionPair, err)
+	}
+
+	quote := bank.TransferQuote{
+		FromAccountNumber: tt.FromAccountNumber,
+		ToAccountNumber:   tt.ToAccountNumber,
+		Currency:          tt.Currency,
+		Amount:            tt.Amount,
+		DebitCurrency:     fromAccountOrm.Currency,
+		DebitAmount:       debitAmount,
+		CreditCurrency:    toAccountOrm.Currency,
+		CreditAmount:      creditAmount,
+	}
+
+	if b.fees != nil {
+		quote.Fee, quote.FeeComponents, err = b.fees.transferFee(b.currencies, fromAccountOrm.Currency,
+			debitAmount, fromAccountOrm.Currency != toAccountOrm.Currency, now)
+		if err != nil {
+			return fromAccountOrm, toAccountOrm, bank.TransferQuote{}, err
+		}
+	}
+
+	return fromAccountOrm, toAccountOrm, quote, nil
+}
+
+// convertAmount converts with the rate valid at ts and rounds to the minor units of toCur
+func (b *BankService) convertAmount(fromCur string, toCur string, amount float64, ts time.Time) (float64, error) {
+	if fromCur == toCur {
+		return amount, nil
+	}
+
+	conversion, err := b.converter.Convert(fromCur, toCur, amount, ts)
+	if err != nil {
+		return 0, err
+	}
+
+	return b.currencies.Round(toCur, conversion.ConvertedAmount), nil
+}
+
+// VerifyLedgerBalance compares the stored balance of an account with the sum of its ledger postings
+func (b *BankService) VerifyLedgerBalance(account string) (bank.BalanceCheck, error) {
+	bankAccountOrm, err := b.db.GetBankAccountByAccountNumber(account)
+	if err != nil {
+		return bank.BalanceCheck{}, fmt.Errorf("%w : %v", bank.ErrAccountNotFound, account)
+	}
+
+	ledgerBalance, err := b.db.GetLedgerBalance(bankAccountOrm.AccountUuid)
+	if err != nil {
+		return bank.BalanceCheck{}, err
+	}
+
+	return bank.BalanceCheck{
+		AccountNumber: account,
+		StoredBalance: bankAccountOrm.CurrentBalance,
+		LedgerBalance: ledgerBalance,
+		Difference:    bankAccountOrm.CurrentBalance - ledgerBalance,
+	}, nil
+}
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+var candleIntervalBuckets = map[string]string{
+	bank.CandleIntervalMinute: "minute",
+	bank.CandleIntervalHour:   "hour",
+	bank.CandleIntervalDay:    "day",
+}
+
+func normalizePageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return defaultPageSize
+	}
+
+	if pageSize > maxPageSize {
+		return maxPageSize
+	}
+
+	return pageSize
+}
+
+func (b *BankService) FindExchangeRateHistory(fromCur string, toCur string, start time.Time, end time.Time,
+	pageToken string, pageSize int) (bank.ExchangeRatePage, error) {
+	var page bank.ExchangeRatePage
+
+	if err := b.validateCurrencyPair(fromCur, toCur); err != nil {
+		return page, err
+	}
+
+	if !start.Before(end) {
+		return page, bank.ErrInvalidTimeRange
+	}
+
+	var cursor *bank.ExchangeRateCursor
+
+	if pageToken != "" {
+		c, err := bank.DecodeExchangeRateCursor(pageToken)
+		if err != nil {
+			return page, err
+		}
+
+		cursor = &c
+	}
+
+	pageSize = normalizePageSize(pageSize)
+
+	// fetch one extra row to know whether there is a next page
+	exchangeRateOrms, err := b.db.GetExchangeRatesInRange(fromCur, toCur, start, end, cursor, pageSize+1)
+	if err != nil {
+		return page, err
+	}
+
+	if len(exchangeRateOrms) > pageSize {
+		exchangeRateOrms = exchangeRateOrms[:pageSize]
+		last := exchangeRateOrms[pageSize-1]
+
+		page.NextPageToken = bank.ExchangeRateCursor{
+			ValidFrom:        last.ValidFromTimestamp,
+			ExchangeRateUuid: last.ExchangeRateUuid,
+		}.Encode()
+	}
+
+	page.Rates = make([]bank.ExchangeRate, 0, len(exchangeRateOrms))
+	for _, r := range exchangeRateOrms {
+		page.Rates = append(page.Rates, toExchangeRate(r))
+	}
+
+	return page, nil
+}
+
+func (b *BankService) FindExchangeRateCandles(fromCur string, toCur string, interval string, start time.Time,
+	end time.Time, after time.Time, pageSize int) (bank.ExchangeRateCandlePage, error) {
+	var page bank.ExchangeRateCandlePage
+
+	if err := b.validateCurrencyPair(fromCur, toCur); err != nil {
+		return page, err
+	}
+
+	bucket, ok := candleIntervalBuckets[interval]
+	if !ok {
+		return page, bank.ErrInvalidCandleInterval
+	}
+
+	if !start.Before(end) {
+		return page, bank.ErrInvalidTimeRange
+	}
+
+	pageSize = normalizePageSize(pageSize)
+
+	candleOrms, err := b.db.GetExchangeRateCandles(fromCur, toCur, bucket, start, end, after, pageSize+1)
+	if err != nil {
+		return page, err
+	}
+
+	if len(candleOrms) > pageSize {
+		candleOrms = candleOrms[:pageSize]
+		page.NextPageTimestamp = candleOrms[pageSize-1].BucketStart
+	}
+
+	page.Candles = make([]bank.ExchangeRateCandle, 0, len(candleOrms))
+	for _, c := range candleOrms {
+		page.Candles = append(page.Candles, bank.ExchangeRateCandle{
+			FromCurrency: fromCur,
+			ToCurrency:   toCur,
+			Interval:     interval,
+			BucketStart:  c.BucketStart,
+			Open:         c.Open,
+			High:         c.High,
+			Low:          c.Low,
+			Close:        c.Close,
+			Count:        c.Count,
+		})
+	}
+
+	return page, nil
+}
+
+func (b *BankService) ListTransactions(filter bank.TransactionFilter) (bank.TransactionPage, error) {
+	var page bank.TransactionPage
+
+	if filter.TransactionType != "" && filter.TransactionType != bank.TransactionTypeIN &&
+		filter.TransactionType != bank.TransactionTypeOUT {
+		return page, bank.ErrInvalidTransactionType
+	}
+
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return page, bank.ErrInvalidAmountRange
+	}
+
+	if !filter.StartTimestamp.IsZero() && !filter.EndTimestamp.IsZero() &&
+		!filter.StartTimestamp.Before(filter.EndTimestamp) {
+		return page, bank.ErrInvalidTimeRange
+	}
+
+	var cursor *bank.TransactionCursor
+
+	if filter.PageToken != "" {
+		c, err := bank.DecodeTransactionCursor(filter.PageToken)
+		if err != nil {
+			return page, err
+		}
+
+		cursor = &c
+	}
+
+	bankAccountOrm, err := b.db.GetBankAccountByAccountNumber(filter.AccountNumber)
+	if err != nil {
+		return page, fmt.Errorf("%w : %v", bank.ErrAccountNotFound, filter.AccountNumber)
+	}
+
+	pageSize := normalizePageSize(filter.PageSize)
+
+	transactionOrms, err := b.db.ListTransactions(bankAccountOrm.AccountUuid, filter, cursor, pageSize+1)
+	if err != nil {
+		return page, err
+	}
+
+	if len(transactionOrms) > pageSize {
+		transactionOrms = transactionOrms[:pageSize]
+		last := transactionOrms[pageSize-1]
+
+		page.NextPageToken = bank.TransactionCursor{
+			Timestamp:       last.TransactionTimestamp,
+			TransactionUuid: last.TransactionUuid,
+		}.Encode()
+	}
+
+	page.Transactions = make([]bank.Transaction, 0, len(transactionOrms))
+	for _, t := range transactionOrms {
+		page.Transactions = append(page.Transactions, toTransaction(t))
+	}
+
+	return page, nil
+}
+
+func toTransaction(t database.BankTransactionOrm) bank.Transaction {
+	return bank.Transaction{
+		TransactionUuid: t.TransactionUuid,
+		Amount:          t.Amount,
+		Timestamp:       t.TransactionTimestamp,
+		TransactionType: t.TransactionType,
+		Notes:           t.Notes,
+	}
+}
+
+func (b *BankService) GetTransfer(transferUuid uuid.UUID) (bank.Transfer, error) {
+	transferOrm, err := b.db.GetTransferDetail(transferUuid)
+	if err != nil {
+		return bank.Transfer{}, wrapNotFound(bank.ErrTransferNotFound, transferUuid, err)
+	}
+
+	return toTransfer(transferOrm), nil
+}
+
+func (b *BankService) ListTransfers(filter bank.TransferFilter) (bank.TransferPage, error) {
+	var page bank.TransferPage
+
+	if filter.Direction != "" && filter.Direction != bank.TransferDirectionSent &&
+		filter.Direction != bank.TransferDirectionReceived {
+		return page, bank.ErrInvalidTransferDirection
+	}
+
+	for _, s := range filter.Statuses {
+		if !bank.ValidTransferStatus(s) {
+			return page, fmt.Errorf("%w : %v", bank.ErrInvalidTransferStatus, s)
+		}
+	}
+
+	if !filter.StartTimestamp.IsZero() && !filter.EndTimestamp.IsZero() &&
+		!filter.StartTimestamp.Before(filter.EndTimestamp) {
+		return page, bank.ErrInvalidTimeRange
+	}
+
+	var cursor *bank.TransactionCursor
+
+	if filter.PageToken != "" {
+		c, err := bank.DecodeTransactionCursor(filter.PageToken)
+		if err != nil {
+			return page, err
+		}
+
+		cursor = &c
+	}
+
+	bankAccountOrm, err := b.db.GetBankAccountByAccountNumber(filter.AccountNumber)
+	if err != nil {
+		return page, fmt.Errorf("%w : %v", bank.ErrAccountNotFound, filter.AccountNumber)
+	}
+
+	pageSize := normalizePageSize(filter.PageSize)
+
+	transferOrms, err := b.db.ListTransfers(bankAccountOrm.AccountUuid, filter, cursor, pageSize+1)
+	if err != nil {
+		return page, err
+	}
+
+	if len(transferOrms) > pageSize {
+		transferOrms = transferOrms[:pageSize]
+		last := transferOrms[pageSize-1]
+
+		page.NextPageToken = bank.TransactionCursor{
+			Timestamp:       last.TransferTimestamp,
+			TransactionUuid: last.TransferUuid,
+		}.Encode()
+	}
+
+	page.Transfers = make([]bank.Transfer, 0, len(transferOrms))
+	for _, t := range transferOrms {
+		page.Transfers = append(page.Transfers, toTransfer(t))
+	}
+
+	return page, nil
+}
+
+func toTransfer(t database.BankTransferDetailOrm) bank.Transfer {
+	transfer := bank.Transfer{
+		TransferUuid:      t.TransferUuid,
+		FromAccountNumber: t.FromAccountNumber,
+		ToAccountNumber:   t.ToAccountNumber,
+		Currency:          t.Currency,
+		Amount:            t.Amount,
+		FeeAmount:         t.FeeAmount,
+		Status:            t.TransferStatus,
+		FailureReason:     t.FailureReason,
+		TransferTimestamp: t.TransferTimestamp,
+		AuthorizedAt:      t.AuthorizedAt,
+		SettledAt:         t.SettledAt,
+		FailedAt:          t.FailedAt,
+		ReversedAt:        t.ReversedAt,
+		ReversalReason:    t.ReversalReason,
+	}
+
+	if t.FeeCurrency != nil {
+		transfer.FeeCurrency = *t.FeeCurrency
+	}
+
+	return transfer
+}
+
+// FindTransactionSummary returns the persisted summary of one day (UTC), a day without transactions has a zero summary
+func (b *BankService) FindTransactionSummary(account string, date time.Time) (bank.TransactionSummary, error) {
+	summaries, err := b.FindTransactionSummaries(account, date, date)
+	if err != nil {
+		return bank.TransactionSummary{}, err
+	}
+
+	if len(summaries) == 0 {
+		return bank.TransactionSummary{
+			AccountNumber: account,
+			SummaryOnDate: toDate(date),
+		}, nil
+	}
+
+	return summaries[0], nil
+}
+
+// FindTransactionSummaries returns one summary per day that has transactions, between both dates (inclusive)
+func (b *BankService) FindTransactionSummaries(account string, startDate time.Time,
+	endDate time.Time) ([]bank.TransactionSummary, error) {
+	startDate, endDate = toDate(startDate), toDate(endDate)
+
+	if endDate.Before(startDate) {
+		return nil, bank.ErrInvalidTimeRange
+	}
+
+	bankAccountOrm, err := b.db.GetBankAccountByAccountNumber(account)
+	if err != nil {
+		return nil, fmt.Errorf("%w : %v", bank.ErrAccountNotFound, account)
+	}
+
+	summaryOrms, err := b.db.GetDailySummaries(bankAccountOrm.AccountUuid, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]bank.TransactionSummary, 0, len(summaryOrms))
+	for _, s := range summaryOrms {
+		summaries = append(summaries, bank.TransactionSummary{
+			AccountNumber:    account,
+			SummaryOnDate:    s.SummaryDate,
+			SumIn:            s.SumIn,
+			SumOut:           s.SumOut,
+			SumTotal:         s.SumIn - s.SumOut,
+			TransactionCount: s.TransactionCount,
+		})
+	}
+
+	return summaries, nil
+}
+
+// RebuildTransactionSummaries recomputes all daily summaries from bank_transactions and returns the number of rows
+func (b *BankService) RebuildTransactionSummaries() (int64, error) {
+	rows, err := b.db.RebuildDailySummaries()
+	if err != nil {
+		log.Println("error on 'rebuild transaction summaries'", err)
+		return 0, err
+	}
+
+	log.Printf("transaction summaries rebuilt : %d rows\n", rows)
+
+	return rows, nil
+}
+
+// toDate truncates to midnight UTC, summaries are stored per UTC day
+func toDate(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// GenerateStatement builds the statement of [start, end). The opening balance is derived backwards from the current
+// balance and the transactions made since the start of the period
+func (b *BankService) GenerateStatement(account string, start time.Time, end time.Time) (bank.Statement, error) {
+	if !start.Before(end) {
+		return bank.Statement{}, bank.ErrInvalidTimeRange
+	}
+
+	// the balance and the transactions are read together, see GetStatementSnapshot
+	snapshot, err := b.db.GetStatementSnapshot(account, start, end)
+	if err != nil {
+		return bank.Statement{}, wrapAccountNotFound(account, err)
+	}
+
+	statement := bank.Statement{
+		AccountNumber:  snapshot.Account.AccountNumber,
+		AccountName:    snapshot.Account.AccountName,
+		Currency:       snapshot.Account.Currency,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		OpeningBalance: snapshot.Account.CurrentBalance - snapshot.NetSinceStart,
+		Lines:          make([]bank.StatementLine, 0, len(snapshot.Transactions)),
+		GeneratedAt:    time.Now(),
+	}
+
+	balance := statement.OpeningBalance
+
+	for _, t := range snapshot.Transactions {
+		switch t.TransactionType {
+		case bank.TransactionTypeIN:
+			balance += t.Amount
+			statement.TotalIn += t.Amount
+		case bank.TransactionTypeOUT:
+			balance -= t.Amount
+			statement.TotalOut += t.Amount
+		}
+
+		statement.Lines = append(statement.Lines, bank.StatementLine{
+			Transaction:    toTransaction(t),
+			RunningBalance: balance,
+		})
+	}
+
+	statement.ClosingBalance = balance
+
+	return statement, nil
 }
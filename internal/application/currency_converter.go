@@ -0,0 +1,149 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"gorm.io/gorm"
+)
+
+const defaultConversionPrecision = 6
+
+var defaultPivotCurrencies = []string{"USD"}
+
+// CurrencyConverter derives a rate for a currency pair from the stored exchange rates, either directly,
+// by inverting the opposite pair or by going through a pivot currency
+type CurrencyConverter struct {
+	db        port.BankDatabasePort
+	precision int
+	pivots    []string
+}
+
+func NewCurrencyConverter(dbPort port.BankDatabasePort, precision int, pivots ...string) *CurrencyConverter {
+	if precision < 0 {
+		precision = defaultConversionPrecision
+	}
+
+	if len(pivots) == 0 {
+		pivots = defaultPivotCurrencies
+	}
+
+	return &CurrencyConverter{
+		db:        dbPort,
+		precision: precision,
+		pivots:    pivots,
+	}
+}
+
+func (c *CurrencyConverter) Convert(fromCur string, toCur string, amount float64, ts time.Time) (bank.Conversion, error) {
+	conversion := bank.Conversion{
+		FromCurrency: fromCur,
+		ToCurrency:   toCur,
+		Amount:       amount,
+		Timestamp:    ts,
+	}
+
+	if fromCur == toCur {
+		conversion.Rate = 1
+		conversion.ConvertedAmount = amount
+		conversion.Path = bank.ConversionPathDirect
+		return conversion, nil
+	}
+
+	// try the stored pair first, then its inverse
+	rate, source, path, ok, err := c.findPairRate(fromCur, toCur, ts)
+	if err != nil {
+		return conversion, err
+	}
+
+	if ok {
+		conversion.Rate = rate
+		conversion.ConvertedAmount = c.round(amount * rate)
+		conversion.Path = path
+		conversion.SourceRates = []bank.ExchangeRate{source}
+		return conversion, nil
+	}
+
+	// go through each pivot currency, e.g. EUR -> USD -> IDR
+	for _, pivot := range c.pivots {
+		if pivot == fromCur || pivot == toCur {
+			continue
+		}
+
+		firstRate, firstSource, _, ok, err := c.findPairRate(fromCur, pivot, ts)
+		if err != nil {
+			return conversion, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		secondRate, secondSource, _, ok, err := c.findPairRate(pivot, toCur, ts)
+		if err != nil {
+			return conversion, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		// keep full precision on the rates and only round the converted amount
+		rate := firstRate * secondRate
+
+		conversion.Rate = rate
+		conversion.ConvertedAmount = c.round(amount * rate)
+		conversion.Path = bank.ConversionPathTriangulated
+		conversion.PivotCurrency = pivot
+		conversion.SourceRates = []bank.ExchangeRate{firstSource, secondSource}
+		return conversion, nil
+	}
+
+	return conversion, fmt.Errorf("%w : %v to %v at %v", bank.ErrExchangeRateNotFound, fromCur, toCur,
+		ts.Format(time.RFC3339))
+}
+
+// findPairRate returns the rate to convert fromCur into toCur using either the stored pair or its inverse. Only a
+// missing rate is a miss, any other database error is returned
+func (c *CurrencyConverter) findPairRate(fromCur string, toCur string, ts time.Time) (float64, bank.ExchangeRate,
+	string, bool, error) {
+	r, err := c.db.GetExchangeRateAtTimestamp(fromCur, toCur, ts)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, bank.ExchangeRate{}, "", false, fmt.Errorf("can't read %v/%v rate : %w", fromCur, toCur, err)
+	}
+
+	if err == nil && r.Rate != 0 {
+		return r.Rate, toExchangeRate(r), bank.ConversionPathDirect, true, nil
+	}
+
+	r, err = c.db.GetExchangeRateAtTimestamp(toCur, fromCur, ts)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, bank.ExchangeRate{}, "", false, fmt.Errorf("can't read %v/%v rate : %w", toCur, fromCur, err)
+	}
+
+	if err == nil && r.Rate != 0 {
+		return 1 / r.Rate, toExchangeRate(r), bank.ConversionPathInverse, true, nil
+	}
+
+	return 0, bank.ExchangeRate{}, "", false, nil
+}
+
+func (c *CurrencyConverter) round(v float64) float64 {
+	p := math.Pow10(c.precision)
+	return math.Round(v*p) / p
+}
+
+func toExchangeRate(r database.BankExchangeRateOrm) bank.ExchangeRate {
+	return bank.ExchangeRate{
+		FromCurrency:       r.FromCurrency,
+		ToCurrency:         r.ToCurrency,
+		Rate:               r.Rate,
+		ValidFromTimestamp: r.ValidFromTimestamp,
+		ValidToTimestamp:   r.ValidToTimestamp,
+	}
+}
@@ -0,0 +1,63 @@
+package bank
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AccountStatusActive string = "ACTIVE"
+	AccountStatusFrozen string = "FROZEN"
+	AccountStatusClosed string = "CLOSED"
+)
+
+// savings accounts are paid CREDIT interest on their positive balance, current accounts are not
+const (
+	AccountProductCurrent string = "CURRENT"
+	AccountProductSavings string = "SAVINGS"
+)
+
+type Account struct {
+	AccountUuid    uuid.UUID
+	AccountNumber  string
+	AccountName    string
+	Currency       string
+	CurrentBalance float64
+	OverdraftLimit float64
+	Status         string
+	Product        string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	ClosedAt       *time.Time
+}
+
+// NewAccount opens a CURRENT account unless Product says otherwise
+type NewAccount struct {
+	AccountName          string
+	Currency             string
+	InitialDepositAmount float64
+	Product              string
+}
+
+var ErrAccountNotFound = errors.New("account not found")
+var ErrAccountFrozen = errors.New("account is frozen")
+var ErrAccountClosed = errors.New("account is closed")
+var ErrAccountBalanceNotZero = errors.New("account balance must be zero to close the account")
+var ErrInvalidAccountName = errors.New("account name must be between 1 and 100 characters")
+var ErrInvalidInitialDeposit = errors.New("initial deposit amount can't be negative")
+var ErrInvalidAccountStatusChange = errors.New("invalid account status change")
+var ErrUnknownAccountProduct = errors.New("unknown account product, use CURRENT or SAVINGS")
+
+// CheckAccountStatus returns nil when the account can be used for transactions
+func CheckAccountStatus(status string) error {
+	switch status {
+	case AccountStatusFrozen:
+		return ErrAccountFrozen
+	case AccountStatusClosed:
+		return ErrAccountClosed
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// AccountNumberScheme generates account numbers from a sequence value and validates them without a database
+// round-trip
+type AccountNumberScheme interface {
+	Generate(seq int64) (string, error)
+	Validate(accountNumber string) error
+}
+
+var ErrInvalidAccountNumber = errors.New("invalid account number")
+var ErrAccountNumbersExhausted = errors.New("account number sequence exhausted")
+
+// LuhnAccountNumberScheme builds prefix + zero padded sequence + 1 Luhn check digit, e.g. 7835 69701 7
+type LuhnAccountNumberScheme struct {
+	Prefix         string
+	SequenceDigits int
+}
+
+// Mod97AccountNumberScheme builds prefix + zero padded sequence + 2 check digits (ISO 7064 MOD 97-10, as used by
+// IBAN), which also catches most transpositions of two digits
+type Mod97AccountNumberScheme struct {
+	Prefix         string
+	SequenceDigits int
+}
+
+// GrandfatheredAccountNumberScheme generates and validates the numbers with the scheme, except the legacy numbers
+// issued before it which are accepted as they are
+type GrandfatheredAccountNumberScheme struct {
+	AccountNumberScheme
+	legacy map[string]struct{}
+}
+
+func NewGrandfatheredAccountNumberScheme(scheme AccountNumberScheme,
+	legacyNumbers ...string) GrandfatheredAccountNumberScheme {
+	legacy := make(map[string]struct{}, len(legacyNumbers))
+	for _, n := range legacyNumbers {
+		legacy[n] = struct{}{}
+	}
+
+	return GrandfatheredAccountNumberScheme{
+		AccountNumberScheme: scheme,
+		legacy:              legacy,
+	}
+}
+
+func (s GrandfatheredAccountNumberScheme) Validate(accountNumber string) error {
+	if _, ok := s.legacy[accountNumber]; ok {
+		return nil
+	}
+
+	return s.AccountNumberScheme.Validate(accountNumber)
+}
+
+// DefaultAccountNumberScheme matches the length and prefix of the seeded accounts (10 digits). The 5 sequence digits
+// cap it at 99999 : since the sequence starts at 69701 (migration 010) about 30 300 accounts can be opened before
+// Generate fails with ErrAccountNumbersExhausted. Going past that needs more sequence digits, with the numbers issued
+// so far grandfathered like the legacy ones
+func DefaultAccountNumberScheme() AccountNumberScheme {
+	return LuhnAccountNumberScheme{
+		Prefix:         "7835",
+		SequenceDigits: 5,
+	}
+}
+
+func (s LuhnAccountNumberScheme) Generate(seq int64) (string, error) {
+	body, err := accountNumberBody(s.Prefix, s.SequenceDigits, seq)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%d", body, luhnCheckDigit(body)), nil
+}
+
+func (s LuhnAccountNumberScheme) Validate(accountNumber string) error {
+	if err := validateAccountNumberShape(accountNumber, s.Prefix, s.SequenceDigits+1); err != nil {
+		return err
+	}
+
+	body := accountNumber[:len(accountNumber)-1]
+	check := int(accountNumber[len(accountNumber)-1] - '0')
+
+	if luhnCheckDigit(body) != check {
+		return fmt.Errorf("%w : check digit mismatch on %v", ErrInvalidAccountNumber, accountNumber)
+	}
+
+	return nil
+}
+
+func (s Mod97AccountNumberScheme) Generate(seq int64) (string, error) {
+	body, err := accountNumberBody(s.Prefix, s.SequenceDigits, seq)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%02d", body, mod97CheckDigits(body)), nil
+}
+
+func (s Mod97AccountNumberScheme) Validate(accountNumber string) error {
+	if err := validateAccountNumberShape(accountNumber, s.Prefix, s.SequenceDigits+2); err != nil {
+		return err
+	}
+
+	body := accountNumber[:len(accountNumber)-2]
+	check := fmt.Sprintf("%02d", mod97CheckDigits(body))
+
+	if accountNumber[len(accountNumber)-2:] != check {
+		return fmt.Errorf("%w : check digits mismatch on %v", ErrInvalidAccountNumber, accountNumber)
+	}
+
+	return nil
+}
+
+func accountNumberBody(prefix string, sequenceDigits int, seq int64) (string, error) {
+	body := fmt.Sprintf("%s%0*d", prefix, sequenceDigits, seq)
+
+	if seq < 0 || len(body) != len(prefix)+sequenceDigits {
+		return "", fmt.Errorf("%w : sequence %v doesn't fit in %v digits", ErrAccountNumbersExhausted, seq,
+			sequenceDigits)
+	}
+
+	return body, nil
+}
+
+// validateAccountNumberShape checks everything except the check digits : only digits, the prefix and the length
+func validateAccountNumberShape(accountNumber string, prefix string, suffixDigits int) error {
+	if len(accountNumber) != len(prefix)+suffixDigits {
+		return fmt.Errorf("%w : expected %v digits, got %q", ErrInvalidAccountNumber, len(prefix)+suffixDigits,
+			accountNumber)
+	}
+
+	for _, r := range accountNumber {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("%w : only digits are allowed, got %q", ErrInvalidAccountNumber, accountNumber)
+		}
+	}
+
+	if !strings.HasPrefix(accountNumber, prefix) {
+		return fmt.Errorf("%w : expected prefix %v, got %q", ErrInvalidAccountNumber, prefix, accountNumber)
+	}
+
+	return nil
+}
+
+func luhnCheckDigit(digits string) int {
+	sum := 0
+	double := true
+
+	// walk from the rightmost digit, the check digit itself will be appended to the right
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return (10 - sum%10) % 10
+}
+
+// mod97CheckDigits returns 98 - (body * 100 mod 97), so that body + check digits mod 97 == 1
+func mod97CheckDigits(digits string) int {
+	n, _ := new(big.Int).SetString(digits+"00", 10)
+	rem := new(big.Int).Mod(n, big.NewInt(97))
+
+	return 98 - int(rem.Int64())
+}
@@ -0,0 +1,141 @@
+package bank
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLuhnAccountNumberSchemeGenerate(t *testing.T) {
+	scheme := DefaultAccountNumberScheme()
+
+	tests := []struct {
+		name string
+		seq  int64
+		want string
+		err  error
+	}{
+		{name: "first sequence value", seq: 69701, want: "7835697017"},
+		{name: "zero padded", seq: 1, want: "7835000014"},
+		{name: "last value that fits", seq: 99999, want: "7835999991"},
+		{name: "too many digits", seq: 100000, err: ErrAccountNumbersExhausted},
+		{name: "negative", seq: -1, err: ErrAccountNumbersExhausted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scheme.Generate(tt.seq)
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Generate(%d) error = %v, want %v", tt.seq, err, tt.err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Generate(%d) = %q, want %q", tt.seq, got, tt.want)
+			}
+
+			if tt.err == nil {
+				if err := scheme.Validate(got); err != nil {
+					t.Errorf("Validate(%q) = %v, want nil", got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLuhnAccountNumberSchemeValidate(t *testing.T) {
+	scheme := DefaultAccountNumberScheme()
+
+	tests := []struct {
+		name          string
+		accountNumber string
+		valid         bool
+	}{
+		{name: "valid", accountNumber: "7835697017", valid: true},
+		{name: "wrong check digit", accountNumber: "7835697018"},
+		{name: "one digit changed", accountNumber: "7835697117"},
+		{name: "adjacent digits swapped", accountNumber: "7835679017"},
+		{name: "other prefix", accountNumber: "1234697017"},
+		{name: "too short", accountNumber: "783569701"},
+		{name: "too long", accountNumber: "78356970170"},
+		{name: "not only digits", accountNumber: "78356970a7"},
+		{name: "empty", accountNumber: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := scheme.Validate(tt.accountNumber)
+
+			if tt.valid && err != nil {
+				t.Errorf("Validate(%q) = %v, want nil", tt.accountNumber, err)
+			}
+
+			if !tt.valid && !errors.Is(err, ErrInvalidAccountNumber) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.accountNumber, err, ErrInvalidAccountNumber)
+			}
+		})
+	}
+}
+
+func TestMod97AccountNumberScheme(t *testing.T) {
+	scheme := Mod97AccountNumberScheme{Prefix: "7835", SequenceDigits: 5}
+
+	tests := []struct {
+		name          string
+		seq           int64
+		want          string
+		transposition string
+	}{
+		{name: "first sequence value", seq: 69701, want: "78356970153", transposition: "78356907153"},
+		{name: "zero padded", seq: 1, want: "78350000121", transposition: "78350001021"},
+		{name: "mixed digits", seq: 12345, want: "78351234543", transposition: "78351243543"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scheme.Generate(tt.seq)
+			if err != nil {
+				t.Fatalf("Generate(%d) error = %v", tt.seq, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Generate(%d) = %q, want %q", tt.seq, got, tt.want)
+			}
+
+			if err := scheme.Validate(got); err != nil {
+				t.Errorf("Validate(%q) = %v, want nil", got, err)
+			}
+
+			if err := scheme.Validate(tt.transposition); !errors.Is(err, ErrInvalidAccountNumber) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.transposition, err, ErrInvalidAccountNumber)
+			}
+		})
+	}
+}
+
+func TestGrandfatheredAccountNumberScheme(t *testing.T) {
+	scheme := NewGrandfatheredAccountNumberScheme(DefaultAccountNumberScheme(), "7835697001")
+
+	tests := []struct {
+		name          string
+		accountNumber string
+		valid         bool
+	}{
+		{name: "legacy number", accountNumber: "7835697001", valid: true},
+		{name: "number of the scheme", accountNumber: "7835697017", valid: true},
+		{name: "neither", accountNumber: "7835697002"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := scheme.Validate(tt.accountNumber)
+
+			if tt.valid && err != nil {
+				t.Errorf("Validate(%q) = %v, want nil", tt.accountNumber, err)
+			}
+
+			if !tt.valid && !errors.Is(err, ErrInvalidAccountNumber) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.accountNumber, err, ErrInvalidAccountNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package bank
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidActivityCursor = errors.New("invalid activity cursor")
+var ErrActivityFeedNotRunning = errors.New("account activity feed is not running")
+var ErrActivityFeedLagging = errors.New("account activity watcher fell behind, reconnect with the last cursor")
+
+// ActivityCursor is the position of an event in the activity feed. Events are ordered by the id of the database
+// transaction that wrote them, then by event id, which is the order in which they become visible
+type ActivityCursor struct {
+	TransactionId int64
+	EventId       int64
+}
+
+func (c ActivityCursor) After(o ActivityCursor) bool {
+	return c.TransactionId > o.TransactionId || (c.TransactionId == o.TransactionId && c.EventId > o.EventId)
+}
+
+func (c ActivityCursor) Encode() string {
+	raw := strconv.FormatInt(c.TransactionId, 10) + "|" + strconv.FormatInt(c.EventId, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeActivityCursor(token string) (ActivityCursor, error) {
+	var cursor ActivityCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, fmt.Errorf("%w : %v", ErrInvalidActivityCursor, err)
+	}
+
+	txid, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return cursor, ErrInvalidActivityCursor
+	}
+
+	if cursor.TransactionId, err = strconv.ParseInt(txid, 10, 64); err != nil {
+		return cursor, fmt.Errorf("%w : %v", ErrInvalidActivityCursor, err)
+	}
+
+	if cursor.EventId, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return cursor, fmt.Errorf("%w : %v", ErrInvalidActivityCursor, err)
+	}
+
+	return cursor, nil
+}
+
+// AccountActivity is one item of the account feed, either a new transaction or a balance change. Cursor resumes the
+// feed right after this item
+type AccountActivity struct {
+	Cursor      string
+	EventUuid   uuid.UUID
+	EventType   string
+	Transaction *TransactionEventPayload
+	Balance     *BalanceEventPayload
+	CreatedAt   time.Time
+}
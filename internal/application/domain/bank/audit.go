@@ -0,0 +1,186 @@
+package bank
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionAccountCreate      string = "account.create"
+	AuditActionAccountRename      string = "account.rename"
+	AuditActionAccountFreeze      string = "account.freeze"
+	AuditActionAccountUnfreeze    string = "account.unfreeze"
+	AuditActionAccountClose       string = "account.close"
+	AuditActionAccountOverdraft   string = "account.set_overdraft_limit"
+	AuditActionAccountAdjust      string = "account.adjust_balance"
+	AuditActionAccountTier        string = "account.set_tier"
+	AuditActionAccountLimits      string = "account.set_limits"
+	AuditActionTransactionCreate  string = "transaction.create"
+	AuditActionTransactionReverse string = "transaction.reverse"
+	AuditActionTransferExecute    string = "transfer.execute"
+	AuditActionTransferApprove    string = "transfer.approve"
+	AuditActionTransferReject     string = "transfer.reject"
+	AuditActionTransferReverse    string = "transfer.reverse"
+	AuditActionScheduleCreate     string = "scheduled_transfer.create"
+	AuditActionScheduleCancel     string = "scheduled_transfer.cancel"
+	AuditActionExchangeRateCreate string = "exchange_rate.create"
+	AuditActionWebhookCreate      string = "webhook_subscription.create"
+	AuditActionWebhookDisable     string = "webhook_subscription.disable"
+	AuditActionWebhookRedeliver   string = "webhook_delivery.redeliver"
+)
+
+const (
+	AuditEntityAccount      string = "account"
+	AuditEntityTransaction  string = "transaction"
+	AuditEntityTransfer     string = "transfer"
+	AuditEntitySchedule     string = "scheduled_transfer"
+	AuditEntityExchangeRate string = "exchange_rate"
+	AuditEntityWebhook      string = "webhook_subscription"
+	AuditEntityDelivery     string = "webhook_delivery"
+)
+
+const (
+	AuditOutcomeSuccess string = "SUCCESS"
+	AuditOutcomeFailure string = "FAILURE"
+)
+
+// AuditActorAnonymous is the actor of requests without an identity, AuditActorSystem the one of background jobs
+const (
+	AuditActorAnonymous = "anonymous"
+	AuditActorSystem    = "system"
+)
+
+// AuditActorUnverifiedPrefix is recorded in front of an actor the caller only claimed
+const AuditActorUnverifiedPrefix = "unverified:"
+
+var ErrInvalidAuditFilter = errors.New("invalid audit filter")
+
+// AuditContext is who is doing what, it travels in the request context from the transport to the audit log.
+// Unverified is set when nothing authenticated the actor, e.g. the x-user-id metadata of a gRPC call
+type AuditContext struct {
+	Actor      string
+	Unverified bool
+	Method     string
+	RequestId  string
+}
+
+// RecordedActor is the actor written to the audit log, an unverified actor gets AuditActorUnverifiedPrefix
+func (ac AuditContext) RecordedActor() string {
+	if ac.Unverified && ac.Actor != AuditActorAnonymous {
+		return AuditActorUnverifiedPrefix + ac.Actor
+	}
+
+	return ac.Actor
+}
+
+type auditContextKey struct{}
+
+func WithAuditContext(ctx context.Context, ac AuditContext) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, ac)
+}
+
+// AuditContextFrom returns the audit context of ctx, or an anonymous one
+func AuditContextFrom(ctx context.Context) AuditContext {
+	if ac, ok := ctx.Value(auditContextKey{}).(AuditContext); ok {
+		return ac
+	}
+
+	return AuditContext{Actor: AuditActorAnonymous}
+}
+
+// AuditEntry is what a caller records, Before and After are marshalled to JSON and Err sets the outcome
+type AuditEntry struct {
+	Action     string
+	EntityType string
+	EntityIds  []string
+	Before     interface{}
+	After      interface{}
+	Err        error
+}
+
+// AuditRecord is one row of the audit log. Hash covers every other field and the hash of the previous record, so
+// changing or removing a record breaks the chain from there on
+type AuditRecord struct {
+	Sequence   int64           `json:"sequence"`
+	AuditUuid  uuid.UUID       `json:"audit_uuid"`
+	Actor      string          `json:"actor"`
+	Method     string          `json:"method"`
+	RequestId  string          `json:"request_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityIds  []string        `json:"entity_ids"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	Outcome    string          `json:"outcome"`
+	Error      string          `json:"error"`
+	CreatedAt  time.Time       `json:"created_at"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"-"`
+}
+
+// ComputeHash returns the hex SHA-256 of the record without its Hash field
+func (r AuditRecord) ComputeHash() (string, error) {
+	r.CreatedAt = r.CreatedAt.UTC()
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditFilter selects audit records, zero values mean "no filter". Records are returned in sequence order
+type AuditFilter struct {
+	Actor          string
+	Method         string
+	RequestId      string
+	Action         string
+	EntityId       string
+	StartTimestamp time.Time
+	EndTimestamp   time.Time
+	PageSize       int
+	PageToken      string
+}
+
+type AuditPage struct {
+	Records       []AuditRecord
+	NextPageToken string
+}
+
+func EncodeAuditPageToken(sequence int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(sequence, 10)))
+}
+
+func DecodeAuditPageToken(token string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("%w : %v", ErrInvalidPageToken, err)
+	}
+
+	sequence, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w : %v", ErrInvalidPageToken, err)
+	}
+
+	return sequence, nil
+}
+
+// AuditChainCheck is the result of verifying the hash chain, BrokenAt is the first record that doesn't match
+type AuditChainCheck struct {
+	Records  int64
+	Valid    bool
+	BrokenAt *int64
+	Reason   string
+}
@@ -2,7 +2,11 @@ package bank
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -20,6 +24,7 @@ type ExchangeRate struct {
 }
 
 type Transaction struct {
+	TransactionUuid uuid.UUID
 	Amount          float64
 	Timestamp       time.Time
 	TransactionType string
@@ -27,10 +32,12 @@ type Transaction struct {
 }
 
 type TransactionSummary struct {
-	SummaryOnDate time.Time
-	SumIn         float64
-	SumOut        float64
-	SumTotal      float64
+	AccountNumber    string
+	SummaryOnDate    time.Time
+	SumIn            float64
+	SumOut           float64
+	SumTotal         float64
+	TransactionCount int
 }
 
 type TransferTransaction struct {
@@ -38,6 +45,7 @@ type TransferTransaction struct {
 	ToAccountNumber   string
 	Currency          string
 	Amount            float64
+	RequestedBy       string // the actor asking for the transfer, empty when unknown
 }
 
 var ErrTransferSourceAccountNotFound = errors.New("source account not found")
@@ -45,3 +53,86 @@ var ErrTransferDestinationAccountNotFound = errors.New("destination account not
 var ErrTransferRecordFailed = errors.New("can't create transfer record")
 var ErrTransferTransactionPair = errors.New("can't create transfer transaction pair, " +
 	"possibly insufficient balance on source account")
+
+const (
+	ConversionPathDirect       string = "DIRECT"
+	ConversionPathInverse      string = "INVERSE"
+	ConversionPathTriangulated string = "TRIANGULATED"
+)
+
+type Conversion struct {
+	FromCurrency    string
+	ToCurrency      string
+	Amount          float64
+	ConvertedAmount float64
+	Rate            float64
+	Path            string
+	PivotCurrency   string
+	SourceRates     []ExchangeRate
+	Timestamp       time.Time
+}
+
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+const (
+	CandleIntervalMinute string = "1m"
+	CandleIntervalHour   string = "1h"
+	CandleIntervalDay    string = "1d"
+)
+
+type ExchangeRateCandle struct {
+	FromCurrency string
+	ToCurrency   string
+	Interval     string
+	BucketStart  time.Time
+	Open         float64
+	High         float64
+	Low          float64
+	Close        float64
+	Count        int
+}
+
+// ExchangeRatePage is one page of a rate time series. NextPageToken is empty when there are no more pages,
+// otherwise it is passed back to fetch the next page
+type ExchangeRatePage struct {
+	Rates         []ExchangeRate
+	NextPageToken string
+}
+
+// ExchangeRateCursor is the position of the last returned rate, pages are fetched with keyset pagination over
+// (valid_from_timestamp, exchange_rate_uuid)
+type ExchangeRateCursor struct {
+	ValidFrom        time.Time
+	ExchangeRateUuid uuid.UUID
+}
+
+func (c ExchangeRateCursor) Encode() string {
+	return encodeCursor(c.ValidFrom, c.ExchangeRateUuid)
+}
+
+func DecodeExchangeRateCursor(token string) (ExchangeRateCursor, error) {
+	var cursor ExchangeRateCursor
+	var err error
+
+	cursor.ValidFrom, cursor.ExchangeRateUuid, err = decodeCursor(token)
+
+	return cursor, err
+}
+
+type ExchangeRateCandlePage struct {
+	Candles           []ExchangeRateCandle
+	NextPageTimestamp time.Time
+}
+
+var ErrInvalidCandleInterval = errors.New("invalid candle interval, use 1m, 1h or 1d")
+var ErrInvalidTimeRange = errors.New("invalid time range, start must be before end")
+var ErrInvalidAmount = errors.New("amount must be a positive finite number")
+
+// CheckAmount returns ErrInvalidAmount unless the amount of a transaction or transfer is positive and finite
+func CheckAmount(amount float64) error {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount <= 0 {
+		return fmt.Errorf("%w : %v", ErrInvalidAmount, amount)
+	}
+
+	return nil
+}
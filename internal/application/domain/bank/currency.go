@@ -0,0 +1,151 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Currency is an ISO 4217 currency. MinorUnits is the number of digits after the decimal separator
+// (e.g. 2 for USD cents, 0 for JPY)
+type Currency struct {
+	Code        string
+	NumericCode string
+	Name        string
+	MinorUnits  int
+	Enabled     bool
+}
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+var ErrCurrencyDisabled = errors.New("currency is disabled")
+var ErrInvalidAmountPrecision = errors.New("amount has more decimals than the currency minor units")
+
+// defaultMinorUnits is used to round amounts of a currency missing from the registry
+const defaultMinorUnits = 2
+
+type CurrencyRegistry struct {
+	mu         sync.RWMutex
+	currencies map[string]Currency
+}
+
+func NewCurrencyRegistry(currencies ...Currency) *CurrencyRegistry {
+	r := &CurrencyRegistry{
+		currencies: make(map[string]Currency, len(currencies)),
+	}
+
+	for _, c := range currencies {
+		r.currencies[c.Code] = c
+	}
+
+	return r
+}
+
+// DefaultCurrencyRegistry returns a registry with the commonly used ISO 4217 currencies, all enabled. The amount
+// columns are NUMERIC(15,2), currencies with 3 minor units (BHD, KWD, ...) are left out since their amounts can't be
+// stored exactly
+func DefaultCurrencyRegistry() *CurrencyRegistry {
+	return NewCurrencyRegistry(
+		Currency{Code: "AUD", NumericCode: "036", Name: "Australian Dollar", MinorUnits: 2, Enabled: true},
+		Currency{Code: "BRL", NumericCode: "986", Name: "Brazilian Real", MinorUnits: 2, Enabled: true},
+		Currency{Code: "CAD", NumericCode: "124", Name: "Canadian Dollar", MinorUnits: 2, Enabled: true},
+		Currency{Code: "CHF", NumericCode: "756", Name: "Swiss Franc", MinorUnits: 2, Enabled: true},
+		Currency{Code: "CNY", NumericCode: "156", Name: "Yuan Renminbi", MinorUnits: 2, Enabled: true},
+		Currency{Code: "DKK", NumericCode: "208", Name: "Danish Krone", MinorUnits: 2, Enabled: true},
+		Currency{Code: "EUR", NumericCode: "978", Name: "Euro", MinorUnits: 2, Enabled: true},
+		Currency{Code: "GBP", NumericCode: "826", Name: "Pound Sterling", MinorUnits: 2, Enabled: true},
+		Currency{Code: "GHS", NumericCode: "936", Name: "Ghana Cedi", MinorUnits: 2, Enabled: true},
+		Currency{Code: "HKD", NumericCode: "344", Name: "Hong Kong Dollar", MinorUnits: 2, Enabled: true},
+		Currency{Code: "IDR", NumericCode: "360", Name: "Rupiah", MinorUnits: 2, Enabled: true},
+		Currency{Code: "INR", NumericCode: "356", Name: "Indian Rupee", MinorUnits: 2, Enabled: true},
+		Currency{Code: "JPY", NumericCode: "392", Name: "Yen", MinorUnits: 0, Enabled: true},
+		Currency{Code: "KES", NumericCode: "404", Name: "Kenyan Shilling", MinorUnits: 2, Enabled: true},
+		Currency{Code: "KRW", NumericCode: "410", Name: "Won", MinorUnits: 0, Enabled: true},
+		Currency{Code: "MXN", NumericCode: "484", Name: "Mexican Peso", MinorUnits: 2, Enabled: true},
+		Currency{Code: "MYR", NumericCode: "458", Name: "Malaysian Ringgit", MinorUnits: 2, Enabled: true},
+		Currency{Code: "NGN", NumericCode: "566", Name: "Naira", MinorUnits: 2, Enabled: true},
+		Currency{Code: "NOK", NumericCode: "578", Name: "Norwegian Krone", MinorUnits: 2, Enabled: true},
+		Currency{Code: "NZD", NumericCode: "554", Name: "New Zealand Dollar", MinorUnits: 2, Enabled: true},
+		Currency{Code: "PHP", NumericCode: "608", Name: "Philippine Peso", MinorUnits: 2, Enabled: true},
+		Currency{Code: "SAR", NumericCode: "682", Name: "Saudi Riyal", MinorUnits: 2, Enabled: true},
+		Currency{Code: "SEK", NumericCode: "752", Name: "Swedish Krona", MinorUnits: 2, Enabled: true},
+		Currency{Code: "SGD", NumericCode: "702", Name: "Singapore Dollar", MinorUnits: 2, Enabled: true},
+		Currency{Code: "THB", NumericCode: "764", Name: "Baht", MinorUnits: 2, Enabled: true},
+		Currency{Code: "USD", NumericCode: "840", Name: "US Dollar", MinorUnits: 2, Enabled: true},
+		Currency{Code: "VND", NumericCode: "704", Name: "Dong", MinorUnits: 0, Enabled: true},
+		Currency{Code: "ZAR", NumericCode: "710", Name: "Rand", MinorUnits: 2, Enabled: true},
+	)
+}
+
+func (r *CurrencyRegistry) Lookup(code string) (Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.currencies[code]
+	return c, ok
+}
+
+// Validate returns an error wrapping ErrUnknownCurrency or ErrCurrencyDisabled if the code can't be used
+func (r *CurrencyRegistry) Validate(code string) error {
+	c, ok := r.Lookup(code)
+	if !ok {
+		return fmt.Errorf("%w : %q", ErrUnknownCurrency, code)
+	}
+
+	if !c.Enabled {
+		return fmt.Errorf("%w : %v", ErrCurrencyDisabled, c.Code)
+	}
+
+	return nil
+}
+
+func (r *CurrencyRegistry) Enable(code string) error {
+	return r.setEnabled(code, true)
+}
+
+func (r *CurrencyRegistry) Disable(code string) error {
+	return r.setEnabled(code, false)
+}
+
+func (r *CurrencyRegistry) setEnabled(code string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.currencies[code]
+	if !ok {
+		return fmt.Errorf("%w : %q", ErrUnknownCurrency, code)
+	}
+
+	c.Enabled = enabled
+	r.currencies[c.Code] = c
+
+	return nil
+}
+
+// ValidateAmount returns an error wrapping ErrInvalidAmountPrecision if the amount has more decimals than the minor
+// units of the currency (e.g. 1.5 JPY or 1.005 USD)
+func (r *CurrencyRegistry) ValidateAmount(code string, amount float64) error {
+	c, ok := r.Lookup(code)
+	if !ok {
+		return fmt.Errorf("%w : %q", ErrUnknownCurrency, code)
+	}
+
+	// the amounts are floats, a tiny difference left by the scaling is not a decimal of its own
+	scaled := amount * math.Pow10(c.MinorUnits)
+	if math.Abs(scaled-math.Round(scaled)) > 1e-9*math.Max(1, math.Abs(scaled)) {
+		return fmt.Errorf("%w : %v %v allows %v decimals", ErrInvalidAmountPrecision, amount, c.Code, c.MinorUnits)
+	}
+
+	return nil
+}
+
+// Round rounds the amount to the minor units of the currency
+func (r *CurrencyRegistry) Round(code string, amount float64) float64 {
+	minorUnits := defaultMinorUnits
+	if c, ok := r.Lookup(code); ok {
+		minorUnits = c.MinorUnits
+	}
+
+	p := math.Pow10(minorUnits)
+	return math.Round(amount*p) / p
+}
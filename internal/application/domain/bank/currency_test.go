@@ -0,0 +1,63 @@
+package bank
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCurrencyRegistryValidateAmount(t *testing.T) {
+	registry := DefaultCurrencyRegistry()
+
+	tests := []struct {
+		name     string
+		currency string
+		amount   float64
+		err      error
+	}{
+		{name: "cents", currency: "USD", amount: 10.25},
+		{name: "whole amount", currency: "USD", amount: 10},
+		{name: "float noise", currency: "USD", amount: 0.1 + 0.2},
+		{name: "large amount", currency: "USD", amount: 9999999999999.99},
+		{name: "negative cents", currency: "EUR", amount: -3.5},
+		{name: "fraction of a cent", currency: "USD", amount: 1.005, err: ErrInvalidAmountPrecision},
+		{name: "whole yen", currency: "JPY", amount: 1500},
+		{name: "fraction of a yen", currency: "JPY", amount: 1.5, err: ErrInvalidAmountPrecision},
+		{name: "fraction of a won", currency: "KRW", amount: 0.01, err: ErrInvalidAmountPrecision},
+		{name: "3 decimal currency left out", currency: "KWD", amount: 1.125, err: ErrUnknownCurrency},
+		{name: "unknown currency", currency: "XYZ", amount: 1, err: ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.ValidateAmount(tt.currency, tt.amount); !errors.Is(err, tt.err) {
+				t.Errorf("ValidateAmount(%v, %v) = %v, want %v", tt.currency, tt.amount, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCurrencyRegistryRound(t *testing.T) {
+	registry := DefaultCurrencyRegistry()
+
+	tests := []struct {
+		name     string
+		currency string
+		amount   float64
+		want     float64
+	}{
+		{name: "cents", currency: "USD", amount: 1.234, want: 1.23},
+		{name: "half cent rounds away from zero", currency: "USD", amount: 0.125, want: 0.13},
+		{name: "negative", currency: "USD", amount: -1.236, want: -1.24},
+		{name: "yen", currency: "JPY", amount: 149.5, want: 150},
+		{name: "dong", currency: "VND", amount: 1234.4, want: 1234},
+		{name: "unknown currency uses 2 decimals", currency: "XYZ", amount: 1.234, want: 1.23},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := registry.Round(tt.currency, tt.amount); got != tt.want {
+				t.Errorf("Round(%v, %v) = %v, want %v", tt.currency, tt.amount, got, tt.want)
+			}
+		})
+	}
+}
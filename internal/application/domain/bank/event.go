@@ -0,0 +1,110 @@
+package bank
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// events are versioned, a consumer must check EventVersion before decoding the payload
+const EventVersion = 1
+
+const (
+	EventTransactionCreated      string = "transaction.created"
+	EventTransferSettled         string = "transfer.settled"
+	EventTransferFailed          string = "transfer.failed"
+	EventTransferReversed        string = "transfer.reversed"
+	EventExchangeRateCreated     string = "exchange_rate.created"
+	EventAccountCreated          string = "account.created"
+	EventAccountRenamed          string = "account.renamed"
+	EventAccountFrozen           string = "account.frozen"
+	EventAccountUnfrozen         string = "account.unfrozen"
+	EventAccountClosed           string = "account.closed"
+	EventAccountOverdraftChanged string = "account.overdraft_limit_changed"
+	EventAccountBalanceChanged   string = "account.balance_changed"
+)
+
+// events of the same aggregate are published in the order they were written
+const (
+	AggregateAccount      string = "account"
+	AggregateExchangeRate string = "exchange_rate"
+)
+
+type Event struct {
+	EventUuid     uuid.UUID       `json:"event_uuid"`
+	EventType     string          `json:"event_type"`
+	EventVersion  int             `json:"event_version"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateId   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func NewEvent(eventType string, aggregateType string, aggregateId string, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		EventUuid:     uuid.New(),
+		EventType:     eventType,
+		EventVersion:  EventVersion,
+		AggregateType: aggregateType,
+		AggregateId:   aggregateId,
+		Payload:       data,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+type TransactionEventPayload struct {
+	TransactionUuid uuid.UUID  `json:"transaction_uuid"`
+	AccountNumber   string     `json:"account_number"`
+	Currency        string     `json:"currency"`
+	TransactionType string     `json:"transaction_type"`
+	Amount          float64    `json:"amount"`
+	Notes           string     `json:"notes"`
+	ReversalOf      *uuid.UUID `json:"reversal_of,omitempty"`
+	Timestamp       time.Time  `json:"timestamp"`
+}
+
+type TransferEventPayload struct {
+	TransferUuid      uuid.UUID `json:"transfer_uuid"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountNumber   string    `json:"to_account_number"`
+	Currency          string    `json:"currency"`
+	Amount            float64   `json:"amount"`
+	DebitAmount       float64   `json:"debit_amount,omitempty"`
+	CreditAmount      float64   `json:"credit_amount,omitempty"`
+	Fee               float64   `json:"fee,omitempty"`
+	Status            string    `json:"status"`
+	Reason            string    `json:"reason,omitempty"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+type ExchangeRateEventPayload struct {
+	FromCurrency       string    `json:"from_currency"`
+	ToCurrency         string    `json:"to_currency"`
+	Rate               float64   `json:"rate"`
+	ValidFromTimestamp time.Time `json:"valid_from_timestamp"`
+	ValidToTimestamp   time.Time `json:"valid_to_timestamp"`
+}
+
+type AccountEventPayload struct {
+	AccountUuid    uuid.UUID `json:"account_uuid"`
+	AccountNumber  string    `json:"account_number"`
+	AccountName    string    `json:"account_name"`
+	Currency       string    `json:"currency"`
+	Status         string    `json:"status"`
+	OverdraftLimit float64   `json:"overdraft_limit"`
+}
+
+// BalanceEventPayload is the balance of a customer account right after a journal entry was applied to it
+type BalanceEventPayload struct {
+	AccountNumber string    `json:"account_number"`
+	Currency      string    `json:"currency"`
+	Balance       float64   `json:"balance"`
+	JournalUuid   uuid.UUID `json:"journal_uuid"`
+	Timestamp     time.Time `json:"timestamp"`
+}
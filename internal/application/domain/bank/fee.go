@@ -0,0 +1,108 @@
+package bank
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FeeTypeFlat          string = "FLAT"
+	FeeTypePercentage    string = "PERCENTAGE"
+	FeeTypeTiered        string = "TIERED"
+	FeeTypeCrossCurrency string = "CROSS_CURRENCY"
+)
+
+// FeeRule is one line of the fee schedule, the fee is FlatAmount + Percentage * amount, kept between MinFee and
+// MaxFee when they are set
+type FeeRule struct {
+	FeeType    string
+	MinAmount  float64
+	FlatAmount float64
+	Percentage float64
+	MinFee     *float64
+	MaxFee     *float64
+}
+
+func (r FeeRule) fee(amount float64) float64 {
+	fee := r.FlatAmount + r.Percentage*amount
+
+	if r.MinFee != nil {
+		fee = math.Max(fee, *r.MinFee)
+	}
+
+	if r.MaxFee != nil {
+		fee = math.Min(fee, *r.MaxFee)
+	}
+
+	return fee
+}
+
+type FeeComponent struct {
+	FeeType string
+	Amount  float64
+}
+
+type FeeSchedule []FeeRule
+
+// Evaluate returns the total fee and its parts for a transfer of amount. Flat and percentage rules always apply,
+// only the highest tier reached applies and the cross currency surcharge only applies when the currencies differ.
+// Each part is rounded with round, to the minor units of the debited currency
+func (s FeeSchedule) Evaluate(amount float64, crossCurrency bool, round func(float64) float64) (float64,
+	[]FeeComponent) {
+	var components []FeeComponent
+	var tier *FeeRule
+
+	for i, r := range s {
+		switch r.FeeType {
+		case FeeTypeFlat, FeeTypePercentage:
+			components = append(components, FeeComponent{FeeType: r.FeeType, Amount: round(r.fee(amount))})
+		case FeeTypeCrossCurrency:
+			if crossCurrency {
+				components = append(components, FeeComponent{FeeType: r.FeeType, Amount: round(r.fee(amount))})
+			}
+		case FeeTypeTiered:
+			if amount >= r.MinAmount && (tier == nil || r.MinAmount > tier.MinAmount) {
+				tier = &s[i]
+			}
+		}
+	}
+
+	if tier != nil {
+		components = append(components, FeeComponent{FeeType: FeeTypeTiered, Amount: round(tier.fee(amount))})
+	}
+
+	var total float64
+	var charged []FeeComponent
+
+	for _, c := range components {
+		if c.Amount > 0 {
+			total += c.Amount
+			charged = append(charged, c)
+		}
+	}
+
+	return round(total), charged
+}
+
+// TransferQuote is what a transfer would move : the source account is debited DebitAmount plus Fee, both in its
+// currency, and the destination account is credited CreditAmount in its currency
+type TransferQuote struct {
+	FromAccountNumber string
+	ToAccountNumber   string
+	Currency          string
+	Amount            float64
+	DebitCurrency     string
+	DebitAmount       float64
+	CreditCurrency    string
+	CreditAmount      float64
+	Fee               float64
+	FeeComponents     []FeeComponent
+}
+
+type TransferResult struct {
+	TransferUuid uuid.UUID
+	Success      bool
+	Held         bool
+	Quote        TransferQuote
+}
@@ -0,0 +1,149 @@
+package bank
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFeeScheduleEvaluate(t *testing.T) {
+	minFee, maxFee := 1.0, 25.0
+	minYen, maxYen := 150.0, 3800.0
+
+	// the generic rules of migration 019
+	generic := FeeSchedule{
+		{FeeType: FeeTypeTiered, MinAmount: 0, FlatAmount: 0.50},
+		{FeeType: FeeTypeTiered, MinAmount: 1000, Percentage: 0.001, MinFee: &minFee, MaxFee: &maxFee},
+		{FeeType: FeeTypeCrossCurrency, Percentage: 0.01},
+	}
+
+	// the JPY rules of migration 032
+	yen := FeeSchedule{
+		{FeeType: FeeTypeTiered, MinAmount: 0, FlatAmount: 80},
+		{FeeType: FeeTypeTiered, MinAmount: 150000, Percentage: 0.001, MinFee: &minYen, MaxFee: &maxYen},
+		{FeeType: FeeTypeCrossCurrency, Percentage: 0.01},
+	}
+
+	registry := DefaultCurrencyRegistry()
+
+	tests := []struct {
+		name          string
+		schedule      FeeSchedule
+		currency      string
+		amount        float64
+		crossCurrency bool
+		want          float64
+		components    []FeeComponent
+	}{
+		{
+			name:     "no rules",
+			currency: "USD",
+			amount:   100,
+			want:     0,
+		},
+		{
+			name:       "lowest tier",
+			schedule:   generic,
+			currency:   "USD",
+			amount:     100,
+			want:       0.50,
+			components: []FeeComponent{{FeeType: FeeTypeTiered, Amount: 0.50}},
+		},
+		{
+			name:       "highest tier reached, kept at the minimum fee",
+			schedule:   generic,
+			currency:   "USD",
+			amount:     1000,
+			want:       1,
+			components: []FeeComponent{{FeeType: FeeTypeTiered, Amount: 1}},
+		},
+		{
+			name:       "highest tier reached, percentage",
+			schedule:   generic,
+			currency:   "USD",
+			amount:     12345.67,
+			want:       12.35,
+			components: []FeeComponent{{FeeType: FeeTypeTiered, Amount: 12.35}},
+		},
+		{
+			name:       "highest tier reached, kept at the maximum fee",
+			schedule:   generic,
+			currency:   "USD",
+			amount:     100000,
+			want:       25,
+			components: []FeeComponent{{FeeType: FeeTypeTiered, Amount: 25}},
+		},
+		{
+			name:          "cross currency surcharge",
+			schedule:      generic,
+			currency:      "USD",
+			amount:        100,
+			crossCurrency: true,
+			want:          1.50,
+			components: []FeeComponent{
+				{FeeType: FeeTypeCrossCurrency, Amount: 1},
+				{FeeType: FeeTypeTiered, Amount: 0.50},
+			},
+		},
+		{
+			name: "flat and percentage rules add up",
+			schedule: FeeSchedule{
+				{FeeType: FeeTypeFlat, FlatAmount: 0.30},
+				{FeeType: FeeTypePercentage, Percentage: 0.029},
+			},
+			currency: "USD",
+			amount:   10,
+			want:     0.59,
+			components: []FeeComponent{
+				{FeeType: FeeTypeFlat, Amount: 0.30},
+				{FeeType: FeeTypePercentage, Amount: 0.29},
+			},
+		},
+		{
+			name:          "fee rounded to whole yen",
+			schedule:      yen,
+			currency:      "JPY",
+			amount:        1234,
+			crossCurrency: true,
+			want:          92,
+			components: []FeeComponent{
+				{FeeType: FeeTypeCrossCurrency, Amount: 12},
+				{FeeType: FeeTypeTiered, Amount: 80},
+			},
+		},
+		{
+			name:     "generic rules in yen round the flat fee to a whole yen",
+			schedule: generic,
+			currency: "JPY",
+			amount:   500,
+			want:     1,
+			components: []FeeComponent{
+				{FeeType: FeeTypeTiered, Amount: 1},
+			},
+		},
+		{
+			name:       "percentage of a large yen amount",
+			schedule:   yen,
+			currency:   "JPY",
+			amount:     1234567,
+			want:       1235,
+			components: []FeeComponent{{FeeType: FeeTypeTiered, Amount: 1235}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, components := tt.schedule.Evaluate(tt.amount, tt.crossCurrency, func(amount float64) float64 {
+				return registry.Round(tt.currency, amount)
+			})
+
+			if got != tt.want {
+				t.Errorf("Evaluate(%v %v) = %v, want %v", tt.amount, tt.currency, got, tt.want)
+			}
+
+			if !reflect.DeepEqual(components, tt.components) {
+				t.Errorf("Evaluate(%v %v) components = %+v, want %+v", tt.amount, tt.currency, components,
+					tt.components)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package bank
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// interest is charged on negative balances (debit) and paid on positive balances (credit)
+const (
+	InterestRateTypeDebit  string = "DEBIT"
+	InterestRateTypeCredit string = "CREDIT"
+)
+
+const SystemAccountInterest string = "INTEREST"
+
+const JournalTypeInterest string = "INTEREST"
+
+const interestDaysPerYear = 365
+
+var ErrInvalidOverdraftLimit = errors.New("overdraft limit can't be negative or below the current overdrawn balance")
+
+type InterestAccrual struct {
+	AccountUuid     uuid.UUID
+	AccountNumber   string
+	AccrualDate     time.Time
+	RateType        string
+	Balance         float64
+	AnnualRate      float64
+	Amount          float64
+	TransactionUuid uuid.UUID
+}
+
+type InterestAccrualReport struct {
+	AccrualDate     time.Time
+	AccountsChecked int
+	Accruals        []InterestAccrual
+	Failed          int
+}
+
+// DailyInterest is one day of interest on balance at annualRate, rounded to cents. The sign follows the balance
+func DailyInterest(balance float64, annualRate float64) float64 {
+	return math.Round(balance*annualRate/interestDaysPerYear*100) / 100
+}
@@ -0,0 +1,104 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AccountTypeCustomer string = "CUSTOMER"
+	AccountTypeSystem   string = "SYSTEM"
+)
+
+// system accounts exist once per currency, see SystemAccountNumber
+const (
+	SystemAccountCash string = "CASH"
+	SystemAccountFees string = "FEES"
+	SystemAccountFX   string = "FX"
+)
+
+const (
+	JournalTypeDeposit    string = "DEPOSIT"
+	JournalTypeWithdrawal string = "WITHDRAWAL"
+	JournalTypeTransfer   string = "TRANSFER"
+)
+
+// Posting is the signed change of one account balance, positive increases the balance
+type Posting struct {
+	AccountUuid uuid.UUID
+	Currency    string
+	Amount      float64
+}
+
+type JournalEntry struct {
+	JournalUuid uuid.UUID
+	EntryType   string
+	Description string
+	Postings    []Posting
+	CreatedAt   time.Time
+}
+
+// BalanceCheck compares the stored balance with the sum of the account postings
+type BalanceCheck struct {
+	AccountNumber string
+	StoredBalance float64
+	LedgerBalance float64
+	Difference    float64
+}
+
+var ErrUnbalancedJournalEntry = errors.New("journal entry postings don't balance")
+var ErrInsufficientFunds = errors.New("insufficient funds")
+var ErrSystemAccountNotFound = errors.New("system account not found")
+var ErrSameAccountTransfer = errors.New("cannot send money to yourself")
+
+func SystemAccountNumber(kind string, currency string) string {
+	return "SYS-" + kind + "-" + currency
+}
+
+// Validate checks that there are at least two postings, none of them zero, and that they sum to zero per currency
+// (compared in minor units to avoid float noise)
+func (j JournalEntry) Validate() error {
+	if len(j.Postings) < 2 {
+		return fmt.Errorf("%w : at least 2 postings required", ErrUnbalancedJournalEntry)
+	}
+
+	sums := make(map[string]int64)
+
+	for _, p := range j.Postings {
+		cents := toCents(p.Amount)
+		if cents == 0 {
+			return fmt.Errorf("%w : zero posting on account %v", ErrUnbalancedJournalEntry, p.AccountUuid)
+		}
+
+		sums[p.Currency] += cents
+	}
+
+	for currency, sum := range sums {
+		if sum != 0 {
+			return fmt.Errorf("%w : %v off by %.2f", ErrUnbalancedJournalEntry, currency, float64(sum)/100)
+		}
+	}
+
+	return nil
+}
+
+func (c BalanceCheck) Balanced() bool {
+	return toCents(c.Difference) == 0
+}
+
+func toCents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
+
+const JournalTypeReversal string = "REVERSAL"
+
+var ErrReversalReasonRequired = errors.New("a reason is required to reverse")
+var ErrAlreadyReversed = errors.New("already reversed")
+var ErrReverseReversal = errors.New("a reversal can't be reversed")
+var ErrReverseTransferLeg = errors.New("transaction is part of a transfer, reverse the transfer instead")
+var ErrTransactionNotFound = errors.New("transaction not found")
+var ErrTransferNotFound = errors.New("transfer not found")
@@ -0,0 +1,147 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	AccountTierStandard = "STANDARD"
+)
+
+const (
+	LimitMaxSingleOut        = "MAX_SINGLE_OUT"
+	LimitDailyOut            = "DAILY_OUT_LIMIT"
+	LimitMonthlyOut          = "MONTHLY_OUT_LIMIT"
+	LimitMaxTransfersPerHour = "MAX_TRANSFERS_PER_HOUR"
+	LimitMinimumBalance      = "MINIMUM_BALANCE"
+)
+
+var ErrLimitExceeded = errors.New("account limit exceeded")
+var ErrUnknownAccountTier = errors.New("unknown account tier")
+var ErrInvalidAccountLimits = errors.New("invalid account limits")
+
+// AccountLimits are the limits on outgoing money of an account, nil means no limit. Amounts are in the account
+// currency
+type AccountLimits struct {
+	AccountTier         string
+	MaxSingleOut        *float64
+	DailyOut            *float64
+	MonthlyOut          *float64
+	MaxTransfersPerHour *int
+	MinimumBalance      float64
+}
+
+func (l AccountLimits) Validate() error {
+	for _, amount := range []*float64{l.MaxSingleOut, l.DailyOut, l.MonthlyOut} {
+		if amount != nil && *amount < 0 {
+			return fmt.Errorf("%w : amount limits can't be negative", ErrInvalidAccountLimits)
+		}
+	}
+
+	if l.MaxTransfersPerHour != nil && *l.MaxTransfersPerHour < 0 {
+		return fmt.Errorf("%w : transfers per hour can't be negative", ErrInvalidAccountLimits)
+	}
+
+	return nil
+}
+
+// LimitUsage is what the account already used against its limits, outgoing totals exclude reversals
+type LimitUsage struct {
+	Balance           float64
+	OverdraftLimit    float64
+	DailyOut          float64
+	MonthlyOut        float64
+	TransfersLastHour int
+}
+
+// LimitWindows returns the start of the day, the start of the month and one hour ago, in UTC like the daily
+// summaries
+func LimitWindows(now time.Time) (day time.Time, month time.Time, hour time.Time) {
+	now = now.UTC()
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return day, month, now.Add(-time.Hour)
+}
+
+type LimitViolation struct {
+	Limit       string
+	Description string
+}
+
+// LimitExceededError lists every limit the operation would break, errors.Is(err, ErrLimitExceeded) is true
+type LimitExceededError struct {
+	AccountNumber string
+	Violations    []LimitViolation
+}
+
+func (e *LimitExceededError) Error() string {
+	descriptions := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		descriptions[i] = v.Description
+	}
+
+	return fmt.Sprintf("%v for account %v : %v", ErrLimitExceeded, e.AccountNumber, strings.Join(descriptions, ", "))
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Check returns a *LimitExceededError if taking amount out of the account breaks any limit. The transfer count only
+// applies when the outgoing money is a transfer
+func (l AccountLimits) Check(accountNumber string, amount float64, transfer bool, usage LimitUsage) error {
+	var violations []LimitViolation
+
+	if l.MaxSingleOut != nil && amount > *l.MaxSingleOut {
+		violations = append(violations, LimitViolation{
+			Limit:       LimitMaxSingleOut,
+			Description: fmt.Sprintf("amount %.2f exceeds the single payment limit %.2f", amount, *l.MaxSingleOut),
+		})
+	}
+
+	if l.DailyOut != nil && usage.DailyOut+amount > *l.DailyOut {
+		violations = append(violations, LimitViolation{
+			Limit: LimitDailyOut,
+			Description: fmt.Sprintf("daily outgoing total %.2f + %.2f exceeds %.2f", usage.DailyOut, amount,
+				*l.DailyOut),
+		})
+	}
+
+	if l.MonthlyOut != nil && usage.MonthlyOut+amount > *l.MonthlyOut {
+		violations = append(violations, LimitViolation{
+			Limit: LimitMonthlyOut,
+			Description: fmt.Sprintf("monthly outgoing total %.2f + %.2f exceeds %.2f", usage.MonthlyOut, amount,
+				*l.MonthlyOut),
+		})
+	}
+
+	if transfer && l.MaxTransfersPerHour != nil && usage.TransfersLastHour+1 > *l.MaxTransfersPerHour {
+		violations = append(violations, LimitViolation{
+			Limit: LimitMaxTransfersPerHour,
+			Description: fmt.Sprintf("%d transfers in the last hour, at most %d allowed", usage.TransfersLastHour,
+				*l.MaxTransfersPerHour),
+		})
+	}
+
+	// the minimum balance is lowered by the overdraft limit of the account
+	if minimumBalance := l.MinimumBalance - usage.OverdraftLimit; usage.Balance-amount < minimumBalance {
+		violations = append(violations, LimitViolation{
+			Limit: LimitMinimumBalance,
+			Description: fmt.Sprintf("balance %.2f - %.2f would be below the minimum balance %.2f", usage.Balance,
+				amount, minimumBalance),
+		})
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &LimitExceededError{
+		AccountNumber: accountNumber,
+		Violations:    violations,
+	}
+}
@@ -0,0 +1,144 @@
+package bank
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAccountLimitsCheck(t *testing.T) {
+	maxSingleOut, dailyOut, monthlyOut := 1000.0, 2000.0, 5000.0
+	transfersPerHour := 3
+
+	limits := AccountLimits{
+		AccountTier:         AccountTierStandard,
+		MaxSingleOut:        &maxSingleOut,
+		DailyOut:            &dailyOut,
+		MonthlyOut:          &monthlyOut,
+		MaxTransfersPerHour: &transfersPerHour,
+	}
+
+	tests := []struct {
+		name       string
+		limits     AccountLimits
+		amount     float64
+		transfer   bool
+		usage      LimitUsage
+		violations []string
+	}{
+		{
+			name:     "within every limit",
+			limits:   limits,
+			amount:   500,
+			transfer: true,
+			usage:    LimitUsage{Balance: 3000, DailyOut: 1000, MonthlyOut: 4000, TransfersLastHour: 2},
+		},
+		{
+			name:   "exactly at the limits",
+			limits: limits,
+			amount: 1000,
+			usage:  LimitUsage{Balance: 1000, DailyOut: 1000, MonthlyOut: 4000},
+		},
+		{
+			name:       "single payment too large",
+			limits:     limits,
+			amount:     1000.01,
+			usage:      LimitUsage{Balance: 3000},
+			violations: []string{LimitMaxSingleOut},
+		},
+		{
+			name:       "daily and monthly totals exceeded",
+			limits:     limits,
+			amount:     600,
+			usage:      LimitUsage{Balance: 3000, DailyOut: 1500, MonthlyOut: 4500},
+			violations: []string{LimitDailyOut, LimitMonthlyOut},
+		},
+		{
+			name:       "too many transfers in the last hour",
+			limits:     limits,
+			amount:     10,
+			transfer:   true,
+			usage:      LimitUsage{Balance: 3000, TransfersLastHour: 3},
+			violations: []string{LimitMaxTransfersPerHour},
+		},
+		{
+			name:   "transfer count ignored for a withdrawal",
+			limits: limits,
+			amount: 10,
+			usage:  LimitUsage{Balance: 3000, TransfersLastHour: 3},
+		},
+		{
+			name:       "balance would go negative",
+			limits:     limits,
+			amount:     100,
+			usage:      LimitUsage{Balance: 50},
+			violations: []string{LimitMinimumBalance},
+		},
+		{
+			name:   "overdraft lowers the minimum balance",
+			limits: limits,
+			amount: 100,
+			usage:  LimitUsage{Balance: 50, OverdraftLimit: 50},
+		},
+		{
+			name:       "overdraft limit exceeded",
+			limits:     limits,
+			amount:     100.01,
+			usage:      LimitUsage{Balance: 50, OverdraftLimit: 50},
+			violations: []string{LimitMinimumBalance},
+		},
+		{
+			name:       "minimum balance of the tier",
+			limits:     AccountLimits{MinimumBalance: 100},
+			amount:     950,
+			usage:      LimitUsage{Balance: 1000},
+			violations: []string{LimitMinimumBalance},
+		},
+		{
+			name:     "no limits",
+			limits:   AccountLimits{MinimumBalance: -1e12},
+			amount:   1e9,
+			transfer: true,
+			usage:    LimitUsage{DailyOut: 1e9, MonthlyOut: 1e9, TransfersLastHour: 1000},
+		},
+		{
+			name:       "every limit at once",
+			limits:     limits,
+			amount:     1500,
+			usage:      LimitUsage{Balance: 100, DailyOut: 1000, MonthlyOut: 4000, TransfersLastHour: 3},
+			violations: []string{LimitMaxSingleOut, LimitDailyOut, LimitMonthlyOut, LimitMinimumBalance},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Check("7835697017", tt.amount, tt.transfer, tt.usage)
+
+			if len(tt.violations) == 0 {
+				if err != nil {
+					t.Errorf("Check = %v, want nil", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("Check = %v, want %v", err, ErrLimitExceeded)
+			}
+
+			var limitErr *LimitExceededError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("Check = %T, want *LimitExceededError", err)
+			}
+
+			var violations []string
+			for _, v := range limitErr.Violations {
+				violations = append(violations, v.Limit)
+			}
+
+			if !reflect.DeepEqual(violations, tt.violations) {
+				t.Errorf("Check violations = %v, want %v", violations, tt.violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package bank
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// a repair posts an ADJUSTMENT journal entry against the ADJUSTMENT system account of the currency
+const (
+	SystemAccountAdjustment string = "ADJUSTMENT"
+	JournalTypeAdjustment   string = "ADJUSTMENT"
+)
+
+// BalanceMismatch is an account whose stored balance differs from the balance recomputed from its history.
+// AdjustmentJournalUuid is the journal entry that repaired it
+type BalanceMismatch struct {
+	AccountUuid           uuid.UUID
+	AccountNumber         string
+	Currency              string
+	StoredBalance         float64
+	ExpectedBalance       float64
+	LedgerBalance         float64
+	Difference            float64
+	Repaired              bool
+	AdjustmentJournalUuid *uuid.UUID
+}
+
+type ReconciliationReport struct {
+	RunUuid         uuid.UUID
+	StartedAt       time.Time
+	FinishedAt      time.Time
+	AccountsChecked int
+	Repair          bool
+	Mismatches      []BalanceMismatch
+}
@@ -0,0 +1,202 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	RiskDecisionAllow  string = "ALLOW"
+	RiskDecisionReview string = "REVIEW"
+	RiskDecisionDeny   string = "DENY"
+)
+
+const (
+	RiskOperationTransfer   string = "TRANSFER"
+	RiskOperationWithdrawal string = "WITHDRAWAL"
+	RiskOperationDeposit    string = "DEPOSIT"
+)
+
+const (
+	RiskRuleNewPayee       string = "NEW_PAYEE"
+	RiskRuleUnusualAmount  string = "UNUSUAL_AMOUNT"
+	RiskRuleRapidSuccesion string = "RAPID_SUCCESSION"
+	RiskRuleRoundAmount    string = "ROUND_AMOUNT"
+)
+
+const (
+	RiskReviewApproved string = "APPROVED"
+	RiskReviewRejected string = "REJECTED"
+)
+
+var ErrRiskDenied = errors.New("operation denied by risk evaluation")
+var ErrTransferNotHeld = errors.New("transfer is not held for review")
+var ErrTransferRejected = errors.New("transfer rejected on review")
+var ErrReviewerRequired = errors.New("a reviewer is required")
+var ErrReviewerNotAllowed = errors.New("reviewer is not allowed to review transfers")
+var ErrReviewerIsRequester = errors.New("reviewer requested the transfer")
+
+// RiskRequest is the operation to evaluate, Amount is in the currency of the account
+type RiskRequest struct {
+	Operation                 string
+	AccountNumber             string
+	CounterpartyAccountNumber string
+	Currency                  string
+	Amount                    float64
+	Timestamp                 time.Time
+}
+
+// RiskHistory is what the account did before the operation. Outgoing statistics exclude reversals
+type RiskHistory struct {
+	TransfersToCounterparty int
+	OutCount                int
+	OutAverage              float64
+	OutStdDev               float64
+	RecentOutCount          int
+}
+
+type RiskSignal struct {
+	Rule        string
+	Score       int
+	Description string
+}
+
+type RiskAssessment struct {
+	Decision string
+	Score    int
+	Signals  []RiskSignal
+}
+
+// Rules returns the names of the rules that fired
+func (a RiskAssessment) Rules() []string {
+	rules := make([]string, len(a.Signals))
+	for i, s := range a.Signals {
+		rules[i] = s.Rule
+	}
+
+	return rules
+}
+
+// HeldTransfer is a transfer waiting for review with the assessment that held it
+type HeldTransfer struct {
+	Transfer   Transfer
+	Assessment RiskAssessment
+}
+
+// RiskDeniedError carries the assessment of a denied operation, errors.Is(err, ErrRiskDenied) is true
+type RiskDeniedError struct {
+	Assessment RiskAssessment
+}
+
+func (e *RiskDeniedError) Error() string {
+	return fmt.Sprintf("%v : score %d (%v)", ErrRiskDenied, e.Assessment.Score,
+		strings.Join(e.Assessment.Rules(), ", "))
+}
+
+func (e *RiskDeniedError) Unwrap() error {
+	return ErrRiskDenied
+}
+
+// RiskRules configures the built-in rules, the scores of the rules that fire are added up and compared to the
+// review and deny scores
+type RiskRules struct {
+	NewPayeeScore       int
+	UnusualAmountScore  int
+	RapidSuccesionScore int
+	RoundAmountScore    int
+
+	// an amount is unusual above average + UnusualStdDevs standard deviations, once the account has
+	// UnusualMinHistory outgoing transactions to compare with
+	UnusualStdDevs    float64
+	UnusualMinHistory int
+
+	// rapid succession is RapidCount outgoing transactions within RapidWindow, including this one
+	RapidCount  int
+	RapidWindow time.Duration
+
+	// a round amount is a multiple of RoundAmountUnit of at least RoundAmountUnit
+	RoundAmountUnit float64
+
+	ReviewScore int
+	DenyScore   int
+}
+
+func DefaultRiskRules() RiskRules {
+	return RiskRules{
+		NewPayeeScore:       20,
+		UnusualAmountScore:  40,
+		RapidSuccesionScore: 30,
+		RoundAmountScore:    15,
+		UnusualStdDevs:      3,
+		UnusualMinHistory:   5,
+		RapidCount:          4,
+		RapidWindow:         10 * time.Minute,
+		RoundAmountUnit:     1000,
+		ReviewScore:         50,
+		DenyScore:           80,
+	}
+}
+
+// Assess applies the rules to the request, deposits only go through the amount rules
+func (r RiskRules) Assess(req RiskRequest, history RiskHistory) RiskAssessment {
+	var signals []RiskSignal
+
+	outgoing := req.Operation != RiskOperationDeposit
+
+	if req.Operation == RiskOperationTransfer && history.TransfersToCounterparty == 0 {
+		signals = append(signals, RiskSignal{
+			Rule:        RiskRuleNewPayee,
+			Score:       r.NewPayeeScore,
+			Description: fmt.Sprintf("first transfer to %v", req.CounterpartyAccountNumber),
+		})
+	}
+
+	if outgoing && history.OutCount >= r.UnusualMinHistory &&
+		req.Amount > history.OutAverage+r.UnusualStdDevs*history.OutStdDev && req.Amount > 2*history.OutAverage {
+		signals = append(signals, RiskSignal{
+			Rule:  RiskRuleUnusualAmount,
+			Score: r.UnusualAmountScore,
+			Description: fmt.Sprintf("amount %.2f against an average of %.2f (std dev %.2f)", req.Amount,
+				history.OutAverage, history.OutStdDev),
+		})
+	}
+
+	if outgoing && history.RecentOutCount+1 >= r.RapidCount {
+		signals = append(signals, RiskSignal{
+			Rule:  RiskRuleRapidSuccesion,
+			Score: r.RapidSuccesionScore,
+			Description: fmt.Sprintf("%d outgoing operations within %v", history.RecentOutCount+1,
+				r.RapidWindow),
+		})
+	}
+
+	if r.RoundAmountUnit > 0 && req.Amount >= r.RoundAmountUnit &&
+		math.Mod(req.Amount, r.RoundAmountUnit) == 0 {
+		signals = append(signals, RiskSignal{
+			Rule:        RiskRuleRoundAmount,
+			Score:       r.RoundAmountScore,
+			Description: fmt.Sprintf("round amount %.2f", req.Amount),
+		})
+	}
+
+	assessment := RiskAssessment{
+		Decision: RiskDecisionAllow,
+		Signals:  signals,
+	}
+
+	for _, s := range signals {
+		assessment.Score += s.Score
+	}
+
+	switch {
+	case assessment.Score >= r.DenyScore:
+		assessment.Decision = RiskDecisionDeny
+	case assessment.Score >= r.ReviewScore:
+		assessment.Decision = RiskDecisionReview
+	}
+
+	return assessment
+}
@@ -0,0 +1,81 @@
+package bank
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RecurrenceNone    string = "NONE"
+	RecurrenceDaily   string = "DAILY"
+	RecurrenceWeekly  string = "WEEKLY"
+	RecurrenceMonthly string = "MONTHLY"
+)
+
+const (
+	ScheduleStatusActive    string = "ACTIVE"
+	ScheduleStatusCompleted string = "COMPLETED"
+	ScheduleStatusCancelled string = "CANCELLED"
+)
+
+const (
+	ExecutionOutcomeSuccess string = "SUCCESS"
+	ExecutionOutcomeSkipped string = "SKIPPED"
+	ExecutionOutcomeRetry   string = "RETRY"
+	ExecutionOutcomeFailed  string = "FAILED"
+)
+
+type ScheduledTransfer struct {
+	ScheduleUuid uuid.UUID
+	Transfer     TransferTransaction
+	Recurrence   string
+	StartAt      time.Time
+	EndAt        *time.Time
+	NextRunAt    time.Time
+	Status       string
+}
+
+type ScheduledTransferExecution struct {
+	ExecutionUuid uuid.UUID
+	ScheduleUuid  uuid.UUID
+	ScheduledFor  time.Time
+	ExecutedAt    time.Time
+	Attempt       int
+	Outcome       string
+	TransferUuid  *uuid.UUID
+	Reason        string
+}
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence, use NONE, DAILY, WEEKLY or MONTHLY")
+var ErrScheduledTransferNotFound = errors.New("scheduled transfer not found")
+var ErrScheduledTransferNotActive = errors.New("scheduled transfer is not active")
+
+func ValidRecurrence(recurrence string) bool {
+	switch recurrence {
+	case RecurrenceNone, RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+		return true
+	default:
+		return false
+	}
+}
+
+// Occurrence returns the n-th occurrence (0 is start) of a recurrence. Monthly occurrences keep the day of month of
+// start and fall back to the last day of shorter months, e.g. Jan 31 -> Feb 29 -> Mar 31
+func Occurrence(start time.Time, recurrence string, n int) time.Time {
+	switch recurrence {
+	case RecurrenceDaily:
+		return start.AddDate(0, 0, n)
+	case RecurrenceWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case RecurrenceMonthly:
+		firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(n), 1, start.Hour(), start.Minute(),
+			start.Second(), start.Nanosecond(), start.Location())
+		lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+		return firstOfMonth.AddDate(0, 0, min(start.Day(), lastDay)-1)
+	default:
+		return start
+	}
+}
@@ -0,0 +1,62 @@
+package bank
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccurrence(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 9, 30, 0, 0, time.UTC)
+	mid := time.Date(2024, time.March, 15, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		start      time.Time
+		recurrence string
+		n          int
+		want       time.Time
+	}{
+		{name: "first occurrence is the start", start: start, recurrence: RecurrenceMonthly, n: 0, want: start},
+		{name: "no recurrence", start: start, recurrence: RecurrenceNone, n: 5, want: start},
+		{
+			name: "daily across a month end", start: start, recurrence: RecurrenceDaily, n: 1,
+			want: time.Date(2024, time.February, 1, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "weekly", start: start, recurrence: RecurrenceWeekly, n: 2,
+			want: time.Date(2024, time.February, 14, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "monthly falls back to the last day of a leap February", start: start,
+			recurrence: RecurrenceMonthly, n: 1,
+			want: time.Date(2024, time.February, 29, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "monthly goes back to the day of month of the start", start: start,
+			recurrence: RecurrenceMonthly, n: 2,
+			want: time.Date(2024, time.March, 31, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "monthly falls back to the last day of a 30 day month", start: start,
+			recurrence: RecurrenceMonthly, n: 3,
+			want: time.Date(2024, time.April, 30, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "monthly falls back to the last day of a February", start: start,
+			recurrence: RecurrenceMonthly, n: 13,
+			want: time.Date(2025, time.February, 28, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "monthly across a year end", start: mid, recurrence: RecurrenceMonthly, n: 10,
+			want: time.Date(2025, time.January, 15, 9, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Occurrence(tt.start, tt.recurrence, tt.n); !got.Equal(tt.want) {
+				t.Errorf("Occurrence(%v, %v, %d) = %v, want %v", tt.start, tt.recurrence, tt.n, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package bank
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	ScreeningActionBlock string = "BLOCK"
+	ScreeningActionFlag  string = "FLAG"
+)
+
+const (
+	ScreeningMatchAccountNumber string = "ACCOUNT_NUMBER"
+	ScreeningMatchName          string = "NAME"
+)
+
+const (
+	ScreeningRoleAccountHolder string = "ACCOUNT_HOLDER"
+	ScreeningRoleSender        string = "SENDER"
+	ScreeningRoleRecipient     string = "RECIPIENT"
+)
+
+const (
+	ScreeningOperationAccountCreate string = "ACCOUNT_CREATE"
+	ScreeningOperationAccountRename string = "ACCOUNT_RENAME"
+	ScreeningOperationTransfer      string = "TRANSFER"
+)
+
+// DefaultNameMatchThreshold is the similarity from which a name matches a listed name, 1 is an exact match once
+// case, accents, punctuation and word order are ignored
+const DefaultNameMatchThreshold = 0.92
+
+var ErrScreeningBlocked = errors.New("operation blocked by sanctions screening")
+var ErrInvalidScreeningList = errors.New("invalid screening list")
+var ErrScreeningUnavailable = errors.New("sanctions screening list is not loaded")
+
+// ScreeningEntry is one line of the list, with a name, an account number or both. Each alias of a listed party is
+// a line of its own
+type ScreeningEntry struct {
+	Name          string
+	AccountNumber string
+	Action        string
+	Reason        string
+}
+
+// ScreeningParty is who is screened, Name is matched approximately and AccountNumber exactly
+type ScreeningParty struct {
+	Role          string
+	AccountNumber string
+	Name          string
+}
+
+type ScreeningHit struct {
+	Party     ScreeningParty
+	Entry     ScreeningEntry
+	MatchType string
+	Score     float64
+}
+
+// ScreeningBlockedError is returned when a hit blocks the operation. Its message only names the parties, what they
+// matched is not told to the caller
+type ScreeningBlockedError struct {
+	Hits []ScreeningHit
+}
+
+func (e *ScreeningBlockedError) Error() string {
+	roles := make([]string, 0, len(e.Hits))
+	seen := make(map[string]bool)
+
+	for _, h := range e.Hits {
+		if h.Entry.Action == ScreeningActionBlock && !seen[h.Party.Role] {
+			seen[h.Party.Role] = true
+			roles = append(roles, strings.ToLower(h.Party.Role))
+		}
+	}
+
+	return fmt.Sprintf("%v : %v", ErrScreeningBlocked, strings.Join(roles, ", "))
+}
+
+func (e *ScreeningBlockedError) Unwrap() error {
+	return ErrScreeningBlocked
+}
+
+// ScreeningList is a parsed list, it is read only so it can be swapped whole on reload
+type ScreeningList struct {
+	entries   []ScreeningEntry
+	names     []string
+	byAccount map[string][]int
+}
+
+// ParseScreeningList reads a CSV list with a header naming its columns : name, account_number, action (BLOCK when
+// empty) and reason. Lines starting with # are comments
+func ParseScreeningList(r io.Reader) (*ScreeningList, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w : no header", ErrInvalidScreeningList)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w : %v", ErrInvalidScreeningList, err)
+	}
+
+	columns := make(map[string]int)
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	if _, ok := columns["name"]; !ok {
+		if _, ok := columns["account_number"]; !ok {
+			return nil, fmt.Errorf("%w : needs a name or account_number column", ErrInvalidScreeningList)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+
+		return ""
+	}
+
+	list := &ScreeningList{
+		byAccount: make(map[string][]int),
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w : %v", ErrInvalidScreeningList, err)
+		}
+
+		entry := ScreeningEntry{
+			Name:          field(record, "name"),
+			AccountNumber: field(record, "account_number"),
+			Action:        strings.ToUpper(field(record, "action")),
+			Reason:        field(record, "reason"),
+		}
+
+		if entry.Name == "" && entry.AccountNumber == "" {
+			continue
+		}
+
+		switch entry.Action {
+		case "":
+			entry.Action = ScreeningActionBlock
+		case ScreeningActionBlock, ScreeningActionFlag:
+		default:
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("%w : line %d : unknown action %v", ErrInvalidScreeningList, line,
+				entry.Action)
+		}
+
+		if entry.AccountNumber != "" {
+			list.byAccount[entry.AccountNumber] = append(list.byAccount[entry.AccountNumber], len(list.entries))
+		}
+
+		list.entries = append(list.entries, entry)
+		list.names = append(list.names, normalizeScreeningName(entry.Name))
+	}
+
+	return list, nil
+}
+
+func (l *ScreeningList) Len() int {
+	if l == nil {
+		return 0
+	}
+
+	return len(l.entries)
+}
+
+// Screen returns the hits of the parties, an account number match scores 1. A nil list has no hits
+func (l *ScreeningList) Screen(threshold float64, parties ...ScreeningParty) []ScreeningHit {
+	if l == nil {
+		return nil
+	}
+
+	var hits []ScreeningHit
+
+	for _, p := range parties {
+		matched := make(map[int]bool)
+
+		for _, i := range l.byAccount[p.AccountNumber] {
+			matched[i] = true
+			hits = append(hits, ScreeningHit{
+				Party:     p,
+				Entry:     l.entries[i],
+				MatchType: ScreeningMatchAccountNumber,
+				Score:     1,
+			})
+		}
+
+		name := normalizeScreeningName(p.Name)
+		if name == "" {
+			continue
+		}
+
+		for i, listed := range l.names {
+			if listed == "" || matched[i] {
+				continue
+			}
+
+			if score := nameSimilarity(name, listed); score >= threshold {
+				hits = append(hits, ScreeningHit{
+					Party:     p,
+					Entry:     l.entries[i],
+					MatchType: ScreeningMatchName,
+					Score:     score,
+				})
+			}
+		}
+	}
+
+	return hits
+}
+
+// normalizeScreeningName lowercases, drops accents and punctuation and collapses spaces
+func normalizeScreeningName(name string) string {
+	var b strings.Builder
+
+	space := false
+
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteRune(' ')
+			}
+
+			space = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			space = true
+		}
+	}
+
+	return b.String()
+}
+
+// nameSimilarity is the Jaro-Winkler similarity of the names as given and with their words sorted, so "Doe John"
+// matches "John Doe"
+func nameSimilarity(a string, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	score := jaroWinkler(a, b)
+
+	if sorted := jaroWinkler(sortWords(a), sortWords(b)); sorted > score {
+		score = sorted
+	}
+
+	return score
+}
+
+func sortWords(s string) string {
+	words := strings.Fields(s)
+	sort.Strings(words)
+
+	return strings.Join(words, " ")
+}
+
+func jaroWinkler(a string, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := max(len(s1), len(s2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+
+	for i := range s1 {
+		for j := max(0, i-window); j < min(len(s2), i+window+1); j++ {
+			if !matched2[j] && s1[i] == s2[j] {
+				matched1[i], matched2[j] = true, true
+				matches++
+
+				break
+			}
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+
+	for i, j := 0, 0; i < len(s1); i++ {
+		if !matched1[i] {
+			continue
+		}
+
+		for !matched2[j] {
+			j++
+		}
+
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,131 @@
+package bank
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want float64
+	}{
+		{a: "martha", b: "martha", want: 1},
+		{a: "martha", b: "marhta", want: 0.961},
+		{a: "dwayne", b: "duane", want: 0.840},
+		{a: "dixon", b: "dicksonx", want: 0.813},
+		{a: "jellyfish", b: "smellyfish", want: 0.896},
+		{a: "abc", b: "xyz", want: 0},
+		{a: "", b: "martha", want: 0},
+		{a: "martha", b: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := jaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("jaroWinkler(%q, %q) = %.3f, want %.3f", tt.a, tt.b, got, tt.want)
+			}
+
+			if got, reversed := jaroWinkler(tt.a, tt.b), jaroWinkler(tt.b, tt.a); math.Abs(got-reversed) > 1e-9 {
+				t.Errorf("jaroWinkler(%q, %q) = %v but jaroWinkler(%q, %q) = %v", tt.a, tt.b, got, tt.b, tt.a,
+					reversed)
+			}
+		})
+	}
+}
+
+func TestScreeningListScreen(t *testing.T) {
+	list, err := ParseScreeningList(strings.NewReader(`name,account_number,action,reason
+# listed parties
+Ivan Petrov,,BLOCK,sanctioned
+José Álvarez-Núñez,,FLAG,politically exposed
+,7835697033,,mule account
+Acme Trading Ltd,7835697041,BLOCK,shell company
+`))
+	if err != nil {
+		t.Fatalf("ParseScreeningList error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		party   ScreeningParty
+		matches []string
+		actions []string
+	}{
+		{
+			name:    "exact name",
+			party:   ScreeningParty{Name: "Ivan Petrov"},
+			matches: []string{ScreeningMatchName},
+			actions: []string{ScreeningActionBlock},
+		},
+		{
+			name:    "case, word order and punctuation ignored",
+			party:   ScreeningParty{Name: "PETROV, ivan"},
+			matches: []string{ScreeningMatchName},
+			actions: []string{ScreeningActionBlock},
+		},
+		{
+			name:    "misspelt name",
+			party:   ScreeningParty{Name: "Ivan Petrof"},
+			matches: []string{ScreeningMatchName},
+			actions: []string{ScreeningActionBlock},
+		},
+		{
+			name:    "accents ignored",
+			party:   ScreeningParty{Name: "Jose Alvarez Nunez"},
+			matches: []string{ScreeningMatchName},
+			actions: []string{ScreeningActionFlag},
+		},
+		{
+			name:  "different name",
+			party: ScreeningParty{Name: "Jane Doe"},
+		},
+		{
+			name:    "account number without name",
+			party:   ScreeningParty{AccountNumber: "7835697033", Name: "Jane Doe"},
+			matches: []string{ScreeningMatchAccountNumber},
+			actions: []string{ScreeningActionBlock},
+		},
+		{
+			name:    "account number and name of the same entry hit once",
+			party:   ScreeningParty{AccountNumber: "7835697041", Name: "Acme Trading Ltd"},
+			matches: []string{ScreeningMatchAccountNumber},
+			actions: []string{ScreeningActionBlock},
+		},
+		{
+			name:  "no name and an unlisted account",
+			party: ScreeningParty{AccountNumber: "7835697017"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := list.Screen(DefaultNameMatchThreshold, tt.party)
+
+			if len(hits) != len(tt.matches) {
+				t.Fatalf("Screen(%+v) = %+v, want %d hits", tt.party, hits, len(tt.matches))
+			}
+
+			for i, h := range hits {
+				if h.MatchType != tt.matches[i] || h.Entry.Action != tt.actions[i] {
+					t.Errorf("hit %d = %v %v, want %v %v", i, h.MatchType, h.Entry.Action, tt.matches[i],
+						tt.actions[i])
+				}
+
+				if h.Score < DefaultNameMatchThreshold || h.Score > 1 {
+					t.Errorf("hit %d score = %v, want between %v and 1", i, h.Score, DefaultNameMatchThreshold)
+				}
+			}
+		})
+	}
+}
+
+func TestScreeningListNil(t *testing.T) {
+	var list *ScreeningList
+
+	if hits := list.Screen(DefaultNameMatchThreshold, ScreeningParty{Name: "Ivan Petrov"}); hits != nil {
+		t.Errorf("Screen on a nil list = %+v, want no hits", hits)
+	}
+}
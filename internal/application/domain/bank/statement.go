@@ -0,0 +1,39 @@
+package bank
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	StatementFormatCSV string = "csv"
+	StatementFormatPDF string = "pdf"
+)
+
+type StatementLine struct {
+	Transaction
+	RunningBalance float64
+}
+
+// Statement covers [PeriodStart, PeriodEnd). ClosingBalance = OpeningBalance + TotalIn - TotalOut
+type Statement struct {
+	AccountNumber  string
+	AccountName    string
+	Currency       string
+	PeriodStart    time.Time
+	PeriodEnd      time.Time
+	OpeningBalance float64
+	TotalIn        float64
+	TotalOut       float64
+	ClosingBalance float64
+	Lines          []StatementLine
+	GeneratedAt    time.Time
+}
+
+var ErrUnknownStatementFormat = errors.New("unknown statement format, use csv or pdf")
+
+// MonthPeriod returns the first instant of the month and the first instant of the next month (UTC)
+func MonthPeriod(year int, month time.Month) (time.Time, time.Time) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
@@ -0,0 +1,84 @@
+package bank
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransactionFilter selects the transactions of one account. Zero values mean "no filter" for every field
+type TransactionFilter struct {
+	AccountNumber   string
+	StartTimestamp  time.Time
+	EndTimestamp    time.Time
+	TransactionType string
+	MinAmount       *float64
+	MaxAmount       *float64
+	NotesContains   string
+	SortDescending  bool
+	PageSize        int
+	PageToken       string
+}
+
+type TransactionPage struct {
+	Transactions  []Transaction
+	NextPageToken string
+}
+
+// TransactionCursor is the position of the last returned row, pages are fetched with keyset pagination over
+// (transaction_timestamp, transaction_uuid). Transfers page the same way with their timestamp and uuid
+type TransactionCursor struct {
+	Timestamp       time.Time
+	TransactionUuid uuid.UUID
+}
+
+var ErrInvalidPageToken = errors.New("invalid page token")
+var ErrInvalidAmountRange = errors.New("invalid amount range, min amount must not exceed max amount")
+var ErrInvalidTransactionType = errors.New("invalid transaction type, use IN or OUT")
+
+func (c TransactionCursor) Encode() string {
+	return encodeCursor(c.Timestamp, c.TransactionUuid)
+}
+
+func DecodeTransactionCursor(token string) (TransactionCursor, error) {
+	var cursor TransactionCursor
+	var err error
+
+	cursor.Timestamp, cursor.TransactionUuid, err = decodeCursor(token)
+
+	return cursor, err
+}
+
+// encodeCursor is the page token of a (timestamp, uuid) keyset position
+func encodeCursor(timestamp time.Time, id uuid.UUID) string {
+	raw := timestamp.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(token string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, uuid.Nil, fmt.Errorf("%w : %v", ErrInvalidPageToken, err)
+	}
+
+	ts, rawId, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, uuid.Nil, ErrInvalidPageToken
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, uuid.Nil, fmt.Errorf("%w : %v", ErrInvalidPageToken, err)
+	}
+
+	id, err := uuid.Parse(rawId)
+	if err != nil {
+		return time.Time{}, uuid.Nil, fmt.Errorf("%w : %v", ErrInvalidPageToken, err)
+	}
+
+	return timestamp, id, nil
+}
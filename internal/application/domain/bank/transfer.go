@@ -0,0 +1,62 @@
+package bank
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TransferDirectionSent     string = "SENT"
+	TransferDirectionReceived string = "RECEIVED"
+)
+
+type Transfer struct {
+	TransferUuid      uuid.UUID
+	FromAccountNumber string
+	ToAccountNumber   string
+	Currency          string
+	Amount            float64
+	FeeAmount         float64
+	FeeCurrency       string
+	Status            string
+	FailureReason     string
+	TransferTimestamp time.Time
+	AuthorizedAt      *time.Time
+	SettledAt         *time.Time
+	FailedAt          *time.Time
+	ReversedAt        *time.Time
+	ReversalReason    string
+}
+
+// TransferFilter selects the transfers of one account, an empty Direction means sent and received. Zero values mean
+// "no filter" like TransactionFilter. The page token is a TransactionCursor over (transfer_timestamp, transfer_uuid)
+type TransferFilter struct {
+	AccountNumber  string
+	Direction      string
+	Statuses       []string
+	StartTimestamp time.Time
+	EndTimestamp   time.Time
+	SortDescending bool
+	PageSize       int
+	PageToken      string
+}
+
+type TransferPage struct {
+	Transfers     []Transfer
+	NextPageToken string
+}
+
+var ErrInvalidTransferDirection = errors.New("invalid transfer direction, use SENT or RECEIVED")
+var ErrInvalidTransferStatus = errors.New("invalid transfer status")
+
+func ValidTransferStatus(status string) bool {
+	switch status {
+	case TransferStatusPending, TransferStatusAuthorized, TransferStatusSettled, TransferStatusFailed,
+		TransferStatusReversed, TransferStatusHeld:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,37 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	TransferStatusPending    string = "PENDING"
+	TransferStatusAuthorized string = "AUTHORIZED"
+	TransferStatusSettled    string = "SETTLED"
+	TransferStatusFailed     string = "FAILED"
+	TransferStatusReversed   string = "REVERSED"
+	TransferStatusHeld       string = "HELD"
+)
+
+var ErrInvalidTransferTransition = errors.New("invalid transfer status transition")
+var ErrTransferNotSettled = errors.New("transfer is not settled")
+
+// transferTransitions lists the statuses each status can move to, FAILED and REVERSED are final. HELD transfers
+// wait for a reviewer to approve (AUTHORIZED) or reject (FAILED) them
+var transferTransitions = map[string][]string{
+	TransferStatusPending:    {TransferStatusAuthorized, TransferStatusFailed},
+	TransferStatusHeld:       {TransferStatusAuthorized, TransferStatusFailed},
+	TransferStatusAuthorized: {TransferStatusSettled, TransferStatusFailed},
+	TransferStatusSettled:    {TransferStatusReversed},
+}
+
+func CheckTransferTransition(from string, to string) error {
+	for _, next := range transferTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w : %v -> %v", ErrInvalidTransferTransition, from, to)
+}
@@ -0,0 +1,189 @@
+package bank
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// webhook event types are what subscribers receive, they are derived from the domain events
+const (
+	WebhookEventTransferReceived string = "transfer.received"
+	WebhookEventLargeWithdrawal  string = "withdrawal.large"
+	WebhookEventLowBalance       string = "balance.low"
+)
+
+const (
+	WebhookStatusActive   string = "ACTIVE"
+	WebhookStatusDisabled string = "DISABLED"
+)
+
+const (
+	WebhookDeliveryPending   string = "PENDING"
+	WebhookDeliveryDelivered string = "DELIVERED"
+	WebhookDeliveryDead      string = "DEAD"
+)
+
+const (
+	WebhookSignatureHeader = "X-Bank-Signature"
+	WebhookEventHeader     = "X-Bank-Event"
+	WebhookDeliveryHeader  = "X-Bank-Delivery"
+)
+
+var ErrInvalidWebhookUrl = errors.New("webhook url must be an absolute http or https url")
+var ErrInvalidWebhookEventType = errors.New("invalid webhook event type, use transfer.received, withdrawal.large " +
+	"or balance.low")
+var ErrInvalidWebhookThreshold = errors.New("webhook threshold must be positive")
+var ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+var ErrWebhookDeliveryNotDead = errors.New("webhook delivery is not dead lettered")
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// NewWebhookSubscription registers a URL for some event types. An empty AccountNumber subscribes to every account,
+// an empty Secret is generated. withdrawal.large needs LargeWithdrawalAmount and balance.low needs LowBalanceAmount
+type NewWebhookSubscription struct {
+	AccountNumber         string
+	Url                   string
+	EventTypes            []string
+	Secret                string
+	LargeWithdrawalAmount *float64
+	LowBalanceAmount      *float64
+}
+
+type WebhookSubscription struct {
+	SubscriptionUuid      uuid.UUID
+	AccountNumber         string
+	Url                   string
+	EventTypes            []string
+	Secret                string
+	LargeWithdrawalAmount *float64
+	LowBalanceAmount      *float64
+	Status                string
+	CreatedAt             time.Time
+}
+
+func ValidWebhookEventType(eventType string) bool {
+	switch eventType {
+	case WebhookEventTransferReceived, WebhookEventLargeWithdrawal, WebhookEventLowBalance:
+		return true
+	default:
+		return false
+	}
+}
+
+type WebhookDelivery struct {
+	DeliveryUuid     uuid.UUID
+	SubscriptionUuid uuid.UUID
+	EventUuid        uuid.UUID
+	EventType        string
+	Status           string
+	Attempts         int
+	NextAttemptAt    time.Time
+	LastStatusCode   *int
+	LastError        string
+	CreatedAt        time.Time
+	DeliveredAt      *time.Time
+}
+
+type WebhookDeliveryAttempt struct {
+	Attempt     int
+	AttemptedAt time.Time
+	StatusCode  *int
+	Error       string
+	Duration    time.Duration
+}
+
+type WebhookDeadLetter struct {
+	DeliveryUuid     uuid.UUID
+	SubscriptionUuid uuid.UUID
+	EventType        string
+	Attempts         int
+	LastError        string
+	DeadAt           time.Time
+}
+
+// WebhookPayload is the body of every delivery, Data depends on the event type
+type WebhookPayload struct {
+	EventUuid     uuid.UUID   `json:"event_uuid"`
+	EventType     string      `json:"event_type"`
+	AccountNumber string      `json:"account_number"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Data          interface{} `json:"data"`
+}
+
+type LowBalanceWebhookData struct {
+	AccountNumber   string    `json:"account_number"`
+	Currency        string    `json:"currency"`
+	Balance         float64   `json:"balance"`
+	Threshold       float64   `json:"threshold"`
+	TransactionUuid uuid.UUID `json:"transaction_uuid"`
+}
+
+// SignWebhook returns the signature header value "t=<unix seconds>,v1=<hex hmac-sha256>", the HMAC covers the
+// timestamp and the body so a captured delivery can't be replayed later with a new timestamp
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	return "t=" + t + ",v1=" + webhookMac(secret, t, body)
+}
+
+// VerifyWebhookSignature is what a receiver does with the signature header, deliveries signed more than tolerance
+// away from now are rejected
+func VerifyWebhookSignature(secret string, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var t, v1 string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || v1 == "" {
+		return fmt.Errorf("%w : malformed header", ErrInvalidWebhookSignature)
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w : timestamp outside tolerance", ErrInvalidWebhookSignature)
+	}
+
+	if !hmac.Equal([]byte(v1), []byte(webhookMac(secret, t, body))) {
+		return ErrInvalidWebhookSignature
+	}
+
+	return nil
+}
+
+func webhookMac(secret string, t string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DecodeWebhookPayload decodes a delivery body, Data is left as raw JSON for the receiver to decode by event type
+func DecodeWebhookPayload(body []byte) (WebhookPayload, json.RawMessage, error) {
+	var raw struct {
+		WebhookPayload
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return WebhookPayload{}, nil, err
+	}
+
+	return raw.WebhookPayload, raw.Data, nil
+}
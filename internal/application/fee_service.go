@@ -0,0 +1,64 @@
+package application
+
+import (
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+)
+
+// FeeService reads the fee schedule, BankService uses it to charge and quote transfer fees
+type FeeService struct {
+	db port.FeeDatabasePort
+}
+
+func NewFeeService(dbPort port.FeeDatabasePort) *FeeService {
+	return &FeeService{
+		db: dbPort,
+	}
+}
+
+// FindFeeSchedule returns the rules valid at ts for the currency. If the currency has its own rules, the rules
+// without currency are ignored
+func (f *FeeService) FindFeeSchedule(currency string, ts time.Time) (bank.FeeSchedule, error) {
+	ruleOrms, err := f.db.GetFeeRules(currency, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule bank.FeeSchedule
+
+	for _, r := range ruleOrms {
+		// the currency rules come first, stop at the generic rules if there are any
+		if r.Currency == nil && len(schedule) > 0 && ruleOrms[0].Currency != nil {
+			break
+		}
+
+		schedule = append(schedule, bank.FeeRule{
+			FeeType:    r.FeeType,
+			MinAmount:  r.MinAmount,
+			FlatAmount: r.FlatAmount,
+			Percentage: r.Percentage,
+			MinFee:     r.MinFee,
+			MaxFee:     r.MaxFee,
+		})
+	}
+
+	return schedule, nil
+}
+
+// transferFee evaluates the schedule of the source account currency on the debited amount, the fee is rounded to
+// the minor units of that currency
+func (f *FeeService) transferFee(currencies *bank.CurrencyRegistry, debitCurrency string, debitAmount float64,
+	crossCurrency bool, ts time.Time) (float64, []bank.FeeComponent, error) {
+	schedule, err := f.FindFeeSchedule(debitCurrency, ts)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	fee, components := schedule.Evaluate(debitAmount, crossCurrency, func(amount float64) float64 {
+		return currencies.Round(debitCurrency, amount)
+	})
+
+	return fee, components, nil
+}
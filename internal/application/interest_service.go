@@ -0,0 +1,184 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"gorm.io/gorm"
+)
+
+// InterestService charges interest on overdrawn balances and pays interest on positive balances, once per account
+// and day, against the system interest account of the currency
+type InterestService struct {
+	db port.InterestDatabasePort
+}
+
+func NewInterestService(dbPort port.InterestDatabasePort) *InterestService {
+	return &InterestService{
+		db: dbPort,
+	}
+}
+
+// AccrueInterest accrues one day of interest on the balances at the end of date. Accounts that already have an
+// accrual for date are skipped, so the job can safely run more than once a day. The day is recorded as accrued only
+// if no account failed
+func (i *InterestService) AccrueInterest(date time.Time) (bank.InterestAccrualReport, error) {
+	date = toDate(date)
+
+	report := bank.InterestAccrualReport{
+		AccrualDate: date,
+	}
+
+	balanceOrms, err := i.db.GetAccountsForInterest(date)
+	if err != nil {
+		return report, err
+	}
+
+	report.AccountsChecked = len(balanceOrms)
+
+	for _, balanceOrm := range balanceOrms {
+		accrual, posted, err := i.accrue(balanceOrm, date)
+		if err != nil {
+			log.Printf("can't accrue interest on account %v for %v : %v\n", balanceOrm.AccountNumber,
+				date.Format(time.DateOnly), err)
+			report.Failed++
+			continue
+		}
+
+		if posted {
+			report.Accruals = append(report.Accruals, accrual)
+		}
+	}
+
+	if report.Failed > 0 {
+		return report, nil
+	}
+
+	err = i.db.SaveInterestRun(database.BankInterestRunOrm{
+		AccrualDate: date,
+		CompletedAt: time.Now(),
+	})
+
+	return report, err
+}
+
+// AccrueInterestThrough accrues every day from the one after the last accrued day up to until, so days missed while
+// the job wasn't running are caught up. Without a previous run only until is accrued. It stops at the first day with
+// failed accounts, the next run retries from that day
+func (i *InterestService) AccrueInterestThrough(until time.Time) ([]bank.InterestAccrualReport, error) {
+	until = toDate(until)
+
+	lastRun, err := i.db.GetLastInterestRun()
+	if err != nil {
+		return nil, err
+	}
+
+	date := until
+	if lastRun != nil {
+		date = toDate(*lastRun).AddDate(0, 0, 1)
+	}
+
+	var reports []bank.InterestAccrualReport
+
+	for ; !date.After(until); date = date.AddDate(0, 0, 1) {
+		report, err := i.AccrueInterest(date)
+		reports = append(reports, report)
+
+		if err != nil {
+			return reports, err
+		}
+
+		if report.Failed > 0 {
+			break
+		}
+	}
+
+	return reports, nil
+}
+
+func (i *InterestService) accrue(balanceOrm database.BankInterestBalanceOrm, date time.Time) (bank.InterestAccrual,
+	bool, error) {
+	accountOrm := balanceOrm.BankAccountOrm
+	balance := balanceOrm.ClosingBalance
+
+	rateType := bank.InterestRateTypeCredit
+	if balance < 0 {
+		rateType = bank.InterestRateTypeDebit
+	}
+
+	// only savings accounts are paid interest, overdraft interest is charged on every account
+	if rateType == bank.InterestRateTypeCredit && accountOrm.AccountProduct != bank.AccountProductSavings {
+		return bank.InterestAccrual{}, false, nil
+	}
+
+	rateOrm, err := i.db.GetInterestRate(accountOrm.Currency, rateType, math.Abs(balance), date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return bank.InterestAccrual{}, false, nil
+	} else if err != nil {
+		return bank.InterestAccrual{}, false, err
+	}
+
+	// negative for overdraft interest, positive for savings interest
+	amount := bank.DailyInterest(balance, rateOrm.AnnualRate)
+	if amount == 0 {
+		return bank.InterestAccrual{}, false, nil
+	}
+
+	interestAccountOrm, err := i.db.GetSystemAccount(bank.SystemAccountInterest, accountOrm.Currency)
+	if err != nil {
+		return bank.InterestAccrual{}, false, err
+	}
+
+	notes := fmt.Sprintf("Savings interest %.3f%% p.a. on balance %.2f for %v", rateOrm.AnnualRate*100,
+		balance, date.Format(time.DateOnly))
+	if rateType == bank.InterestRateTypeDebit {
+		notes = fmt.Sprintf("Overdraft interest %.3f%% p.a. on balance %.2f for %v", rateOrm.AnnualRate*100,
+			balance, date.Format(time.DateOnly))
+	}
+
+	now := time.Now()
+
+	entry := newLedgerEntryBuilder(bank.JournalTypeInterest, notes+" on "+accountOrm.AccountNumber, now)
+	transactionUuid := entry.post(accountOrm, amount, notes)
+	entry.post(interestAccountOrm, -amount, notes)
+
+	ledgerEntry, err := entry.build()
+	if err != nil {
+		return bank.InterestAccrual{}, false, err
+	}
+
+	// overdraft interest is charged even if it takes the account past its overdraft limit
+	ledgerEntry.Charge = true
+
+	posted, err := i.db.PostInterestAccrual(database.BankInterestAccrualOrm{
+		AccountUuid:     accountOrm.AccountUuid,
+		AccrualDate:     date,
+		RateType:        rateType,
+		Balance:         balance,
+		AnnualRate:      rateOrm.AnnualRate,
+		Amount:          amount,
+		TransactionUuid: &transactionUuid,
+		CreatedAt:       now,
+	}, ledgerEntry)
+
+	if err != nil || !posted {
+		return bank.InterestAccrual{}, false, err
+	}
+
+	return bank.InterestAccrual{
+		AccountUuid:     accountOrm.AccountUuid,
+		AccountNumber:   accountOrm.AccountNumber,
+		AccrualDate:     date,
+		RateType:        rateType,
+		Balance:         balance,
+		AnnualRate:      rateOrm.AnnualRate,
+		Amount:          amount,
+		TransactionUuid: transactionUuid,
+	}, true, nil
+}
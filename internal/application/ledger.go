@@ -0,0 +1,150 @@
+package application
+
+import (
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/google/uuid"
+)
+
+// ledgerEntryBuilder collects the postings of one journal entry. Postings on customer accounts also get a
+// bank_transactions row, which is the history the customer sees, and a transaction.created event
+type ledgerEntryBuilder struct {
+	entry database.LedgerEntry
+	now   time.Time
+	err   error
+}
+
+func newLedgerEntryBuilder(entryType string, description string, now time.Time) *ledgerEntryBuilder {
+	return &ledgerEntryBuilder{
+		entry: database.LedgerEntry{
+			Journal: database.LedgerJournalEntryOrm{
+				JournalUuid: uuid.New(),
+				EntryType:   entryType,
+				Description: description,
+				CreatedAt:   now,
+			},
+		},
+		now: now,
+	}
+}
+
+// reference links the entry to what it belongs to, e.g. the transfer or the reversed journal entry
+func (l *ledgerEntryBuilder) reference(referenceUuid uuid.UUID) *ledgerEntryBuilder {
+	l.entry.Journal.ReferenceUuid = &referenceUuid
+	return l
+}
+
+// post adds a signed amount to the account and returns the uuid of the customer transaction (uuid.Nil for system
+// accounts)
+func (l *ledgerEntryBuilder) post(acct database.BankAccountOrm, amount float64, notes string) uuid.UUID {
+	return l.postReversal(acct, amount, notes, nil)
+}
+
+// postReversal is post for compensating entries, the customer transaction is linked to the transaction it reverses
+func (l *ledgerEntryBuilder) postReversal(acct database.BankAccountOrm, amount float64, notes string,
+	reversalOf *uuid.UUID) uuid.UUID {
+	posting := database.LedgerPostingOrm{
+		PostingUuid: uuid.New(),
+		JournalUuid: l.entry.Journal.JournalUuid,
+		AccountUuid: acct.AccountUuid,
+		Currency:    acct.Currency,
+		Amount:      amount,
+		CreatedAt:   l.now,
+	}
+
+	transactionUuid := uuid.Nil
+
+	if acct.AccountType != bank.AccountTypeSystem {
+		transactionUuid = uuid.New()
+		transactionType := bank.TransactionTypeIN
+		transactionAmount := amount
+
+		if amount < 0 {
+			transactionType = bank.TransactionTypeOUT
+			transactionAmount = -amount
+		}
+
+		l.entry.Transactions = append(l.entry.Transactions, database.BankTransactionOrm{
+			TransactionUuid:      transactionUuid,
+			AccountUuid:          acct.AccountUuid,
+			TransactionTimestamp: l.now,
+			Amount:               transactionAmount,
+			TransactionType:      transactionType,
+			Notes:                notes,
+			ReversalOf:           reversalOf,
+			CreatedAt:            l.now,
+			UpdatedAt:            l.now,
+		})
+
+		posting.TransactionUuid = &transactionUuid
+
+		l.emit(bank.EventTransactionCreated, bank.AggregateAccount, acct.AccountNumber, bank.TransactionEventPayload{
+			TransactionUuid: transactionUuid,
+			AccountNumber:   acct.AccountNumber,
+			Currency:        acct.Currency,
+			TransactionType: transactionType,
+			Amount:          transactionAmount,
+			Notes:           notes,
+			ReversalOf:      reversalOf,
+			Timestamp:       l.now,
+		})
+	}
+
+	l.entry.Postings = append(l.entry.Postings, posting)
+
+	return transactionUuid
+}
+
+// adjust adds a signed amount to the account without a customer transaction, for corrections of the books the
+// transaction history already shows
+func (l *ledgerEntryBuilder) adjust(acct database.BankAccountOrm, amount float64) {
+	l.entry.Postings = append(l.entry.Postings, database.LedgerPostingOrm{
+		PostingUuid: uuid.New(),
+		JournalUuid: l.entry.Journal.JournalUuid,
+		AccountUuid: acct.AccountUuid,
+		Currency:    acct.Currency,
+		Amount:      amount,
+		CreatedAt:   l.now,
+	})
+}
+
+// emit adds an outbox event written in the same database transaction as the entry
+func (l *ledgerEntryBuilder) emit(eventType string, aggregateType string, aggregateId string, payload interface{}) {
+	e, err := newOutboxEvent(eventType, aggregateType, aggregateId, payload)
+	if err != nil {
+		l.err = err
+		return
+	}
+
+	l.entry.Events = append(l.entry.Events, e)
+}
+
+// build validates that the postings balance per currency before anything is written
+func (l *ledgerEntryBuilder) build() (database.LedgerEntry, error) {
+	if l.err != nil {
+		return database.LedgerEntry{}, l.err
+	}
+
+	journal := bank.JournalEntry{
+		JournalUuid: l.entry.Journal.JournalUuid,
+		EntryType:   l.entry.Journal.EntryType,
+		Description: l.entry.Journal.Description,
+		CreatedAt:   l.entry.Journal.CreatedAt,
+	}
+
+	for _, p := range l.entry.Postings {
+		journal.Postings = append(journal.Postings, bank.Posting{
+			AccountUuid: p.AccountUuid,
+			Currency:    p.Currency,
+			Amount:      p.Amount,
+		})
+	}
+
+	if err := journal.Validate(); err != nil {
+		return database.LedgerEntry{}, err
+	}
+
+	return l.entry, nil
+}
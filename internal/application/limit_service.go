@@ -0,0 +1,127 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LimitService holds the per tier and per account limits on outgoing money and checks them for BankService
+type LimitService struct {
+	db port.LimitDatabasePort
+}
+
+func NewLimitService(dbPort port.LimitDatabasePort) *LimitService {
+	return &LimitService{
+		db: dbPort,
+	}
+}
+
+func (l *LimitService) FindAccountLimits(accountNumber string) (bank.AccountLimits, error) {
+	accountOrm, err := l.findAccountOrm(accountNumber)
+	if err != nil {
+		return bank.AccountLimits{}, err
+	}
+
+	limitsOrm, err := l.db.GetEffectiveAccountLimits(accountOrm.AccountUuid)
+	if err != nil {
+		return bank.AccountLimits{}, err
+	}
+
+	return toAccountLimits(limitsOrm), nil
+}
+
+func (l *LimitService) SetAccountTier(accountNumber string, tier string) error {
+	accountOrm, err := l.findAccountOrm(accountNumber)
+	if err != nil {
+		return err
+	}
+
+	if _, err := l.db.GetLimitTier(tier); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w : %v", bank.ErrUnknownAccountTier, tier)
+		}
+
+		return err
+	}
+
+	return l.db.UpdateBankAccountTier(accountOrm, tier)
+}
+
+// SetAccountLimits overrides the tier limits for one account, nil limits fall back to the tier again. The minimum
+// balance is always overridden
+func (l *LimitService) SetAccountLimits(accountNumber string, limits bank.AccountLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	accountOrm, err := l.findAccountOrm(accountNumber)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	return l.db.SaveAccountLimits(database.BankAccountLimitsOrm{
+		AccountUuid:         accountOrm.AccountUuid,
+		MaxSingleOut:        limits.MaxSingleOut,
+		DailyOutLimit:       limits.DailyOut,
+		MonthlyOutLimit:     limits.MonthlyOut,
+		MaxTransfersPerHour: limits.MaxTransfersPerHour,
+		MinimumBalance:      &limits.MinimumBalance,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	})
+}
+
+// outgoingLimit is the check of amount (in the account currency) leaving the account, it fails with a
+// *bank.LimitExceededError. The check runs when the entry is posted, with the account row locked, so concurrent
+// payments can't overshoot a limit. transferUuid is the transfer being settled, uuid.Nil for a withdrawal
+func (l *LimitService) outgoingLimit(acct database.BankAccountOrm, amount float64, transferUuid uuid.UUID,
+	now time.Time) *database.LimitCheck {
+	day, month, hour := bank.LimitWindows(now)
+
+	return &database.LimitCheck{
+		AccountUuid:  acct.AccountUuid,
+		TransferUuid: transferUuid,
+		Day:          day,
+		Month:        month,
+		Hour:         hour,
+		Check: func(limitsOrm database.BankLimitTierOrm, usageOrm database.BankLimitUsageOrm) error {
+			return toAccountLimits(limitsOrm).Check(acct.AccountNumber, amount, transferUuid != uuid.Nil,
+				bank.LimitUsage{
+					Balance:           usageOrm.Balance,
+					OverdraftLimit:    usageOrm.OverdraftLimit,
+					DailyOut:          usageOrm.DailyOut,
+					MonthlyOut:        usageOrm.MonthlyOut,
+					TransfersLastHour: usageOrm.TransfersLastHour,
+				})
+		},
+	}
+}
+
+func (l *LimitService) findAccountOrm(accountNumber string) (database.BankAccountOrm, error) {
+	accountOrm, err := l.db.GetBankAccountByAccountNumber(accountNumber)
+	if err != nil {
+		return accountOrm, wrapAccountNotFound(accountNumber, err)
+	}
+
+	return accountOrm, nil
+}
+
+func toAccountLimits(l database.BankLimitTierOrm) bank.AccountLimits {
+	return bank.AccountLimits{
+		AccountTier:         l.AccountTier,
+		MaxSingleOut:        l.MaxSingleOut,
+		DailyOut:            l.DailyOutLimit,
+		MonthlyOut:          l.MonthlyOutLimit,
+		MaxTransfersPerHour: l.MaxTransfersPerHour,
+		MinimumBalance:      l.MinimumBalance,
+	}
+}
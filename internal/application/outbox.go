@@ -0,0 +1,128 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+)
+
+const (
+	outboxRelayBatchSize = 100
+	// a publish that takes longer is a failed attempt, the event is retried on a later run
+	outboxPublishTimeout = 10 * time.Second
+	// the batch is claimed for that long, the relay stops publishing before the claim runs out
+	outboxClaimLease = 2 * time.Minute
+)
+
+// OutboxRelay publishes the events written to the outbox to the sink, at least once and in order per aggregate
+type OutboxRelay struct {
+	db   port.OutboxDatabasePort
+	sink port.EventSinkPort
+}
+
+func NewOutboxRelay(dbPort port.OutboxDatabasePort, sink port.EventSinkPort) *OutboxRelay {
+	return &OutboxRelay{
+		db:   dbPort,
+		sink: sink,
+	}
+}
+
+// Relay publishes one batch of events and returns how many the sink accepted. The batch is claimed first and
+// published without holding a database transaction. Once an event of an aggregate fails, the later events of that
+// aggregate wait for the next run so consumers see each account's events in order
+func (o *OutboxRelay) Relay(ctx context.Context) (int, error) {
+	eventOrms, err := o.db.ClaimOutboxEvents(outboxRelayBatchSize, outboxClaimLease)
+	if err != nil || len(eventOrms) == 0 {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(outboxClaimLease - outboxPublishTimeout)
+
+	claimed := make([]int64, 0, len(eventOrms))
+	var published []int64
+	failed := make(map[int64]string)
+	blocked := make(map[string]bool)
+
+	for _, e := range eventOrms {
+		claimed = append(claimed, e.EventId)
+
+		aggregate := e.AggregateType + "/" + e.AggregateId
+		if blocked[aggregate] || time.Now().After(deadline) {
+			continue
+		}
+
+		if err := o.publish(ctx, e); err != nil {
+			blocked[aggregate] = true
+			failed[e.EventId] = err.Error()
+			continue
+		}
+
+		published = append(published, e.EventId)
+	}
+
+	return len(published), o.db.CompleteOutboxClaim(claimed, published, failed)
+}
+
+func (o *OutboxRelay) publish(ctx context.Context, e database.OutboxEventOrm) error {
+	ctx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+	defer cancel()
+
+	return o.sink.Publish(ctx, bank.Event{
+		EventUuid:     e.EventUuid,
+		EventType:     e.EventType,
+		EventVersion:  e.EventVersion,
+		AggregateType: e.AggregateType,
+		AggregateId:   e.AggregateId,
+		Payload:       json.RawMessage(e.Payload),
+		CreatedAt:     e.CreatedAt,
+	})
+}
+
+func newOutboxEvent(eventType string, aggregateType string, aggregateId string, payload interface{}) (
+	database.OutboxEventOrm, error) {
+	e, err := bank.NewEvent(eventType, aggregateType, aggregateId, payload)
+	if err != nil {
+		return database.OutboxEventOrm{}, err
+	}
+
+	return database.OutboxEventOrm{
+		EventUuid:     e.EventUuid,
+		EventType:     e.EventType,
+		EventVersion:  e.EventVersion,
+		AggregateType: e.AggregateType,
+		AggregateId:   e.AggregateId,
+		Payload:       string(e.Payload),
+		CreatedAt:     e.CreatedAt,
+	}, nil
+}
+
+func newAccountEvent(eventType string, a database.BankAccountOrm) (database.OutboxEventOrm, error) {
+	return newOutboxEvent(eventType, bank.AggregateAccount, a.AccountNumber, bank.AccountEventPayload{
+		AccountUuid:    a.AccountUuid,
+		AccountNumber:  a.AccountNumber,
+		AccountName:    a.AccountName,
+		Currency:       a.Currency,
+		Status:         a.AccountStatus,
+		OverdraftLimit: a.OverdraftLimit,
+	})
+}
+
+// newTransferEvent is keyed by the source account, the destination account is in the payload
+func newTransferEvent(eventType string, t database.BankTransferDetailOrm, status string, reason string) (
+	database.OutboxEventOrm, error) {
+	return newOutboxEvent(eventType, bank.AggregateAccount, t.FromAccountNumber, bank.TransferEventPayload{
+		TransferUuid:      t.TransferUuid,
+		FromAccountNumber: t.FromAccountNumber,
+		ToAccountNumber:   t.ToAccountNumber,
+		Currency:          t.Currency,
+		Amount:            t.Amount,
+		Fee:               t.FeeAmount,
+		Status:            status,
+		Reason:            reason,
+		Timestamp:         time.Now(),
+	})
+}
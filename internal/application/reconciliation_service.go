@@ -0,0 +1,158 @@
+package application
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+)
+
+// reconciliationCheckpointLag keeps the baselines behind the transactions that may still be committing when a run
+// reads the balances
+const reconciliationCheckpointLag = time.Hour
+
+type ReconciliationService struct {
+	db port.ReconciliationDatabasePort
+}
+
+func NewReconciliationService(dbPort port.ReconciliationDatabasePort) *ReconciliationService {
+	return &ReconciliationService{
+		db: dbPort,
+	}
+}
+
+// Reconcile recomputes every customer account balance and reports the accounts that drifted. The accounts that
+// reconcile get their baseline moved to the checkpoint of the run.
+//
+// With repair, an account whose stored balance matches its ledger but not its transaction history gets an
+// ADJUSTMENT journal entry against the adjustment system account, which brings both to the history. A stored
+// balance that differs from its ledger changed outside the ledger, it is left for a manual look
+func (r *ReconciliationService) Reconcile(repair bool) (bank.ReconciliationReport, error) {
+	report := bank.ReconciliationReport{
+		RunUuid:   uuid.New(),
+		StartedAt: time.Now(),
+		Repair:    repair,
+	}
+
+	checkpoint := report.StartedAt.Add(-reconciliationCheckpointLag)
+
+	balanceOrms, err := r.db.GetAccountBalancesForReconciliation(checkpoint)
+	if err != nil {
+		return report, err
+	}
+
+	report.AccountsChecked = len(balanceOrms)
+
+	var items []database.BankReconciliationItemOrm
+	var baselines []database.BankBalanceBaselineOrm
+
+	for _, b := range balanceOrms {
+		stored, expected, ledger := toCents(b.StoredBalance), toCents(b.ExpectedBalance), toCents(b.LedgerBalance)
+
+		if stored == expected && stored == ledger {
+			baselines = append(baselines, database.BankBalanceBaselineOrm{
+				AccountUuid:       b.AccountUuid,
+				BaselineBalance:   b.CheckpointBalance,
+				BaselineTimestamp: checkpoint,
+				CreatedAt:         report.StartedAt,
+				UpdatedAt:         report.StartedAt,
+			})
+
+			continue
+		}
+
+		item := database.BankReconciliationItemOrm{
+			ItemUuid:        uuid.New(),
+			RunUuid:         report.RunUuid,
+			AccountUuid:     b.AccountUuid,
+			StoredBalance:   b.StoredBalance,
+			ExpectedBalance: b.ExpectedBalance,
+			LedgerBalance:   b.LedgerBalance,
+			CreatedAt:       report.StartedAt,
+		}
+
+		if repair && stored == ledger {
+			adjustment, err := r.adjustment(b, item.ItemUuid, report.StartedAt)
+			if err != nil {
+				return report, err
+			}
+
+			item.Adjustment = &adjustment
+		}
+
+		items = append(items, item)
+
+		report.Mismatches = append(report.Mismatches, bank.BalanceMismatch{
+			AccountUuid:     b.AccountUuid,
+			AccountNumber:   b.AccountNumber,
+			Currency:        b.Currency,
+			StoredBalance:   b.StoredBalance,
+			ExpectedBalance: b.ExpectedBalance,
+			LedgerBalance:   b.LedgerBalance,
+			Difference:      b.StoredBalance - b.ExpectedBalance,
+		})
+	}
+
+	report.FinishedAt = time.Now()
+
+	run := database.BankReconciliationRunOrm{
+		RunUuid:         report.RunUuid,
+		StartedAt:       report.StartedAt,
+		FinishedAt:      report.FinishedAt,
+		AccountsChecked: report.AccountsChecked,
+		Mismatches:      len(report.Mismatches),
+		Repair:          repair,
+	}
+
+	if err := r.db.SaveReconciliationRun(run, items, baselines); err != nil {
+		return report, err
+	}
+
+	for i := range items {
+		report.Mismatches[i].Repaired = items[i].Repaired
+		report.Mismatches[i].AdjustmentJournalUuid = items[i].AdjustmentJournalUuid
+	}
+
+	return report, nil
+}
+
+// adjustment moves the balance and the ledger of the account to its expected balance. The transaction history
+// already has the amount, so the customer posting gets no transaction of its own
+func (r *ReconciliationService) adjustment(b database.BankAccountBalanceOrm, itemUuid uuid.UUID, now time.Time) (
+	database.LedgerEntry, error) {
+	adjustmentAccountOrm, err := r.db.GetSystemAccount(bank.SystemAccountAdjustment, b.Currency)
+	if err != nil {
+		return database.LedgerEntry{}, err
+	}
+
+	amount := float64(toCents(b.ExpectedBalance)-toCents(b.StoredBalance)) / 100
+
+	entry := newLedgerEntryBuilder(bank.JournalTypeAdjustment,
+		fmt.Sprintf("Reconciliation adjustment of %.2f on %v", amount, b.AccountNumber), now).
+		reference(itemUuid)
+	entry.adjust(database.BankAccountOrm{
+		AccountUuid:   b.AccountUuid,
+		AccountNumber: b.AccountNumber,
+		Currency:      b.Currency,
+		AccountType:   bank.AccountTypeCustomer,
+	}, amount)
+	entry.post(adjustmentAccountOrm, -amount, "")
+
+	ledgerEntry, err := entry.build()
+	if err != nil {
+		return database.LedgerEntry{}, err
+	}
+
+	// the adjustment corrects the books, the overdraft limit doesn't apply
+	ledgerEntry.Charge = true
+
+	return ledgerEntry, nil
+}
+
+func toCents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
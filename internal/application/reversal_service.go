@@ -0,0 +1,164 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReversalService undoes deposits, withdrawals and transfers with compensating entries, the original rows are never
+// modified
+type ReversalService struct {
+	db port.ReversalDatabasePort
+}
+
+func NewReversalService(dbPort port.ReversalDatabasePort) *ReversalService {
+	return &ReversalService{
+		db: dbPort,
+	}
+}
+
+// ReverseTransaction reverses a deposit or withdrawal and returns the uuid of the compensating transaction
+func (r *ReversalService) ReverseTransaction(transactionUuid uuid.UUID, reason string) (uuid.UUID, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return uuid.Nil, bank.ErrReversalReasonRequired
+	}
+
+	transactionOrm, err := r.db.GetTransactionByUuid(transactionUuid)
+	if err != nil {
+		return uuid.Nil, wrapNotFound(bank.ErrTransactionNotFound, transactionUuid, err)
+	}
+
+	if transactionOrm.ReversalOf != nil {
+		return uuid.Nil, bank.ErrReverseReversal
+	}
+
+	journal, err := r.db.GetJournalByTransaction(transactionUuid)
+	if err != nil {
+		return uuid.Nil, wrapNotFound(bank.ErrTransactionNotFound, transactionUuid, err)
+	}
+
+	if journal.Journal.EntryType == bank.JournalTypeTransfer {
+		return uuid.Nil, bank.ErrReverseTransferLeg
+	}
+
+	entry, reversalUuids, err := r.buildReversal(journal, reason)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := r.db.PostReversal(entry, nil, reason); err != nil {
+		return uuid.Nil, wrapAlreadyReversed(err)
+	}
+
+	log.Printf("transaction %v reversed : %v\n", transactionUuid, reason)
+
+	return reversalUuids[transactionUuid], nil
+}
+
+// ReverseTransfer reverses both legs of a transfer atomically and flags the transfer as reversed
+func (r *ReversalService) ReverseTransfer(transferUuid uuid.UUID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return bank.ErrReversalReasonRequired
+	}
+
+	transferOrm, err := r.db.GetTransferDetail(transferUuid)
+	if err != nil {
+		return wrapNotFound(bank.ErrTransferNotFound, transferUuid, err)
+	}
+
+	if transferOrm.TransferStatus == bank.TransferStatusReversed {
+		return fmt.Errorf("transfer %v : %w", transferUuid, bank.ErrAlreadyReversed)
+	}
+
+	if transferOrm.TransferStatus != bank.TransferStatusSettled {
+		return fmt.Errorf("transfer %v is %v : %w", transferUuid, transferOrm.TransferStatus,
+			bank.ErrTransferNotSettled)
+	}
+
+	journal, err := r.db.GetJournalByReference(transferUuid)
+	if err != nil {
+		return wrapNotFound(bank.ErrTransferNotFound, transferUuid, err)
+	}
+
+	entry, _, err := r.buildReversal(journal, reason)
+	if err != nil {
+		return err
+	}
+
+	event, err := newTransferEvent(bank.EventTransferReversed, transferOrm, bank.TransferStatusReversed, reason)
+	if err != nil {
+		return err
+	}
+
+	entry.Events = append(entry.Events, event)
+
+	if err := r.db.PostReversal(entry, &transferOrm.BankTransferOrm, reason); err != nil {
+		return wrapAlreadyReversed(err)
+	}
+
+	log.Printf("transfer %v reversed : %v\n", transferUuid, reason)
+
+	return nil
+}
+
+// buildReversal negates every posting of the journal entry. It returns the compensating transaction uuid of each
+// original transaction
+func (r *ReversalService) buildReversal(journal database.LedgerEntry, reason string) (database.LedgerEntry,
+	map[uuid.UUID]uuid.UUID, error) {
+	entry := newLedgerEntryBuilder(bank.JournalTypeReversal,
+		fmt.Sprintf("Reversal of %v : %v", journal.Journal.JournalUuid, reason), time.Now()).
+		reference(journal.Journal.JournalUuid)
+
+	reversalUuids := make(map[uuid.UUID]uuid.UUID)
+
+	for _, p := range journal.Postings {
+		acct, err := r.db.GetBankAccountByUuid(p.AccountUuid)
+		if err != nil {
+			return database.LedgerEntry{}, nil, err
+		}
+
+		notes := "Reversal : " + reason
+
+		if p.TransactionUuid != nil {
+			notes = fmt.Sprintf("Reversal of %v : %v", *p.TransactionUuid, reason)
+		}
+
+		reversalUuid := entry.postReversal(acct, -p.Amount, notes, p.TransactionUuid)
+
+		if p.TransactionUuid != nil {
+			reversalUuids[*p.TransactionUuid] = reversalUuid
+		}
+	}
+
+	ledgerEntry, err := entry.build()
+
+	return ledgerEntry, reversalUuids, err
+}
+
+func wrapNotFound(notFound error, id uuid.UUID, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w : %v", notFound, id)
+	}
+
+	return err
+}
+
+// wrapAlreadyReversed turns the unique violation on bank_transactions.reversal_of into ErrAlreadyReversed
+func wrapAlreadyReversed(err error) error {
+	if strings.Contains(err.Error(), "idx_bank_transactions_reversal_of") {
+		return fmt.Errorf("%w : %v", bank.ErrAlreadyReversed, err)
+	}
+
+	return err
+}
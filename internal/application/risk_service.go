@@ -0,0 +1,252 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+)
+
+// riskHistoryWindow is how far back the outgoing operations of an account are compared with
+const riskHistoryWindow = 90 * 24 * time.Hour
+
+// RuleBasedRiskEvaluator is the built-in RiskEvaluatorPort, it applies the rules to the history of the account
+type RuleBasedRiskEvaluator struct {
+	db    port.RiskDatabasePort
+	rules bank.RiskRules
+}
+
+func NewRuleBasedRiskEvaluator(dbPort port.RiskDatabasePort, rules bank.RiskRules) *RuleBasedRiskEvaluator {
+	return &RuleBasedRiskEvaluator{
+		db:    dbPort,
+		rules: rules,
+	}
+}
+
+func (r *RuleBasedRiskEvaluator) Evaluate(req bank.RiskRequest) (bank.RiskAssessment, error) {
+	historyOrm, err := r.db.GetRiskHistory(req.AccountNumber, req.CounterpartyAccountNumber,
+		req.Timestamp.Add(-riskHistoryWindow), req.Timestamp.Add(-r.rules.RapidWindow))
+	if err != nil {
+		return bank.RiskAssessment{}, err
+	}
+
+	return r.rules.Assess(req, bank.RiskHistory{
+		TransfersToCounterparty: historyOrm.TransfersToCounterparty,
+		OutCount:                historyOrm.OutCount,
+		OutAverage:              historyOrm.OutAverage,
+		OutStdDev:               historyOrm.OutStdDev,
+		RecentOutCount:          historyOrm.RecentOutCount,
+	}), nil
+}
+
+// evaluateRisk returns a *bank.RiskDeniedError for denied operations, the denial is recorded without reference as
+// nothing else is written
+func (b *BankService) evaluateRisk(req bank.RiskRequest) (bank.RiskAssessment, error) {
+	if b.risk == nil {
+		return bank.RiskAssessment{Decision: bank.RiskDecisionAllow}, nil
+	}
+
+	assessment, err := b.risk.Evaluate(req)
+	if err != nil {
+		return assessment, fmt.Errorf("can't evaluate risk of %v on %v : %w", req.Operation, req.AccountNumber, err)
+	}
+
+	if assessment.Decision == bank.RiskDecisionDeny {
+		b.recordRiskAssessment(req, assessment, nil)
+		return assessment, &bank.RiskDeniedError{Assessment: assessment}
+	}
+
+	return assessment, nil
+}
+
+// recordRiskAssessment is best effort, the decision was already applied
+func (b *BankService) recordRiskAssessment(req bank.RiskRequest, assessment bank.RiskAssessment,
+	referenceUuid *uuid.UUID) {
+	if err := b.db.CreateRiskAssessment(toRiskAssessmentOrm(req, assessment, referenceUuid)); err != nil {
+		log.Printf("can't record risk assessment %v of %v on %v : %v\n", assessment.Decision, req.Operation,
+			req.AccountNumber, err)
+	}
+}
+
+func (b *BankService) ListHeldTransfers(pageSize int) ([]bank.HeldTransfer, error) {
+	transferOrms, err := b.db.GetHeldTransfers(normalizePageSize(pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	held := make([]bank.HeldTransfer, 0, len(transferOrms))
+
+	for _, t := range transferOrms {
+		h := bank.HeldTransfer{Transfer: toTransfer(t)}
+
+		if assessmentOrm, err := b.db.GetRiskAssessmentByReference(t.TransferUuid); err == nil {
+			h.Assessment = toRiskAssessment(assessmentOrm)
+		} else {
+			log.Printf("can't find risk assessment of held transfer %v : %v\n", t.TransferUuid, err)
+		}
+
+		held = append(held, h)
+	}
+
+	return held, nil
+}
+
+// ApproveHeldTransfer settles a held transfer as if it was just made : accounts, balance and limits are checked
+// again with the current rates. The fee stays the one recorded when the transfer was held
+func (b *BankService) ApproveHeldTransfer(transferUuid uuid.UUID, reviewer string, note string) (
+	bank.TransferResult, error) {
+	transferOrm, err := b.reviewHeldTransfer(transferUuid, reviewer, bank.RiskReviewApproved, note)
+	if err != nil {
+		return bank.TransferResult{}, err
+	}
+
+	result := bank.TransferResult{
+		TransferUuid: transferUuid,
+	}
+
+	tt := bank.TransferTransaction{
+		FromAccountNumber: transferOrm.FromAccountNumber,
+		ToAccountNumber:   transferOrm.ToAccountNumber,
+		Currency:          transferOrm.Currency,
+		Amount:            transferOrm.Amount,
+	}
+
+	now := time.Now()
+
+	fromAccountOrm, toAccountOrm, quote, err := b.prepareTransfer(tt, now)
+	if err != nil {
+		return result, b.failTransfer(transferOrm.BankTransferOrm, tt, bank.TransferStatusHeld, err)
+	}
+
+	if quote.Fee != transferOrm.FeeAmount {
+		quote.Fee = transferOrm.FeeAmount
+		quote.FeeComponents = nil
+	}
+
+	result.Quote = quote
+
+	if err := b.settleTransfer(transferOrm.BankTransferOrm, tt, fromAccountOrm, toAccountOrm, quote,
+		bank.TransferStatusHeld, now); err != nil {
+		return result, err
+	}
+
+	result.Success = true
+
+	return result, nil
+}
+
+func (b *BankService) RejectHeldTransfer(transferUuid uuid.UUID, reviewer string, reason string) error {
+	transferOrm, err := b.reviewHeldTransfer(transferUuid, reviewer, bank.RiskReviewRejected, reason)
+	if err != nil {
+		return err
+	}
+
+	tt := bank.TransferTransaction{
+		FromAccountNumber: transferOrm.FromAccountNumber,
+		ToAccountNumber:   transferOrm.ToAccountNumber,
+		Currency:          transferOrm.Currency,
+		Amount:            transferOrm.Amount,
+	}
+
+	rejection := fmt.Errorf("%w by %v", bank.ErrTransferRejected, reviewer)
+	if reason != "" {
+		rejection = fmt.Errorf("%w by %v : %v", bank.ErrTransferRejected, reviewer, reason)
+	}
+
+	b.failTransfer(transferOrm.BankTransferOrm, tt, bank.TransferStatusHeld, rejection)
+
+	return nil
+}
+
+// reviewHeldTransfer records the review on the assessment of the transfer, an assessment is reviewed once so two
+// reviewers can't both decide. The reviewer must be one of the configured reviewers and can't be the actor that
+// requested the transfer
+func (b *BankService) reviewHeldTransfer(transferUuid uuid.UUID, reviewer string, decision string, note string) (
+	database.BankTransferDetailOrm, error) {
+	if reviewer == "" {
+		return database.BankTransferDetailOrm{}, bank.ErrReviewerRequired
+	}
+
+	if !b.reviewers[reviewer] {
+		return database.BankTransferDetailOrm{}, fmt.Errorf("%w : %v", bank.ErrReviewerNotAllowed, reviewer)
+	}
+
+	transferOrm, err := b.db.GetTransferDetail(transferUuid)
+	if err != nil {
+		return transferOrm, wrapNotFound(bank.ErrTransferNotFound, transferUuid, err)
+	}
+
+	if transferOrm.RequestedBy != nil && *transferOrm.RequestedBy == reviewer {
+		return transferOrm, fmt.Errorf("%w : %v can't review %v", bank.ErrReviewerIsRequester, reviewer,
+			transferUuid)
+	}
+
+	if transferOrm.TransferStatus != bank.TransferStatusHeld {
+		return transferOrm, fmt.Errorf("%w : %v is %v", bank.ErrTransferNotHeld, transferUuid,
+			transferOrm.TransferStatus)
+	}
+
+	assessmentOrm, err := b.db.GetRiskAssessmentByReference(transferUuid)
+	if err != nil {
+		return transferOrm, err
+	}
+
+	reviewed, err := b.db.ReviewRiskAssessment(assessmentOrm.AssessmentUuid, reviewer, decision, note)
+	if err != nil {
+		return transferOrm, err
+	}
+
+	if !reviewed {
+		return transferOrm, fmt.Errorf("%w : %v was already reviewed", bank.ErrTransferNotHeld, transferUuid)
+	}
+
+	return transferOrm, nil
+}
+
+func toRiskAssessmentOrm(req bank.RiskRequest, assessment bank.RiskAssessment,
+	referenceUuid *uuid.UUID) database.BankRiskAssessmentOrm {
+	signals := assessment.Signals
+	if signals == nil {
+		signals = []bank.RiskSignal{}
+	}
+
+	// RiskSignal only has strings and ints, it always marshals
+	signalsJson, _ := json.Marshal(signals)
+
+	assessmentOrm := database.BankRiskAssessmentOrm{
+		AssessmentUuid: uuid.New(),
+		Operation:      req.Operation,
+		AccountNumber:  req.AccountNumber,
+		Currency:       req.Currency,
+		Amount:         req.Amount,
+		Decision:       assessment.Decision,
+		Score:          assessment.Score,
+		Signals:        string(signalsJson),
+		ReferenceUuid:  referenceUuid,
+		CreatedAt:      time.Now(),
+	}
+
+	if req.CounterpartyAccountNumber != "" {
+		assessmentOrm.CounterpartyNumber = &req.CounterpartyAccountNumber
+	}
+
+	return assessmentOrm
+}
+
+func toRiskAssessment(a database.BankRiskAssessmentOrm) bank.RiskAssessment {
+	assessment := bank.RiskAssessment{
+		Decision: a.Decision,
+		Score:    a.Score,
+	}
+
+	if err := json.Unmarshal([]byte(a.Signals), &assessment.Signals); err != nil {
+		log.Printf("can't read signals of risk assessment %v : %v\n", a.AssessmentUuid, err)
+	}
+
+	return assessment
+}
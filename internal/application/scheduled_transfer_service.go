@@ -0,0 +1,281 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	scheduledTransferBatchSize  = 50
+	scheduledTransferLease      = 5 * time.Minute
+	scheduledTransferMaxAttempt = 5
+	scheduledTransferRetryDelay = 30 * time.Second
+)
+
+// ScheduledTransferService stores future and recurring transfers and executes them through BankService.Transfer
+type ScheduledTransferService struct {
+	db          port.ScheduledTransferDatabasePort
+	bankService port.BankServicePort
+}
+
+func NewScheduledTransferService(dbPort port.ScheduledTransferDatabasePort,
+	bankService port.BankServicePort) *ScheduledTransferService {
+	return &ScheduledTransferService{
+		db:          dbPort,
+		bankService: bankService,
+	}
+}
+
+func (s *ScheduledTransferService) ScheduleTransfer(tt bank.TransferTransaction, startAt time.Time, recurrence string,
+	endAt *time.Time) (bank.ScheduledTransfer, error) {
+	if err := bank.CheckAmount(tt.Amount); err != nil {
+		return bank.ScheduledTransfer{}, err
+	}
+
+	if !bank.ValidRecurrence(recurrence) {
+		return bank.ScheduledTransfer{}, bank.ErrInvalidRecurrence
+	}
+
+	if endAt != nil && endAt.Before(startAt) {
+		return bank.ScheduledTransfer{}, bank.ErrInvalidTimeRange
+	}
+
+	if err := s.bankService.ValidateCurrency(tt.Currency); err != nil {
+		return bank.ScheduledTransfer{}, err
+	}
+
+	if _, err := s.db.GetBankAccountByAccountNumber(tt.FromAccountNumber); err != nil {
+		return bank.ScheduledTransfer{}, bank.ErrTransferSourceAccountNotFound
+	}
+
+	if _, err := s.db.GetBankAccountByAccountNumber(tt.ToAccountNumber); err != nil {
+		return bank.ScheduledTransfer{}, bank.ErrTransferDestinationAccountNotFound
+	}
+
+	now := time.Now()
+
+	scheduleOrm := database.BankScheduledTransferOrm{
+		ScheduleUuid:      uuid.New(),
+		FromAccountNumber: tt.FromAccountNumber,
+		ToAccountNumber:   tt.ToAccountNumber,
+		Currency:          tt.Currency,
+		Amount:            tt.Amount,
+		Recurrence:        recurrence,
+		StartAt:           startAt,
+		EndAt:             endAt,
+		NextRunAt:         startAt,
+		DueAt:             startAt,
+		Status:            bank.ScheduleStatusActive,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if tt.RequestedBy != "" {
+		scheduleOrm.RequestedBy = &tt.RequestedBy
+	}
+
+	if _, err := s.db.CreateScheduledTransfer(scheduleOrm); err != nil {
+		return bank.ScheduledTransfer{}, err
+	}
+
+	return toScheduledTransfer(scheduleOrm), nil
+}
+
+func (s *ScheduledTransferService) CancelScheduledTransfer(scheduleUuid uuid.UUID) error {
+	cancelled, err := s.db.CancelScheduledTransfer(scheduleUuid)
+	if err != nil {
+		return err
+	}
+
+	if !cancelled {
+		if _, err := s.db.GetScheduledTransfer(scheduleUuid); errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w : %v", bank.ErrScheduledTransferNotFound, scheduleUuid)
+		}
+
+		return bank.ErrScheduledTransferNotActive
+	}
+
+	return nil
+}
+
+func (s *ScheduledTransferService) FindScheduledTransfer(scheduleUuid uuid.UUID) (bank.ScheduledTransfer, error) {
+	scheduleOrm, err := s.db.GetScheduledTransfer(scheduleUuid)
+	if err != nil {
+		return bank.ScheduledTransfer{}, wrapNotFound(bank.ErrScheduledTransferNotFound, scheduleUuid, err)
+	}
+
+	return toScheduledTransfer(scheduleOrm), nil
+}
+
+func (s *ScheduledTransferService) FindScheduledTransferExecutions(scheduleUuid uuid.UUID) (
+	[]bank.ScheduledTransferExecution, error) {
+	if _, err := s.db.GetScheduledTransfer(scheduleUuid); err != nil {
+		return nil, wrapNotFound(bank.ErrScheduledTransferNotFound, scheduleUuid, err)
+	}
+
+	executionOrms, err := s.db.GetScheduledTransferExecutions(scheduleUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	executions := make([]bank.ScheduledTransferExecution, 0, len(executionOrms))
+	for _, e := range executionOrms {
+		executions = append(executions, bank.ScheduledTransferExecution{
+			ExecutionUuid: e.ExecutionUuid,
+			ScheduleUuid:  e.ScheduleUuid,
+			ScheduledFor:  e.ScheduledFor,
+			ExecutedAt:    e.ExecutedAt,
+			Attempt:       e.Attempt,
+			Outcome:       e.Outcome,
+			TransferUuid:  e.TransferUuid,
+			Reason:        e.Reason,
+		})
+	}
+
+	return executions, nil
+}
+
+// ExecuteDueTransfers runs every scheduled transfer that is due and returns how many were attempted
+func (s *ScheduledTransferService) ExecuteDueTransfers() (int, error) {
+	now := time.Now()
+
+	scheduleOrms, err := s.db.ClaimDueScheduledTransfers(now, now.Add(scheduledTransferLease),
+		scheduledTransferBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, scheduleOrm := range scheduleOrms {
+		s.execute(scheduleOrm)
+	}
+
+	return len(scheduleOrms), nil
+}
+
+func (s *ScheduledTransferService) execute(scheduleOrm database.BankScheduledTransferOrm) {
+	now := time.Now()
+
+	execution := database.BankScheduledTransferExecutionOrm{
+		ExecutionUuid: uuid.New(),
+		ScheduleUuid:  scheduleOrm.ScheduleUuid,
+		ScheduledFor:  scheduleOrm.NextRunAt,
+		ExecutedAt:    now,
+		Attempt:       scheduleOrm.Attempt + 1,
+	}
+
+	tt := bank.TransferTransaction{
+		FromAccountNumber: scheduleOrm.FromAccountNumber,
+		ToAccountNumber:   scheduleOrm.ToAccountNumber,
+		Currency:          scheduleOrm.Currency,
+		Amount:            scheduleOrm.Amount,
+	}
+
+	// the transfer is requested by whoever scheduled it
+	if scheduleOrm.RequestedBy != nil {
+		tt.RequestedBy = *scheduleOrm.RequestedBy
+	}
+
+	result, err := s.bankService.Transfer(tt)
+
+	// failed transfers are recorded too, the execution refers to them
+	if result.TransferUuid != uuid.Nil {
+		execution.TransferUuid = &result.TransferUuid
+	}
+
+	switch {
+	case err == nil:
+		execution.Outcome = bank.ExecutionOutcomeSuccess
+		if result.Held {
+			execution.Reason = "transfer held for review"
+		}
+		advanceSchedule(&scheduleOrm)
+	case errors.Is(err, bank.ErrInsufficientFunds), errors.Is(err, bank.ErrLimitExceeded):
+		// not retried, the occurrence is skipped and the next one will try again
+		execution.Outcome = bank.ExecutionOutcomeSkipped
+		execution.Reason = err.Error()
+		advanceSchedule(&scheduleOrm)
+	case isTransientTransferError(err) && execution.Attempt < scheduledTransferMaxAttempt:
+		execution.Outcome = bank.ExecutionOutcomeRetry
+		execution.Reason = err.Error()
+		scheduleOrm.Attempt = execution.Attempt
+		scheduleOrm.DueAt = now.Add(scheduledTransferRetryDelay * time.Duration(1<<(execution.Attempt-1)))
+	default:
+		execution.Outcome = bank.ExecutionOutcomeFailed
+		execution.Reason = err.Error()
+		advanceSchedule(&scheduleOrm)
+	}
+
+	if err := s.db.RecordScheduledTransferExecution(scheduleOrm, execution); err != nil {
+		log.Printf("can't record execution of scheduled transfer %v : %v\n", scheduleOrm.ScheduleUuid, err)
+		return
+	}
+
+	log.Printf("scheduled transfer %v (%v) : %v %v\n", scheduleOrm.ScheduleUuid,
+		execution.ScheduledFor.Format(time.RFC3339), execution.Outcome, execution.Reason)
+}
+
+// advanceSchedule moves to the next occurrence, or completes the schedule when there is none left
+func advanceSchedule(scheduleOrm *database.BankScheduledTransferOrm) {
+	scheduleOrm.OccurrenceCount++
+	scheduleOrm.Attempt = 0
+
+	next := bank.Occurrence(scheduleOrm.StartAt, scheduleOrm.Recurrence, scheduleOrm.OccurrenceCount)
+
+	if scheduleOrm.Recurrence == bank.RecurrenceNone || (scheduleOrm.EndAt != nil && next.After(*scheduleOrm.EndAt)) {
+		scheduleOrm.Status = bank.ScheduleStatusCompleted
+		return
+	}
+
+	scheduleOrm.NextRunAt = next
+	scheduleOrm.DueAt = next
+}
+
+// isTransientTransferError is false for the errors that won't go away by retrying (bad account, currency, rate,
+// amount, or a risk denial or screening block that would record its hits again)
+func isTransientTransferError(err error) bool {
+	for _, permanent := range []error{
+		bank.ErrTransferSourceAccountNotFound,
+		bank.ErrTransferDestinationAccountNotFound,
+		bank.ErrAccountFrozen,
+		bank.ErrAccountClosed,
+		bank.ErrUnknownCurrency,
+		bank.ErrCurrencyDisabled,
+		bank.ErrSameAccountTransfer,
+		bank.ErrExchangeRateNotFound,
+		bank.ErrInvalidAmount,
+		bank.ErrInvalidAmountPrecision,
+		bank.ErrRiskDenied,
+		bank.ErrScreeningBlocked,
+	} {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func toScheduledTransfer(s database.BankScheduledTransferOrm) bank.ScheduledTransfer {
+	return bank.ScheduledTransfer{
+		ScheduleUuid: s.ScheduleUuid,
+		Transfer: bank.TransferTransaction{
+			FromAccountNumber: s.FromAccountNumber,
+			ToAccountNumber:   s.ToAccountNumber,
+			Currency:          s.Currency,
+			Amount:            s.Amount,
+		},
+		Recurrence: s.Recurrence,
+		StartAt:    s.StartAt,
+		EndAt:      s.EndAt,
+		NextRunAt:  s.NextRunAt,
+		Status:     s.Status,
+	}
+}
@@ -0,0 +1,147 @@
+package application
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+)
+
+// ScreeningService screens account holders and transfer parties against the list file. The file is read again by
+// Reload when it changed, a list that doesn't parse is ignored and the previous one is kept. It fails closed : until
+// a list is loaded every screened operation is refused
+type ScreeningService struct {
+	db        port.ScreeningDatabasePort
+	path      string
+	threshold float64
+
+	mu      sync.RWMutex
+	list    *bank.ScreeningList
+	modTime time.Time
+	size    int64
+}
+
+func NewScreeningService(dbPort port.ScreeningDatabasePort, path string, threshold float64) *ScreeningService {
+	return &ScreeningService{
+		db:        dbPort,
+		path:      path,
+		threshold: threshold,
+	}
+}
+
+// Reload reads the list file if it changed since the last load and tells whether it did. On error the list loaded
+// before stays in use
+func (s *ScreeningService) Reload() (bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, fmt.Errorf("can't read screening list %v : %w", s.path, err)
+	}
+
+	s.mu.RLock()
+	unchanged := s.list != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size
+	s.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return false, fmt.Errorf("can't read screening list %v : %w", s.path, err)
+	}
+	defer f.Close()
+
+	list, err := bank.ParseScreeningList(f)
+	if err != nil {
+		return false, fmt.Errorf("can't load screening list %v : %w", s.path, err)
+	}
+
+	s.mu.Lock()
+	s.list = list
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	s.mu.Unlock()
+
+	log.Printf("screening list %v loaded : %d entries\n", s.path, list.Len())
+
+	return true, nil
+}
+
+// screen records the hits of the parties and returns a *bank.ScreeningBlockedError if one of them blocks the
+// operation. referenceUuid is the account or transfer the operation creates, it is only kept when it goes ahead
+func (s *ScreeningService) screen(operation string, referenceUuid uuid.UUID, parties ...bank.ScreeningParty) error {
+	s.mu.RLock()
+	list := s.list
+	s.mu.RUnlock()
+
+	if list == nil {
+		return fmt.Errorf("%w : %v", bank.ErrScreeningUnavailable, s.path)
+	}
+
+	hits := list.Screen(s.threshold, parties...)
+	if len(hits) == 0 {
+		return nil
+	}
+
+	blocked := false
+	for _, h := range hits {
+		blocked = blocked || h.Entry.Action == bank.ScreeningActionBlock
+	}
+
+	var reference *uuid.UUID
+	if !blocked {
+		reference = &referenceUuid
+	}
+
+	now := time.Now()
+	hitOrms := make([]database.BankScreeningHitOrm, 0, len(hits))
+
+	for _, h := range hits {
+		hitOrm := database.BankScreeningHitOrm{
+			HitUuid:             uuid.New(),
+			Operation:           operation,
+			PartyRole:           h.Party.Role,
+			PartyName:           h.Party.Name,
+			ListedName:          h.Entry.Name,
+			ListedAccountNumber: h.Entry.AccountNumber,
+			ListReason:          h.Entry.Reason,
+			MatchType:           h.MatchType,
+			Score:               h.Score,
+			Action:              h.Entry.Action,
+			ReferenceUuid:       reference,
+			CreatedAt:           now,
+		}
+
+		if h.Party.AccountNumber != "" {
+			hitOrm.AccountNumber = &h.Party.AccountNumber
+		}
+
+		hitOrms = append(hitOrms, hitOrm)
+
+		log.Printf("screening hit on %v : %v %v %q matches %q %v (%v %.2f) : %v\n", operation, h.Party.Role,
+			h.Party.AccountNumber, h.Party.Name, h.Entry.Name, h.Entry.AccountNumber, h.MatchType, h.Score,
+			h.Entry.Action)
+	}
+
+	// a blocked operation stays blocked even if its hits can't be recorded, a flagged one is not let through
+	// without a record
+	if err := s.db.CreateScreeningHits(hitOrms); err != nil {
+		if !blocked {
+			return fmt.Errorf("can't record screening hits : %w", err)
+		}
+
+		log.Printf("can't record screening hits on %v : %v\n", operation, err)
+	}
+
+	if blocked {
+		return &bank.ScreeningBlockedError{Hits: hits}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package application
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"gorm.io/gorm"
+)
+
+const transferRecoveryReason = "interrupted before settlement"
+
+// TransferRecoveryService resolves transfers left PENDING or AUTHORIZED, e.g. by a crash between the steps of
+// BankService.Transfer
+type TransferRecoveryService struct {
+	db port.TransferRecoveryDatabasePort
+}
+
+func NewTransferRecoveryService(dbPort port.TransferRecoveryDatabasePort) *TransferRecoveryService {
+	return &TransferRecoveryService{
+		db: dbPort,
+	}
+}
+
+// RecoverTransfers settles the stuck transfers whose journal entry was posted and fails the others. Only transfers
+// older than olderThan are touched so transfers still running on another server are left alone. It returns how many
+// transfers were resolved
+func (t *TransferRecoveryService) RecoverTransfers(olderThan time.Duration) (int, error) {
+	transferOrms, err := t.db.GetTransfersInProgress(time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+
+	for _, transferOrm := range transferOrms {
+		to := bank.TransferStatusFailed
+		reason := transferRecoveryReason
+
+		_, err := t.db.GetJournalByReference(transferOrm.TransferUuid)
+		if err == nil && transferOrm.TransferStatus == bank.TransferStatusAuthorized {
+			to = bank.TransferStatusSettled
+			reason = ""
+		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("can't recover transfer %v : %v\n", transferOrm.TransferUuid, err)
+			continue
+		}
+
+		eventType := bank.EventTransferFailed
+		if to == bank.TransferStatusSettled {
+			eventType = bank.EventTransferSettled
+		}
+
+		event, err := newTransferEvent(eventType, transferOrm, to, reason)
+		if err != nil {
+			return resolved, err
+		}
+
+		if err := t.db.UpdateTransferState(transferOrm.BankTransferOrm, transferOrm.TransferStatus, to, reason,
+			event); err != nil {
+			log.Printf("can't recover transfer %v : %v\n", transferOrm.TransferUuid, err)
+			continue
+		}
+
+		log.Printf("transfer %v recovered : %v -> %v\n", transferOrm.TransferUuid, transferOrm.TransferStatus, to)
+		resolved++
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,508 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookBatchSize     = 50
+	webhookLease         = 2 * time.Minute
+	webhookMaxAttempts   = 8
+	webhookRetryDelay    = 30 * time.Second
+	webhookMaxRetryDelay = 1 * time.Hour
+)
+
+// WebhookService turns domain events into webhook deliveries for the matching subscriptions and delivers them with
+// retries. It is fed by the outbox relay through Publish, so a delivery is only queued for committed changes
+type WebhookService struct {
+	db     port.WebhookDatabasePort
+	sender port.WebhookSenderPort
+}
+
+func NewWebhookService(dbPort port.WebhookDatabasePort, sender port.WebhookSenderPort) *WebhookService {
+	return &WebhookService{
+		db:     dbPort,
+		sender: sender,
+	}
+}
+
+func (w *WebhookService) CreateWebhookSubscription(ns bank.NewWebhookSubscription) (bank.WebhookSubscription,
+	error) {
+	if u, err := url.Parse(ns.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return bank.WebhookSubscription{}, bank.ErrInvalidWebhookUrl
+	}
+
+	var eventTypes []string
+	seen := make(map[string]bool)
+
+	for _, eventType := range ns.EventTypes {
+		if !bank.ValidWebhookEventType(eventType) {
+			return bank.WebhookSubscription{}, fmt.Errorf("%w : %v", bank.ErrInvalidWebhookEventType, eventType)
+		}
+
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	if len(eventTypes) == 0 {
+		return bank.WebhookSubscription{}, bank.ErrInvalidWebhookEventType
+	}
+
+	for eventType, threshold := range map[string]*float64{
+		bank.WebhookEventLargeWithdrawal: ns.LargeWithdrawalAmount,
+		bank.WebhookEventLowBalance:      ns.LowBalanceAmount,
+	} {
+		if threshold != nil && *threshold <= 0 {
+			return bank.WebhookSubscription{}, bank.ErrInvalidWebhookThreshold
+		}
+
+		if seen[eventType] && threshold == nil {
+			return bank.WebhookSubscription{}, fmt.Errorf("%w : %v needs a threshold", bank.ErrInvalidWebhookThreshold,
+				eventType)
+		}
+	}
+
+	var accountNumber *string
+
+	if ns.AccountNumber != "" {
+		if _, err := w.db.GetBankAccountByAccountNumber(ns.AccountNumber); err != nil {
+			return bank.WebhookSubscription{}, wrapAccountNotFound(ns.AccountNumber, err)
+		}
+
+		accountNumber = &ns.AccountNumber
+	}
+
+	secret := ns.Secret
+	if secret == "" {
+		generated, err := newWebhookSecret()
+		if err != nil {
+			return bank.WebhookSubscription{}, err
+		}
+
+		secret = generated
+	}
+
+	now := time.Now()
+
+	subscriptionOrm := database.WebhookSubscriptionOrm{
+		SubscriptionUuid:      uuid.New(),
+		AccountNumber:         accountNumber,
+		Url:                   ns.Url,
+		EventTypes:            strings.Join(eventTypes, ","),
+		Secret:                secret,
+		LargeWithdrawalAmount: ns.LargeWithdrawalAmount,
+		LowBalanceAmount:      ns.LowBalanceAmount,
+		Status:                bank.WebhookStatusActive,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+
+	if _, err := w.db.CreateWebhookSubscription(subscriptionOrm); err != nil {
+		return bank.WebhookSubscription{}, err
+	}
+
+	return toWebhookSubscription(subscriptionOrm), nil
+}
+
+// DisableWebhookSubscription stops new deliveries, the ones already queued are dead lettered when they are due
+func (w *WebhookService) DisableWebhookSubscription(subscriptionUuid uuid.UUID) error {
+	disabled, err := w.db.DisableWebhookSubscription(subscriptionUuid)
+	if err != nil {
+		return err
+	}
+
+	if !disabled {
+		if _, err := w.db.GetWebhookSubscription(subscriptionUuid); errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w : %v", bank.ErrWebhookSubscriptionNotFound, subscriptionUuid)
+		}
+	}
+
+	return nil
+}
+
+func (w *WebhookService) FindWebhookDeliveries(subscriptionUuid uuid.UUID) ([]bank.WebhookDelivery, error) {
+	deliveryOrms, err := w.db.GetWebhookDeliveries(subscriptionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]bank.WebhookDelivery, 0, len(deliveryOrms))
+	for _, d := range deliveryOrms {
+		deliveries = append(deliveries, bank.WebhookDelivery{
+			DeliveryUuid:     d.DeliveryUuid,
+			SubscriptionUuid: d.SubscriptionUuid,
+			EventUuid:        d.EventUuid,
+			EventType:        d.EventType,
+			Status:           d.Status,
+			Attempts:         d.Attempts,
+			NextAttemptAt:    d.NextAttemptAt,
+			LastStatusCode:   d.LastStatusCode,
+			LastError:        d.LastError,
+			CreatedAt:        d.CreatedAt,
+			DeliveredAt:      d.DeliveredAt,
+		})
+	}
+
+	return deliveries, nil
+}
+
+func (w *WebhookService) FindWebhookDeliveryAttempts(deliveryUuid uuid.UUID) ([]bank.WebhookDeliveryAttempt, error) {
+	attemptOrms, err := w.db.GetWebhookDeliveryAttempts(deliveryUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]bank.WebhookDeliveryAttempt, 0, len(attemptOrms))
+	for _, a := range attemptOrms {
+		attempts = append(attempts, bank.WebhookDeliveryAttempt{
+			Attempt:     a.Attempt,
+			AttemptedAt: a.AttemptedAt,
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			Duration:    time.Duration(a.DurationMs) * time.Millisecond,
+		})
+	}
+
+	return attempts, nil
+}
+
+func (w *WebhookService) FindWebhookDeadLetters(subscriptionUuid uuid.UUID) ([]bank.WebhookDeadLetter, error) {
+	deadLetterOrms, err := w.db.GetWebhookDeadLetters(subscriptionUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters := make([]bank.WebhookDeadLetter, 0, len(deadLetterOrms))
+	for _, d := range deadLetterOrms {
+		deadLetters = append(deadLetters, bank.WebhookDeadLetter{
+			DeliveryUuid:     d.DeliveryUuid,
+			SubscriptionUuid: d.SubscriptionUuid,
+			EventType:        d.EventType,
+			Attempts:         d.Attempts,
+			LastError:        d.LastError,
+			DeadAt:           d.DeadAt,
+		})
+	}
+
+	return deadLetters, nil
+}
+
+// RedeliverWebhook queues a dead lettered delivery again with a fresh set of attempts
+func (w *WebhookService) RedeliverWebhook(deliveryUuid uuid.UUID) error {
+	redelivered, err := w.db.RedeliverWebhookDeadLetter(deliveryUuid, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !redelivered {
+		return fmt.Errorf("%w : %v", bank.ErrWebhookDeliveryNotDead, deliveryUuid)
+	}
+
+	return nil
+}
+
+// Publish queues the webhook deliveries an event triggers, it is the event sink the outbox relay feeds. Deliveries
+// are keyed by event, so an event published again doesn't queue them twice
+func (w *WebhookService) Publish(ctx context.Context, e bank.Event) error {
+	var deliveries []database.WebhookDeliveryOrm
+	var err error
+
+	switch e.EventType {
+	case bank.EventTransferSettled:
+		deliveries, err = w.transferDeliveries(e)
+	case bank.EventTransactionCreated:
+		deliveries, err = w.withdrawalDeliveries(e)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return w.db.EnqueueWebhookDeliveries(deliveries)
+}
+
+func (w *WebhookService) transferDeliveries(e bank.Event) ([]database.WebhookDeliveryOrm, error) {
+	var transfer bank.TransferEventPayload
+
+	if err := json.Unmarshal(e.Payload, &transfer); err != nil {
+		return nil, err
+	}
+
+	subscriptionOrms, err := w.db.GetActiveWebhookSubscriptions(transfer.ToAccountNumber,
+		bank.WebhookEventTransferReceived)
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []database.WebhookDeliveryOrm
+
+	for _, s := range subscriptionOrms {
+		delivery, err := newWebhookDelivery(s, e, bank.WebhookEventTransferReceived, transfer.ToAccountNumber, transfer)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// withdrawalDeliveries handles outgoing customer transactions, reversals are not withdrawals. The low balance check
+// uses the balance at the time the event is relayed and only fires when this transaction crossed the threshold
+func (w *WebhookService) withdrawalDeliveries(e bank.Event) ([]database.WebhookDeliveryOrm, error) {
+	var transaction bank.TransactionEventPayload
+
+	if err := json.Unmarshal(e.Payload, &transaction); err != nil {
+		return nil, err
+	}
+
+	if transaction.TransactionType != bank.TransactionTypeOUT || transaction.ReversalOf != nil {
+		return nil, nil
+	}
+
+	var deliveries []database.WebhookDeliveryOrm
+
+	largeOrms, err := w.db.GetActiveWebhookSubscriptions(transaction.AccountNumber, bank.WebhookEventLargeWithdrawal)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range largeOrms {
+		if s.LargeWithdrawalAmount == nil || transaction.Amount < *s.LargeWithdrawalAmount {
+			continue
+		}
+
+		delivery, err := newWebhookDelivery(s, e, bank.WebhookEventLargeWithdrawal, transaction.AccountNumber,
+			transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	lowOrms, err := w.db.GetActiveWebhookSubscriptions(transaction.AccountNumber, bank.WebhookEventLowBalance)
+	if err != nil || len(lowOrms) == 0 {
+		return deliveries, err
+	}
+
+	accountOrm, err := w.db.GetBankAccountByAccountNumber(transaction.AccountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range lowOrms {
+		if s.LowBalanceAmount == nil {
+			continue
+		}
+
+		threshold := *s.LowBalanceAmount
+		if accountOrm.CurrentBalance >= threshold || accountOrm.CurrentBalance+transaction.Amount < threshold {
+			continue
+		}
+
+		delivery, err := newWebhookDelivery(s, e, bank.WebhookEventLowBalance, transaction.AccountNumber,
+			bank.LowBalanceWebhookData{
+				AccountNumber:   accountOrm.AccountNumber,
+				Currency:        accountOrm.Currency,
+				Balance:         accountOrm.CurrentBalance,
+				Threshold:       threshold,
+				TransactionUuid: transaction.TransactionUuid,
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// DeliverDueWebhooks sends every delivery that is due and returns how many were attempted
+func (w *WebhookService) DeliverDueWebhooks(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	deliveryOrms, err := w.db.ClaimDueWebhookDeliveries(now, now.Add(webhookLease), webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := make(map[uuid.UUID]database.WebhookSubscriptionOrm)
+
+	for _, deliveryOrm := range deliveryOrms {
+		subscriptionOrm, ok := subscriptions[deliveryOrm.SubscriptionUuid]
+		if !ok {
+			if subscriptionOrm, err = w.db.GetWebhookSubscription(deliveryOrm.SubscriptionUuid); err != nil {
+				log.Printf("can't load webhook subscription %v : %v\n", deliveryOrm.SubscriptionUuid, err)
+				continue
+			}
+
+			subscriptions[deliveryOrm.SubscriptionUuid] = subscriptionOrm
+		}
+
+		w.deliver(ctx, deliveryOrm, subscriptionOrm)
+	}
+
+	return len(deliveryOrms), nil
+}
+
+func (w *WebhookService) deliver(ctx context.Context, deliveryOrm database.WebhookDeliveryOrm,
+	subscriptionOrm database.WebhookSubscriptionOrm) {
+	start := time.Now()
+	deliveryOrm.Attempts++
+
+	attempt := database.WebhookDeliveryAttemptOrm{
+		AttemptUuid:  uuid.New(),
+		DeliveryUuid: deliveryOrm.DeliveryUuid,
+		Attempt:      deliveryOrm.Attempts,
+		AttemptedAt:  start,
+	}
+
+	if subscriptionOrm.Status != bank.WebhookStatusActive {
+		// not retried, there is nobody to deliver to anymore
+		attempt.Error = "subscription " + subscriptionOrm.Status
+		deliveryOrm.Attempts = webhookMaxAttempts
+	} else {
+		body := []byte(deliveryOrm.Payload)
+
+		statusCode, err := w.sender.Send(ctx, subscriptionOrm.Url, map[string]string{
+			"Content-Type":              "application/json",
+			bank.WebhookSignatureHeader: bank.SignWebhook(subscriptionOrm.Secret, start, body),
+			bank.WebhookEventHeader:     deliveryOrm.EventType,
+			bank.WebhookDeliveryHeader:  deliveryOrm.DeliveryUuid.String(),
+		}, body)
+
+		switch {
+		case err != nil:
+			attempt.Error = err.Error()
+		case statusCode < 200 || statusCode > 299:
+			attempt.StatusCode = &statusCode
+			attempt.Error = fmt.Sprintf("receiver answered %d", statusCode)
+		default:
+			attempt.StatusCode = &statusCode
+		}
+	}
+
+	now := time.Now()
+	attempt.DurationMs = now.Sub(start).Milliseconds()
+	deliveryOrm.LastStatusCode = attempt.StatusCode
+	deliveryOrm.LastError = attempt.Error
+
+	var deadLetter *database.WebhookDeadLetterOrm
+
+	switch {
+	case attempt.Error == "":
+		deliveryOrm.Status = bank.WebhookDeliveryDelivered
+		deliveryOrm.DeliveredAt = &now
+	case deliveryOrm.Attempts >= webhookMaxAttempts:
+		deliveryOrm.Status = bank.WebhookDeliveryDead
+		deadLetter = &database.WebhookDeadLetterOrm{
+			DeliveryUuid:     deliveryOrm.DeliveryUuid,
+			SubscriptionUuid: deliveryOrm.SubscriptionUuid,
+			EventType:        deliveryOrm.EventType,
+			Payload:          deliveryOrm.Payload,
+			Attempts:         deliveryOrm.Attempts,
+			LastError:        attempt.Error,
+			DeadAt:           now,
+		}
+	default:
+		deliveryOrm.NextAttemptAt = now.Add(webhookBackoff(deliveryOrm.Attempts))
+	}
+
+	if err := w.db.RecordWebhookDeliveryAttempt(deliveryOrm, attempt, deadLetter); err != nil {
+		log.Printf("can't record webhook delivery %v : %v\n", deliveryOrm.DeliveryUuid, err)
+		return
+	}
+
+	if attempt.Error != "" {
+		log.Printf("webhook delivery %v attempt %d to %v failed : %v\n", deliveryOrm.DeliveryUuid, attempt.Attempt,
+			subscriptionOrm.Url, attempt.Error)
+	}
+}
+
+// webhookBackoff doubles the delay after every failed attempt, up to webhookMaxRetryDelay
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryDelay
+
+	for i := 1; i < attempts && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, webhookMaxRetryDelay)
+}
+
+func newWebhookDelivery(s database.WebhookSubscriptionOrm, e bank.Event, eventType string, accountNumber string,
+	data interface{}) (database.WebhookDeliveryOrm, error) {
+	payload, err := json.Marshal(bank.WebhookPayload{
+		EventUuid:     e.EventUuid,
+		EventType:     eventType,
+		AccountNumber: accountNumber,
+		CreatedAt:     e.CreatedAt,
+		Data:          data,
+	})
+	if err != nil {
+		return database.WebhookDeliveryOrm{}, err
+	}
+
+	now := time.Now()
+
+	return database.WebhookDeliveryOrm{
+		DeliveryUuid:     uuid.New(),
+		SubscriptionUuid: s.SubscriptionUuid,
+		EventUuid:        e.EventUuid,
+		EventType:        eventType,
+		Payload:          string(payload),
+		Status:           bank.WebhookDeliveryPending,
+		NextAttemptAt:    now,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}, nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func toWebhookSubscription(s database.WebhookSubscriptionOrm) bank.WebhookSubscription {
+	subscription := bank.WebhookSubscription{
+		SubscriptionUuid:      s.SubscriptionUuid,
+		Url:                   s.Url,
+		EventTypes:            strings.Split(s.EventTypes, ","),
+		Secret:                s.Secret,
+		LargeWithdrawalAmount: s.LargeWithdrawalAmount,
+		LowBalanceAmount:      s.LowBalanceAmount,
+		Status:                s.Status,
+		CreatedAt:             s.CreatedAt,
+	}
+
+	if s.AccountNumber != nil {
+		subscription.AccountNumber = *s.AccountNumber
+	}
+
+	return subscription
+}
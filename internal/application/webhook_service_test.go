@@ -0,0 +1,343 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/adapter/webhook"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+	"github.com/Just-Goo/grpc-go-server/internal/port"
+	"github.com/google/uuid"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// errWebhookTestUnsupported is returned by the methods of webhookTestDatabase the delivery tests don't use
+var errWebhookTestUnsupported = errors.New("not supported by the webhook test database")
+
+// webhookTestDatabase keeps one subscription and its deliveries in memory
+type webhookTestDatabase struct {
+	subscription database.WebhookSubscriptionOrm
+	deliveries   map[uuid.UUID]database.WebhookDeliveryOrm
+	attempts     []database.WebhookDeliveryAttemptOrm
+	deadLetters  []database.WebhookDeadLetterOrm
+}
+
+func newWebhookTestDatabase(url string) *webhookTestDatabase {
+	return &webhookTestDatabase{
+		subscription: database.WebhookSubscriptionOrm{
+			SubscriptionUuid: uuid.New(),
+			Url:              url,
+			EventTypes:       bank.WebhookEventTransferReceived,
+			Secret:           testWebhookSecret,
+			Status:           bank.WebhookStatusActive,
+		},
+		deliveries: make(map[uuid.UUID]database.WebhookDeliveryOrm),
+	}
+}
+
+func (d *webhookTestDatabase) enqueue(payload string) uuid.UUID {
+	delivery := database.WebhookDeliveryOrm{
+		DeliveryUuid:     uuid.New(),
+		SubscriptionUuid: d.subscription.SubscriptionUuid,
+		EventUuid:        uuid.New(),
+		EventType:        bank.WebhookEventTransferReceived,
+		Payload:          payload,
+		Status:           bank.WebhookDeliveryPending,
+		NextAttemptAt:    time.Now(),
+	}
+
+	d.deliveries[delivery.DeliveryUuid] = delivery
+
+	return delivery.DeliveryUuid
+}
+
+// makeDue moves the next attempt of the delivery to now, as if its backoff had passed
+func (d *webhookTestDatabase) makeDue(deliveryUuid uuid.UUID) {
+	delivery := d.deliveries[deliveryUuid]
+	delivery.NextAttemptAt = time.Now()
+	d.deliveries[deliveryUuid] = delivery
+}
+
+func (d *webhookTestDatabase) ClaimDueWebhookDeliveries(now time.Time, leaseUntil time.Time, limit int) (
+	[]database.WebhookDeliveryOrm, error) {
+	var due []database.WebhookDeliveryOrm
+
+	for _, delivery := range d.deliveries {
+		if delivery.Status == bank.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, delivery)
+		}
+	}
+
+	return due, nil
+}
+
+func (d *webhookTestDatabase) GetWebhookSubscription(subscriptionUuid uuid.UUID) (database.WebhookSubscriptionOrm,
+	error) {
+	if subscriptionUuid != d.subscription.SubscriptionUuid {
+		return database.WebhookSubscriptionOrm{}, errors.New("unknown subscription")
+	}
+
+	return d.subscription, nil
+}
+
+func (d *webhookTestDatabase) RecordWebhookDeliveryAttempt(delivery database.WebhookDeliveryOrm,
+	attempt database.WebhookDeliveryAttemptOrm, deadLetter *database.WebhookDeadLetterOrm) error {
+	d.deliveries[delivery.DeliveryUuid] = delivery
+	d.attempts = append(d.attempts, attempt)
+
+	if deadLetter != nil {
+		d.deadLetters = append(d.deadLetters, *deadLetter)
+	}
+
+	return nil
+}
+
+func (d *webhookTestDatabase) GetBankAccountByAccountNumber(acct string) (database.BankAccountOrm, error) {
+	return database.BankAccountOrm{}, errWebhookTestUnsupported
+}
+
+func (d *webhookTestDatabase) CreateWebhookSubscription(s database.WebhookSubscriptionOrm) (uuid.UUID, error) {
+	return uuid.Nil, errWebhookTestUnsupported
+}
+
+func (d *webhookTestDatabase) DisableWebhookSubscription(subscriptionUuid uuid.UUID) (bool, error) {
+	return false, errWebhookTestUnsupported
+}
+
+func (d *webhookTestDatabase) GetActiveWebhookSubscriptions(accountNumber string, eventType string) (
+	[]database.WebhookSubscriptionOrm, error) {
+	return nil, errWebhookTestUnsupported
+}
+
+func (d *webhookTestDatabase) EnqueueWebhookDeliveries(deliveries []database.WebhookDeliveryOrm) error {
+	return errWebhookTestUnsupported
+}
+
+func (d *webhookTestDatabase) GetWebhookDeliveries(subscriptionUuid uuid.UUID) ([]database.WebhookDeliveryOrm,
+	error) {
+	return nil, errWebhookTestUnsupported
+}
+
+func (d *webhookTestDatabase) GetWebhookDeliveryAttempts(deliveryUuid uuid.UUID) (
+	[]database.WebhookDeliveryAttemptOrm, error) {
+	return nil, errWebhookTestUnsupported
+}
+
+func (d *webhookTestDatabase) GetWebhookDeadLetters(subscriptionUuid uuid.UUID) ([]database.WebhookDeadLetterOrm,
+	error) {
+	return nil, errWebhookTestUnsupported
+}
+
+func (d *webhookTestDatabase) RedeliverWebhookDeadLetter(deliveryUuid uuid.UUID, now time.Time) (bool, error) {
+	return false, errWebhookTestUnsupported
+}
+
+var _ port.WebhookDatabasePort = (*webhookTestDatabase)(nil)
+
+// webhookReceiver is the httptest receiver, it answers status and keeps what it was sent
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+
+	w.WriteHeader(r.status)
+}
+
+func newWebhookTest(t *testing.T, status int) (*WebhookService, *webhookTestDatabase, *webhookReceiver) {
+	receiver := &webhookReceiver{status: status}
+
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	db := newWebhookTestDatabase(server.URL)
+
+	return NewWebhookService(db, webhook.NewHttpSender(server.Client())), db, receiver
+}
+
+func TestDeliverDueWebhooksSignsTheBody(t *testing.T) {
+	ws, db, receiver := newWebhookTest(t, http.StatusNoContent)
+
+	payload := `{"event_type":"transfer.received"}`
+	deliveryUuid := db.enqueue(payload)
+
+	n, err := ws.DeliverDueWebhooks(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("DeliverDueWebhooks = %d, %v, want 1 delivery", n, err)
+	}
+
+	if len(receiver.requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(receiver.requests))
+	}
+
+	req, body := receiver.requests[0], receiver.bodies[0]
+
+	if string(body) != payload {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+
+	if got := req.Header.Get(bank.WebhookDeliveryHeader); got != deliveryUuid.String() {
+		t.Errorf("%v = %v, want %v", bank.WebhookDeliveryHeader, got, deliveryUuid)
+	}
+
+	if got := req.Header.Get(bank.WebhookEventHeader); got != bank.WebhookEventTransferReceived {
+		t.Errorf("%v = %v, want %v", bank.WebhookEventHeader, got, bank.WebhookEventTransferReceived)
+	}
+
+	signature := req.Header.Get(bank.WebhookSignatureHeader)
+
+	if err := bank.VerifyWebhookSignature(testWebhookSecret, signature, body, time.Now(), time.Minute); err != nil {
+		t.Errorf("VerifyWebhookSignature = %v, want nil", err)
+	}
+
+	if err := bank.VerifyWebhookSignature("whsec_other", signature, body, time.Now(),
+		time.Minute); !errors.Is(err, bank.ErrInvalidWebhookSignature) {
+		t.Errorf("VerifyWebhookSignature with another secret = %v, want %v", err, bank.ErrInvalidWebhookSignature)
+	}
+
+	if err := bank.VerifyWebhookSignature(testWebhookSecret, signature, []byte(`{"event_type":"tampered"}`),
+		time.Now(), time.Minute); !errors.Is(err, bank.ErrInvalidWebhookSignature) {
+		t.Errorf("VerifyWebhookSignature of another body = %v, want %v", err, bank.ErrInvalidWebhookSignature)
+	}
+
+	if err := bank.VerifyWebhookSignature(testWebhookSecret, signature, body, time.Now().Add(time.Hour),
+		time.Minute); !errors.Is(err, bank.ErrInvalidWebhookSignature) {
+		t.Errorf("VerifyWebhookSignature an hour later = %v, want %v", err, bank.ErrInvalidWebhookSignature)
+	}
+
+	delivery := db.deliveries[deliveryUuid]
+
+	if delivery.Status != bank.WebhookDeliveryDelivered || delivery.DeliveredAt == nil {
+		t.Errorf("delivery status = %v, want %v with a delivery time", delivery.Status, bank.WebhookDeliveryDelivered)
+	}
+
+	if delivery.Attempts != 1 || len(db.attempts) != 1 || db.attempts[0].Error != "" {
+		t.Errorf("delivery attempts = %d, recorded %+v, want one successful attempt", delivery.Attempts, db.attempts)
+	}
+}
+
+func TestDeliverDueWebhooksBacksOff(t *testing.T) {
+	ws, db, _ := newWebhookTest(t, http.StatusInternalServerError)
+
+	deliveryUuid := db.enqueue(`{}`)
+
+	for attempt := 1; attempt <= 4; attempt++ {
+		db.makeDue(deliveryUuid)
+
+		before := time.Now()
+
+		if _, err := ws.DeliverDueWebhooks(context.Background()); err != nil {
+			t.Fatalf("DeliverDueWebhooks = %v", err)
+		}
+
+		delivery := db.deliveries[deliveryUuid]
+
+		if delivery.Status != bank.WebhookDeliveryPending || delivery.Attempts != attempt {
+			t.Fatalf("after attempt %d the delivery is %v with %d attempts", attempt, delivery.Status,
+				delivery.Attempts)
+		}
+
+		if delivery.LastStatusCode == nil || *delivery.LastStatusCode != http.StatusInternalServerError {
+			t.Errorf("attempt %d last status code = %v, want %d", attempt, delivery.LastStatusCode,
+				http.StatusInternalServerError)
+		}
+
+		// the delay doubles from webhookRetryDelay
+		want := webhookRetryDelay << (attempt - 1)
+
+		if delay := delivery.NextAttemptAt.Sub(before); delay < want || delay > want+time.Second {
+			t.Errorf("attempt %d retries in %v, want %v", attempt, delay, want)
+		}
+
+		// the delivery isn't due again before its backoff
+		if n, _ := ws.DeliverDueWebhooks(context.Background()); n != 0 {
+			t.Errorf("attempt %d : %d deliveries claimed before the backoff passed", attempt, n)
+		}
+	}
+
+	if got := webhookBackoff(20); got != webhookMaxRetryDelay {
+		t.Errorf("webhookBackoff(20) = %v, want the maximum %v", got, webhookMaxRetryDelay)
+	}
+}
+
+func TestDeliverDueWebhooksDeadLetters(t *testing.T) {
+	ws, db, receiver := newWebhookTest(t, http.StatusBadGateway)
+
+	deliveryUuid := db.enqueue(`{}`)
+
+	for i := 0; i < webhookMaxAttempts; i++ {
+		db.makeDue(deliveryUuid)
+
+		if _, err := ws.DeliverDueWebhooks(context.Background()); err != nil {
+			t.Fatalf("DeliverDueWebhooks = %v", err)
+		}
+	}
+
+	delivery := db.deliveries[deliveryUuid]
+
+	if delivery.Status != bank.WebhookDeliveryDead || delivery.Attempts != webhookMaxAttempts {
+		t.Fatalf("delivery is %v with %d attempts, want %v with %d", delivery.Status, delivery.Attempts,
+			bank.WebhookDeliveryDead, webhookMaxAttempts)
+	}
+
+	if len(receiver.requests) != webhookMaxAttempts {
+		t.Errorf("receiver got %d requests, want %d", len(receiver.requests), webhookMaxAttempts)
+	}
+
+	if len(db.deadLetters) != 1 {
+		t.Fatalf("%d dead letters, want 1", len(db.deadLetters))
+	}
+
+	deadLetter := db.deadLetters[0]
+
+	if deadLetter.DeliveryUuid != deliveryUuid || deadLetter.Attempts != webhookMaxAttempts ||
+		deadLetter.LastError == "" {
+		t.Errorf("dead letter = %+v, want delivery %v after %d attempts with the last error", deadLetter,
+			deliveryUuid, webhookMaxAttempts)
+	}
+
+	// a dead delivery isn't attempted again
+	db.makeDue(deliveryUuid)
+
+	if n, _ := ws.DeliverDueWebhooks(context.Background()); n != 0 || len(receiver.requests) != webhookMaxAttempts {
+		t.Errorf("dead delivery attempted again")
+	}
+}
+
+func TestDeliverDueWebhooksDeadLettersDisabledSubscriptions(t *testing.T) {
+	ws, db, receiver := newWebhookTest(t, http.StatusOK)
+
+	db.subscription.Status = bank.WebhookStatusDisabled
+	deliveryUuid := db.enqueue(`{}`)
+
+	if _, err := ws.DeliverDueWebhooks(context.Background()); err != nil {
+		t.Fatalf("DeliverDueWebhooks = %v", err)
+	}
+
+	if len(receiver.requests) != 0 {
+		t.Errorf("receiver got %d requests for a disabled subscription", len(receiver.requests))
+	}
+
+	if delivery := db.deliveries[deliveryUuid]; delivery.Status != bank.WebhookDeliveryDead || len(db.deadLetters) != 1 {
+		t.Errorf("delivery is %v with %d dead letters, want %v with 1", delivery.Status, len(db.deadLetters),
+			bank.WebhookDeliveryDead)
+	}
+}
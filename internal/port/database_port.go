@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/Just-Goo/grpc-go-server/internal/adapter/database"
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
 	"github.com/google/uuid"
 )
 
@@ -14,11 +15,145 @@ type DummyDatabasePort interface {
 
 type BankDatabasePort interface {
 	GetBankAccountByAccountNumber(acct string) (database.BankAccountOrm, error)
-	CreateExchangeRate(r *database.BankExchangeRateOrm) (uuid.UUID, error)
+	CreateExchangeRate(r *database.BankExchangeRateOrm, events ...database.OutboxEventOrm) (uuid.UUID, error)
 	GetExchangeRateAtTimestamp(fromCur string, toCur string, ts time.Time) (database.BankExchangeRateOrm, error)
-	CreateTransaction(acct database.BankAccountOrm, transaction database.BankTransactionOrm) (uuid.UUID, error)
+	GetExchangeRatesInRange(fromCur string, toCur string, start time.Time, end time.Time,
+		cursor *bank.ExchangeRateCursor, limit int) ([]database.BankExchangeRateOrm, error)
+	GetExchangeRateCandles(fromCur string, toCur string, bucket string, start time.Time, end time.Time,
+		after time.Time, limit int) ([]database.BankExchangeRateCandleOrm, error)
 	CreateTransfer(transfer database.BankTransferOrm) (uuid.UUID, error)
-	CreateTransferTransactionPair(fromAccountOrm database.BankAccountOrm, toAccountOrm database.BankAccountOrm,
-	fromTransactionOrm database.BankTransactionOrm, toTransactionOrm database.BankTransactionOrm) (bool, error)
-	UpdateTransferStatus(transfer database.BankTransferOrm, status bool) error
+	UpdateTransferState(transfer database.BankTransferOrm, from string, to string, reason string,
+		events ...database.OutboxEventOrm) error
+	SettleTransfer(transfer database.BankTransferOrm, entry database.LedgerEntry) error
+	GetTransferDetail(transferUuid uuid.UUID) (database.BankTransferDetailOrm, error)
+	ListTransfers(accountUuid uuid.UUID, filter bank.TransferFilter, cursor *bank.TransactionCursor, limit int) (
+		[]database.BankTransferDetailOrm, error)
+	PostLedgerEntry(entry database.LedgerEntry) error
+	GetSystemAccount(kind string, currency string) (database.BankAccountOrm, error)
+	GetLedgerBalance(accountUuid uuid.UUID) (float64, error)
+	GetDailySummaries(accountUuid uuid.UUID, startDate time.Time, endDate time.Time) (
+		[]database.BankTransactionDailySummaryOrm, error)
+	RebuildDailySummaries() (int64, error)
+	GetStatementSnapshot(acct string, start time.Time, end time.Time) (database.StatementSnapshot, error)
+	ListTransactions(accountUuid uuid.UUID, filter bank.TransactionFilter, cursor *bank.TransactionCursor,
+		limit int) ([]database.BankTransactionOrm, error)
+	CreateRiskAssessment(assessment database.BankRiskAssessmentOrm) error
+	CreateHeldTransfer(transfer database.BankTransferOrm, assessment database.BankRiskAssessmentOrm) error
+	GetRiskAssessmentByReference(referenceUuid uuid.UUID) (database.BankRiskAssessmentOrm, error)
+	ReviewRiskAssessment(assessmentUuid uuid.UUID, reviewer string, decision string, note string) (bool, error)
+	GetHeldTransfers(limit int) ([]database.BankTransferDetailOrm, error)
+}
+
+type RiskDatabasePort interface {
+	GetRiskHistory(accountNumber string, counterpartyNumber string, since time.Time, recentSince time.Time) (
+		database.BankRiskHistoryOrm, error)
+}
+
+type AccountDatabasePort interface {
+	NextAccountNumberSequence() (int64, error)
+	CreateBankAccount(acct database.BankAccountOrm, initialDeposit *database.LedgerEntry,
+		events ...database.OutboxEventOrm) (uuid.UUID, error)
+	GetSystemAccount(kind string, currency string) (database.BankAccountOrm, error)
+	GetBankAccountByAccountNumber(acct string) (database.BankAccountOrm, error)
+	GetBankAccountByUuid(accountUuid uuid.UUID) (database.BankAccountOrm, error)
+	UpdateBankAccountName(acct database.BankAccountOrm, name string, events ...database.OutboxEventOrm) error
+	UpdateBankAccountStatus(acct database.BankAccountOrm, status string, events ...database.OutboxEventOrm) error
+	UpdateBankAccountOverdraftLimit(acct database.BankAccountOrm, limit float64,
+		events ...database.OutboxEventOrm) error
+	CloseBankAccount(acct database.BankAccountOrm, events ...database.OutboxEventOrm) (database.BankAccountOrm, error)
+}
+
+type ReconciliationDatabasePort interface {
+	GetAccountBalancesForReconciliation(checkpoint time.Time) ([]database.BankAccountBalanceOrm, error)
+	SaveReconciliationRun(run database.BankReconciliationRunOrm, items []database.BankReconciliationItemOrm,
+		baselines []database.BankBalanceBaselineOrm) error
+	GetSystemAccount(kind string, currency string) (database.BankAccountOrm, error)
+}
+
+type ReversalDatabasePort interface {
+	GetBankAccountByUuid(accountUuid uuid.UUID) (database.BankAccountOrm, error)
+	GetTransactionByUuid(transactionUuid uuid.UUID) (database.BankTransactionOrm, error)
+	GetTransferDetail(transferUuid uuid.UUID) (database.BankTransferDetailOrm, error)
+	GetJournalByTransaction(transactionUuid uuid.UUID) (database.LedgerEntry, error)
+	GetJournalByReference(referenceUuid uuid.UUID) (database.LedgerEntry, error)
+	PostReversal(entry database.LedgerEntry, transfer *database.BankTransferOrm, reason string) error
+}
+
+type ScheduledTransferDatabasePort interface {
+	GetBankAccountByAccountNumber(acct string) (database.BankAccountOrm, error)
+	CreateScheduledTransfer(s database.BankScheduledTransferOrm) (uuid.UUID, error)
+	GetScheduledTransfer(scheduleUuid uuid.UUID) (database.BankScheduledTransferOrm, error)
+	GetScheduledTransferExecutions(scheduleUuid uuid.UUID) ([]database.BankScheduledTransferExecutionOrm, error)
+	CancelScheduledTransfer(scheduleUuid uuid.UUID) (bool, error)
+	ClaimDueScheduledTransfers(now time.Time, leaseUntil time.Time, limit int) ([]database.BankScheduledTransferOrm,
+		error)
+	RecordScheduledTransferExecution(s database.BankScheduledTransferOrm,
+		execution database.BankScheduledTransferExecutionOrm) error
+}
+
+type LimitDatabasePort interface {
+	GetBankAccountByAccountNumber(acct string) (database.BankAccountOrm, error)
+	GetEffectiveAccountLimits(accountUuid uuid.UUID) (database.BankLimitTierOrm, error)
+	GetLimitTier(tier string) (database.BankLimitTierOrm, error)
+	UpdateBankAccountTier(acct database.BankAccountOrm, tier string) error
+	SaveAccountLimits(limits database.BankAccountLimitsOrm) error
+}
+
+type InterestDatabasePort interface {
+	GetSystemAccount(kind string, currency string) (database.BankAccountOrm, error)
+	GetAccountsForInterest(date time.Time) ([]database.BankInterestBalanceOrm, error)
+	GetInterestRate(currency string, rateType string, balance float64, date time.Time) (database.BankInterestRateOrm,
+		error)
+	PostInterestAccrual(accrual database.BankInterestAccrualOrm, entry database.LedgerEntry) (bool, error)
+	GetLastInterestRun() (*time.Time, error)
+	SaveInterestRun(run database.BankInterestRunOrm) error
+}
+
+type FeeDatabasePort interface {
+	GetFeeRules(currency string, ts time.Time) ([]database.BankFeeRuleOrm, error)
+}
+
+type TransferRecoveryDatabasePort interface {
+	GetTransfersInProgress(before time.Time) ([]database.BankTransferDetailOrm, error)
+	GetJournalByReference(referenceUuid uuid.UUID) (database.LedgerEntry, error)
+	UpdateTransferState(transfer database.BankTransferOrm, from string, to string, reason string,
+		events ...database.OutboxEventOrm) error
+}
+
+type OutboxDatabasePort interface {
+	ClaimOutboxEvents(limit int, lease time.Duration) ([]database.OutboxEventOrm, error)
+	CompleteOutboxClaim(claimed []int64, published []int64, failed map[int64]string) error
+}
+
+type WebhookDatabasePort interface {
+	GetBankAccountByAccountNumber(acct string) (database.BankAccountOrm, error)
+	CreateWebhookSubscription(s database.WebhookSubscriptionOrm) (uuid.UUID, error)
+	GetWebhookSubscription(subscriptionUuid uuid.UUID) (database.WebhookSubscriptionOrm, error)
+	DisableWebhookSubscription(subscriptionUuid uuid.UUID) (bool, error)
+	GetActiveWebhookSubscriptions(accountNumber string, eventType string) ([]database.WebhookSubscriptionOrm, error)
+	EnqueueWebhookDeliveries(deliveries []database.WebhookDeliveryOrm) error
+	ClaimDueWebhookDeliveries(now time.Time, leaseUntil time.Time, limit int) ([]database.WebhookDeliveryOrm, error)
+	RecordWebhookDeliveryAttempt(delivery database.WebhookDeliveryOrm, attempt database.WebhookDeliveryAttemptOrm,
+		deadLetter *database.WebhookDeadLetterOrm) error
+	GetWebhookDeliveries(subscriptionUuid uuid.UUID) ([]database.WebhookDeliveryOrm, error)
+	GetWebhookDeliveryAttempts(deliveryUuid uuid.UUID) ([]database.WebhookDeliveryAttemptOrm, error)
+	GetWebhookDeadLetters(subscriptionUuid uuid.UUID) ([]database.WebhookDeadLetterOrm, error)
+	RedeliverWebhookDeadLetter(deliveryUuid uuid.UUID, now time.Time) (bool, error)
+}
+
+type ActivityDatabasePort interface {
+	GetBankAccountByAccountNumber(acct string) (database.BankAccountOrm, error)
+	GetActivityFeedStart() (bank.ActivityCursor, error)
+	GetActivityFeedEvents(after bank.ActivityCursor, upTo *bank.ActivityCursor, aggregateType string,
+		aggregateId string, limit int) ([]database.OutboxFeedEventOrm, error)
+}
+
+type AuditDatabasePort interface {
+	AppendAuditRecord(seal func(sequence int64, prevHash string) (database.AuditLogOrm, error)) (database.AuditLogOrm,
+		error)
+	GetAuditRecords(filter bank.AuditFilter, afterSequence int64, limit int) ([]database.AuditLogOrm, error)
+}
+
+type ScreeningDatabasePort interface {
+	CreateScreeningHits(hits []database.BankScreeningHitOrm) error
 }
@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+
+	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
+)
+
+// EventSinkPort is where the outbox relay publishes events. Publish must return an error unless the event was
+// accepted, the event is retried later
+type EventSinkPort interface {
+	Publish(ctx context.Context, e bank.Event) error
+}
+
+// WebhookSenderPort posts a webhook body to a subscriber URL. The error is only for requests that got no response,
+// the caller decides what a status code means
+type WebhookSenderPort interface {
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
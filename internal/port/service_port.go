@@ -1,6 +1,7 @@
 package port
 
 import (
+	"context"
 	"time"
 
 	"github.com/Just-Goo/grpc-go-server/internal/application/domain/bank"
@@ -13,10 +14,97 @@ type HelloServicePort interface {
 }
 
 type BankServicePort interface {
+	ValidateCurrency(code string) error
 	FindCurrentBalance(account string) (float64, error)
 	CreateExchangeRate(r bank.ExchangeRate) (uuid.UUID, error)
 	FindExchangeRate(fromCur string, toCur string, ts time.Time) (float64, error)
+	ConvertCurrency(fromCur string, toCur string, amount float64, ts time.Time) (bank.Conversion, error)
+	FindExchangeRateHistory(fromCur string, toCur string, start time.Time, end time.Time, pageToken string,
+		pageSize int) (bank.ExchangeRatePage, error)
+	FindExchangeRateCandles(fromCur string, toCur string, interval string, start time.Time, end time.Time,
+		after time.Time, pageSize int) (bank.ExchangeRateCandlePage, error)
 	CreateTransaction(acct string, t bank.Transaction) (uuid.UUID, error)
+	ListTransactions(filter bank.TransactionFilter) (bank.TransactionPage, error)
+	FindTransactionSummary(account string, date time.Time) (bank.TransactionSummary, error)
+	FindTransactionSummaries(account string, startDate time.Time, endDate time.Time) ([]bank.TransactionSummary, error)
+	RebuildTransactionSummaries() (int64, error)
+	GenerateStatement(account string, start time.Time, end time.Time) (bank.Statement, error)
 	CalculateTransactionSummary(tcur *bank.TransactionSummary, trans bank.Transaction) error
-	Transfer(tt bank.TransferTransaction) (uuid.UUID, bool, error)
+	Transfer(tt bank.TransferTransaction) (bank.TransferResult, error)
+	QuoteTransfer(tt bank.TransferTransaction) (bank.TransferQuote, error)
+	GetTransfer(transferUuid uuid.UUID) (bank.Transfer, error)
+	ListTransfers(filter bank.TransferFilter) (bank.TransferPage, error)
+	VerifyLedgerBalance(account string) (bank.BalanceCheck, error)
+}
+
+type AccountServicePort interface {
+	ValidateAccountNumber(accountNumber string) error
+	CreateAccount(a bank.NewAccount) (bank.Account, error)
+	FindAccountByNumber(accountNumber string) (bank.Account, error)
+	FindAccountByUuid(accountUuid uuid.UUID) (bank.Account, error)
+	RenameAccount(accountNumber string, name string) (bank.Account, error)
+	FreezeAccount(accountNumber string) (bank.Account, error)
+	UnfreezeAccount(accountNumber string) (bank.Account, error)
+	CloseAccount(accountNumber string) (bank.Account, error)
+	SetOverdraftLimit(accountNumber string, limit float64) (bank.Account, error)
+}
+
+type ReconciliationServicePort interface {
+	Reconcile(repair bool) (bank.ReconciliationReport, error)
+}
+
+type ReversalServicePort interface {
+	ReverseTransaction(transactionUuid uuid.UUID, reason string) (uuid.UUID, error)
+	ReverseTransfer(transferUuid uuid.UUID, reason string) error
+}
+
+type ScheduledTransferServicePort interface {
+	ScheduleTransfer(tt bank.TransferTransaction, startAt time.Time, recurrence string, endAt *time.Time) (
+		bank.ScheduledTransfer, error)
+	CancelScheduledTransfer(scheduleUuid uuid.UUID) error
+	FindScheduledTransfer(scheduleUuid uuid.UUID) (bank.ScheduledTransfer, error)
+	FindScheduledTransferExecutions(scheduleUuid uuid.UUID) ([]bank.ScheduledTransferExecution, error)
+	ExecuteDueTransfers() (int, error)
+}
+
+type InterestServicePort interface {
+	AccrueInterest(date time.Time) (bank.InterestAccrualReport, error)
+	AccrueInterestThrough(until time.Time) ([]bank.InterestAccrualReport, error)
+}
+
+type TransferRecoveryServicePort interface {
+	RecoverTransfers(olderThan time.Duration) (int, error)
+}
+
+type WebhookServicePort interface {
+	CreateWebhookSubscription(ns bank.NewWebhookSubscription) (bank.WebhookSubscription, error)
+	DisableWebhookSubscription(subscriptionUuid uuid.UUID) error
+	FindWebhookDeliveries(subscriptionUuid uuid.UUID) ([]bank.WebhookDelivery, error)
+	FindWebhookDeliveryAttempts(deliveryUuid uuid.UUID) ([]bank.WebhookDeliveryAttempt, error)
+	FindWebhookDeadLetters(subscriptionUuid uuid.UUID) ([]bank.WebhookDeadLetter, error)
+	RedeliverWebhook(deliveryUuid uuid.UUID) error
+	DeliverDueWebhooks(ctx context.Context) (int, error)
+}
+
+// AccountActivityServicePort is the account feed behind a WatchAccount stream, send is called for every item
+type AccountActivityServicePort interface {
+	WatchAccount(ctx context.Context, accountNumber string, cursor string, send func(bank.AccountActivity) error) error
+}
+
+type AuditServicePort interface {
+	Record(ctx context.Context, entry bank.AuditEntry)
+	FindAuditRecords(filter bank.AuditFilter) (bank.AuditPage, error)
+	VerifyAuditChain() (bank.AuditChainCheck, error)
+}
+
+// RiskEvaluatorPort scores a transfer or transaction before anything is written, BankService holds REVIEW transfers
+// and refuses DENY operations
+type RiskEvaluatorPort interface {
+	Evaluate(req bank.RiskRequest) (bank.RiskAssessment, error)
+}
+
+type TransferReviewServicePort interface {
+	ListHeldTransfers(pageSize int) ([]bank.HeldTransfer, error)
+	ApproveHeldTransfer(transferUuid uuid.UUID, reviewer string, note string) (bank.TransferResult, error)
+	RejectHeldTransfer(transferUuid uuid.UUID, reviewer string, reason string) error
 }